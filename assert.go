@@ -0,0 +1,102 @@
+package twerge
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// AssertMode controls what happens when It is asked for a class string that
+// is not present in the class map.
+type AssertMode int32
+
+const (
+	// AssertOff silently generates a new class name (the default).
+	AssertOff AssertMode = iota
+	// AssertLog logs the unregistered class string with its call site.
+	AssertLog
+	// AssertPanic panics with an *UnregisteredClassError.
+	AssertPanic
+)
+
+// assertMode is the current AssertMode, initialized from TWERGE_ASSERT
+var assertMode atomic.Int32
+
+// packageDir is the directory of the twerge sources, used to find call sites
+var packageDir string
+
+func init() {
+	_, file, _, ok := runtime.Caller(0)
+	if ok {
+		packageDir = filepath.Dir(file)
+	}
+
+	switch strings.ToLower(os.Getenv("TWERGE_ASSERT")) {
+	case "log":
+		SetAssertMode(AssertLog)
+	case "panic":
+		SetAssertMode(AssertPanic)
+	}
+}
+
+// SetAssertMode sets how unregistered class strings are reported.
+//
+// It is meant for development builds, to catch class strings that were added
+// to templates without re-running code generation. The mode can also be set
+// with the TWERGE_ASSERT environment variable ("log" or "panic").
+func SetAssertMode(mode AssertMode) {
+	assertMode.Store(int32(mode))
+}
+
+// UnregisteredClassError reports a class string requested from It that was
+// not present in the class map.
+type UnregisteredClassError struct {
+	// Classes is the requested class string
+	Classes string
+	// File is the file of the call site
+	File string
+	// Line is the line of the call site
+	Line int
+}
+
+// Error implements the error interface.
+func (e *UnregisteredClassError) Error() string {
+	return fmt.Sprintf("twerge: unregistered classes %q requested at %s:%d", e.Classes, e.File, e.Line)
+}
+
+// assertRegistered reports classes according to the current AssertMode.
+func assertRegistered(classes string) {
+	mode := AssertMode(assertMode.Load())
+	if mode == AssertOff {
+		return
+	}
+
+	file, line := callSite()
+	err := &UnregisteredClassError{Classes: classes, File: file, Line: line}
+	if mode == AssertPanic {
+		panic(err)
+	}
+	log.Println(err.Error())
+}
+
+// callSite returns the first caller outside of the twerge package.
+func callSite() (string, int) {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		inPackage := filepath.Dir(frame.File) == packageDir &&
+			!strings.HasSuffix(frame.File, "_test.go")
+		if !inPackage {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return frame.File, frame.Line
+		}
+	}
+}
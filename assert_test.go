@@ -0,0 +1,41 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertMode(t *testing.T) {
+	defer SetAssertMode(AssertOff)
+	SetMapping(map[string]string{"flex items-center": "tw-row"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	SetAssertMode(AssertPanic)
+	assert.NotPanics(t, func() { It("flex items-center") })
+
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		RuntimeGenerate("grid gap-4")
+	}()
+	err, ok := recovered.(*UnregisteredClassError)
+	if assert.True(t, ok, "expected *UnregisteredClassError, got %v", recovered) {
+		assert.Equal(t, "grid gap-4", err.Classes)
+		assert.Equal(t, "assert_test.go", filepath.Base(err.File))
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	SetAssertMode(AssertLog)
+	It("p-4 m-2")
+	assert.Contains(t, buf.String(), `unregistered classes "p-4 m-2"`)
+	assert.Contains(t, buf.String(), "assert_test.go")
+}
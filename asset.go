@@ -0,0 +1,200 @@
+package twerge
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// assetPath is the resolved stylesheet path returned by AssetPath
+var assetPath atomic.Value
+
+// viteChunk is an entry of a Vite manifest.json
+type viteChunk struct {
+	File    string   `json:"file"`
+	Src     string   `json:"src,omitempty"`
+	IsEntry bool     `json:"isEntry,omitempty"`
+	CSS     []string `json:"css,omitempty"`
+	// Integrity is the subresource integrity of File, as written by
+	// WriteCSSManifest and SRI plugins of Vite
+	Integrity string `json:"integrity,omitempty"`
+}
+
+// esbuildMetafile is the part of an esbuild metafile read by twerge
+type esbuildMetafile struct {
+	Outputs map[string]struct {
+		EntryPoint string `json:"entryPoint"`
+	} `json:"outputs"`
+}
+
+// ResolveManifestAsset returns the hashed output file built for the entry
+// from the content of a Vite manifest.json or an esbuild metafile.
+//
+// For Vite, a JS entry resolves to the first CSS file it imports.
+func ResolveManifestAsset(manifest []byte, entry string) (string, error) {
+	vite, meta, err := parseManifest(manifest)
+	if err != nil {
+		return "", err
+	}
+	if meta != nil {
+		for _, output := range SortedKeys(esbuildOutputs(*meta)) {
+			if meta.Outputs[output].EntryPoint == entry {
+				return output, nil
+			}
+		}
+		return "", fmt.Errorf("entry %q not found in esbuild metafile", entry)
+	}
+
+	chunk, ok := vite[entry]
+	if !ok {
+		return "", fmt.Errorf("entry %q not found in vite manifest", entry)
+	}
+	if path.Ext(chunk.File) != ".css" && len(chunk.CSS) > 0 {
+		return chunk.CSS[0], nil
+	}
+	return chunk.File, nil
+}
+
+// LoadManifest resolves the stylesheet built for entry from the manifest at
+// manifestPath and makes it available through AssetPath, prefixed with base.
+//
+//	err := twerge.LoadManifest("dist/.vite/manifest.json", "src/input.css", "/static/")
+//
+//	<link rel="stylesheet" href={ twerge.AssetPath() }/>
+func LoadManifest(manifestPath, entry, base string) error {
+	manifest, err := readManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	file, err := ResolveManifestAsset(manifest, entry)
+	if err != nil {
+		return err
+	}
+	if base == "" {
+		base = "/"
+	}
+	assetPath.Store(path.Join(base, file))
+	return nil
+}
+
+// AssetPath returns the stylesheet path resolved by LoadManifest, or an
+// empty string if no manifest was loaded.
+func AssetPath() string {
+	p, _ := assetPath.Load().(string)
+	return p
+}
+
+// readManifest returns the content of the manifest at manifestPath.
+func readManifest(manifestPath string) ([]byte, error) {
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// parseManifest parses the content of a Vite manifest.json, or of an esbuild
+// metafile, returned as meta if it is one.
+func parseManifest(manifest []byte) (vite map[string]viteChunk, meta *esbuildMetafile, err error) {
+	var m esbuildMetafile
+	if err := json.Unmarshal(manifest, &m); err == nil && m.Outputs != nil {
+		return nil, &m, nil
+	}
+	if err := json.Unmarshal(manifest, &vite); err != nil {
+		return nil, nil, fmt.Errorf("error parsing manifest: %w", err)
+	}
+	return vite, nil, nil
+}
+
+func esbuildOutputs(meta esbuildMetafile) map[string]string {
+	outputs := make(map[string]string, len(meta.Outputs))
+	for output, o := range meta.Outputs {
+		outputs[output] = o.EntryPoint
+	}
+	return outputs
+}
+
+// WriteCSSManifest copies the stylesheet at cssPath to a file named after its
+// content hash, like input-1a2b3c4d.css, and records it in the Vite manifest
+// at manifestPath, with its subresource integrity:
+//
+//	{
+//	  "static/input.css": {
+//	    "file": "static/input-1a2b3c4d.css",
+//	    "src": "static/input.css",
+//	    "isEntry": true,
+//	    "integrity": "sha384-..."
+//	  }
+//	}
+//
+// Paths are relative to the directory of the manifest. Other entries of an
+// existing manifest are kept, and the copy written for a previous version of
+// the stylesheet is removed. It returns the path of the copy.
+func WriteCSSManifest(manifestPath, cssPath string) (string, error) {
+	css, err := os.ReadFile(cssPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading css file: %w", err)
+	}
+	sum := sha256.Sum256(css)
+	ext := filepath.Ext(cssPath)
+	hashedPath := strings.TrimSuffix(cssPath, ext) + "-" + hex.EncodeToString(sum[:4]) + ext
+
+	manifestDir := filepath.Dir(manifestPath)
+	src, err := filepath.Rel(manifestDir, cssPath)
+	if err != nil {
+		return "", err
+	}
+	file, err := filepath.Rel(manifestDir, hashedPath)
+	if err != nil {
+		return "", err
+	}
+	integrity := sha512.Sum384(css)
+	chunk := viteChunk{
+		File:      filepath.ToSlash(file),
+		Src:       filepath.ToSlash(src),
+		IsEntry:   true,
+		Integrity: "sha384-" + base64.StdEncoding.EncodeToString(integrity[:]),
+	}
+
+	// entries are kept as written by Vite
+	manifest := make(map[string]json.RawMessage)
+	if existing, err := os.ReadFile(manifestPath); err == nil {
+		if err := json.Unmarshal(existing, &manifest); err != nil {
+			return "", fmt.Errorf("error parsing manifest: %w", err)
+		}
+	}
+	var previous viteChunk
+	if raw, ok := manifest[chunk.Src]; ok && json.Unmarshal(raw, &previous) == nil && previous.File != chunk.File {
+		_ = os.Remove(filepath.Join(manifestDir, filepath.FromSlash(previous.File)))
+	}
+	manifest[chunk.Src], err = json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(hashedPath, css, 0644)
+	if err != nil {
+		return "", fmt.Errorf("error writing %s: %w", hashedPath, err)
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(manifestDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("error creating %s: %w", manifestDir, err)
+	}
+	err = os.WriteFile(manifestPath, append(content, '\n'), 0644)
+	if err != nil {
+		return "", fmt.Errorf("error writing %s: %w", manifestPath, err)
+	}
+	return hashedPath, nil
+}
@@ -0,0 +1,73 @@
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveManifestAsset(t *testing.T) {
+	vite := []byte(`{
+		"src/input.css": {"file": "assets/input-4f2a.css", "src": "src/input.css", "isEntry": true},
+		"src/main.js": {"file": "assets/main-9c1b.js", "src": "src/main.js", "isEntry": true, "css": ["assets/main-77aa.css"]}
+	}`)
+	file, err := ResolveManifestAsset(vite, "src/input.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "assets/input-4f2a.css", file)
+	file, err = ResolveManifestAsset(vite, "src/main.js")
+	assert.NoError(t, err)
+	assert.Equal(t, "assets/main-77aa.css", file)
+	_, err = ResolveManifestAsset(vite, "src/missing.css")
+	assert.Error(t, err)
+
+	esbuild := []byte(`{"inputs": {}, "outputs": {
+		"dist/input-QX3Z.css": {"entryPoint": "src/input.css"},
+		"dist/input-QX3Z.css.map": {}
+	}}`)
+	file, err = ResolveManifestAsset(esbuild, "src/input.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "dist/input-QX3Z.css", file)
+}
+
+func TestLoadManifest(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	err := os.WriteFile(manifestPath, []byte(`{"src/input.css": {"file": "assets/input-4f2a.css"}}`), 0644)
+	assert.NoError(t, err)
+
+	assert.NoError(t, LoadManifest(manifestPath, "src/input.css", "/static/"))
+	assert.Equal(t, "/static/assets/input-4f2a.css", AssetPath())
+}
+
+func TestWriteCSSManifest(t *testing.T) {
+	dir := t.TempDir()
+	cssPath := filepath.Join(dir, "static", "input.css")
+	manifestPath := filepath.Join(dir, "manifest.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(cssPath), 0755))
+	assert.NoError(t, os.WriteFile(cssPath, []byte(".tw-0{padding:1rem}"), 0644))
+	// entries of Vite are kept
+	assert.NoError(t, os.WriteFile(manifestPath, []byte(`{"src/main.js": {"file": "assets/main-9c1b.js", "isEntry": true, "css": ["assets/main-77aa.css"]}}`), 0644))
+
+	hashedPath, err := WriteCSSManifest(manifestPath, cssPath)
+	assert.NoError(t, err)
+	assert.Regexp(t, `static/input-[0-9a-f]{8}\.css$`, filepath.ToSlash(hashedPath))
+	assert.FileExists(t, hashedPath)
+
+	manifest, err := os.ReadFile(manifestPath)
+	assert.NoError(t, err)
+	file, err := ResolveManifestAsset(manifest, "static/input.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "static/"+filepath.Base(hashedPath), file)
+	file, err = ResolveManifestAsset(manifest, "src/main.js")
+	assert.NoError(t, err)
+	assert.Equal(t, "assets/main-77aa.css", file)
+	assert.Contains(t, string(manifest), `"integrity": "sha384-`)
+
+	// a new version replaces the previous copy
+	assert.NoError(t, os.WriteFile(cssPath, []byte(".tw-0{padding:2rem}"), 0644))
+	newPath, err := WriteCSSManifest(manifestPath, cssPath)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashedPath, newPath)
+	assert.NoFileExists(t, hashedPath)
+}
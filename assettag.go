@@ -0,0 +1,129 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// AssetTag returns a templ component rendering a <link> element for every
+// stylesheet of the Vite manifest or esbuild metafile at manifestPath, with
+// its integrity if known, so layouts reference the hashed file of the current
+// build:
+//
+//	<head>
+//		@twerge.AssetTag("static/manifest.json")
+//	</head>
+//
+// Stylesheets are the CSS entries of a Vite manifest and the CSS imported by
+// its entries, or the CSS outputs of an esbuild metafile, linked from the
+// site root. Rebuilds updating the manifest are picked up on the next render,
+// or, in production mode, see ProductionMode, after ReloadAssetTags.
+func AssetTag(manifestPath string) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		tags, err := assetTags.load(manifestPath)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, tags)
+		return err
+	})
+}
+
+// ReloadAssetTags makes AssetTag read its manifests again on the next render,
+// e.g. after a deployment replaced them while the server is running.
+func ReloadAssetTags() {
+	assetTags.mu.Lock()
+	defer assetTags.mu.Unlock()
+	clear(assetTags.entries)
+}
+
+// assetTagCache holds the <link> elements of manifests by path, with the
+// manifest they were read from
+type assetTagCache struct {
+	mu      sync.Mutex
+	entries map[string]assetTagEntry
+}
+
+// assetTagEntry is a manifest read by assetTagCache and its <link> elements
+type assetTagEntry struct {
+	manifest []byte
+	tags     string
+}
+
+var assetTags = assetTagCache{entries: make(map[string]assetTagEntry)}
+
+// load returns the <link> elements of the manifest at manifestPath.
+//
+// In production mode, the manifest is read once until ReloadAssetTags.
+// Otherwise it is read on every call, as modification times are too coarse
+// to notice rebuilds, but parsed only when it changed.
+func (c *assetTagCache) load(manifestPath string) (string, error) {
+	if ProductionMode {
+		c.mu.Lock()
+		entry, ok := c.entries[manifestPath]
+		c.mu.Unlock()
+		if ok {
+			return entry.tags, nil
+		}
+	}
+	content, err := readManifest(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[manifestPath]; ok && bytes.Equal(entry.manifest, content) {
+		return entry.tags, nil
+	}
+
+	vite, meta, err := parseManifest(content)
+	if err != nil {
+		return "", err
+	}
+	var tags bytes.Buffer
+	linked := make(map[string]bool)
+	link := func(file, integrity string) {
+		href := path.Join("/", file)
+		if linked[href] {
+			return
+		}
+		linked[href] = true
+		fmt.Fprintf(&tags, `<link rel="stylesheet" href="%s"`, html.EscapeString(href))
+		if integrity != "" {
+			fmt.Fprintf(&tags, ` integrity="%s" crossorigin="anonymous"`, html.EscapeString(integrity))
+		}
+		tags.WriteString(">")
+	}
+	if meta != nil {
+		for _, output := range SortedKeys(esbuildOutputs(*meta)) {
+			if path.Ext(output) == ".css" {
+				link(output, "")
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(vite)) {
+		chunk := vite[key]
+		if path.Ext(chunk.File) == ".css" {
+			link(chunk.File, chunk.Integrity)
+			continue
+		}
+		if chunk.IsEntry {
+			for _, css := range chunk.CSS {
+				link(css, "")
+			}
+		}
+	}
+	c.entries[manifestPath] = assetTagEntry{manifest: content, tags: tags.String()}
+	return tags.String(), nil
+}
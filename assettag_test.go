@@ -0,0 +1,58 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetTag(t *testing.T) {
+	dir := t.TempDir()
+	cssPath := filepath.Join(dir, "static", "input.css")
+	manifestPath := filepath.Join(dir, "manifest.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(cssPath), 0755))
+	assert.NoError(t, os.WriteFile(cssPath, []byte(".tw-0{padding:1rem}"), 0644))
+	assert.NoError(t, os.WriteFile(manifestPath, []byte(`{"src/main.js": {"file": "assets/main-9c1b.js", "isEntry": true, "css": ["assets/main-77aa.css"]}}`), 0644))
+	_, err := WriteCSSManifest(manifestPath, cssPath)
+	assert.NoError(t, err)
+	t.Cleanup(ReloadAssetTags)
+
+	var tag strings.Builder
+	assert.NoError(t, AssetTag(manifestPath).Render(context.Background(), &tag))
+	assert.Regexp(t, `^<link rel="stylesheet" href="/assets/main-77aa.css">`+
+		`<link rel="stylesheet" href="/static/input-[0-9a-f]{8}\.css" integrity="sha384-[A-Za-z0-9+/=]{64}" crossorigin="anonymous">$`, tag.String())
+
+	// a new version replaces the previous copy
+	assert.NoError(t, os.WriteFile(cssPath, []byte(".tw-0{padding:2rem}"), 0644))
+	newPath, err := WriteCSSManifest(manifestPath, cssPath)
+	assert.NoError(t, err)
+	if ProductionMode {
+		ReloadAssetTags()
+	}
+	tag.Reset()
+	assert.NoError(t, AssetTag(manifestPath).Render(context.Background(), &tag))
+	assert.Contains(t, tag.String(), filepath.Base(newPath))
+
+	assert.Error(t, AssetTag(filepath.Join(dir, "missing.json")).Render(context.Background(), &tag))
+}
+
+func TestAssetTagEsbuild(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "meta.json")
+	err := os.WriteFile(manifestPath, []byte(`{"outputs": {
+		"dist/input-QX3Z.css": {"entryPoint": "src/input.css"},
+		"dist/input-QX3Z.css.map": {},
+		"dist/main-8BN2.js": {"entryPoint": "src/main.js"}
+	}}`), 0644)
+	assert.NoError(t, err)
+	t.Cleanup(ReloadAssetTags)
+
+	var tag strings.Builder
+	assert.NoError(t, AssetTag(manifestPath).Render(context.Background(), &tag))
+	assert.Equal(t, `<link rel="stylesheet" href="/dist/input-QX3Z.css">`, tag.String())
+}
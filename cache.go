@@ -0,0 +1,148 @@
+package twerge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache stores the results of a merger, mapping class lists to their merged
+// classes. Get returns an empty string for class lists not stored.
+//
+// A Cache must be safe for concurrent use. By default each merger uses its
+// own in-memory LRU cache, see Config.Cache.
+type Cache interface {
+	Get(classList string) string
+	Set(classList, merged string)
+}
+
+// CacheMetrics is notified of the lookups of a merger's cache, see
+// Config.CacheMetrics. Its methods are called concurrently and should only
+// increment counters.
+type CacheMetrics interface {
+	// Hit is called when a merge result is found in the cache
+	Hit()
+	// Miss is called when classes are merged as their result is not cached
+	Miss()
+	// Evict is called when the in-memory LRU cache drops its least recently
+	// used result to make room for a new one
+	Evict()
+}
+
+// FileCache is a Cache persisted to a JSON file, so merge results survive
+// process restarts and code generation only merges new class lists.
+//
+// The results depend on the Config they were merged with, so a file must
+// only be used with one Config:
+//
+//	cache, err := twerge.NewFileCache(".twerge-cache.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	conf := twerge.DefaultConfig()
+//	conf.Cache = cache
+//	twerge.SetConfig(conf)
+//	defer cache.Flush()
+type FileCache struct {
+	path string
+	// mu protects entries and dirty
+	mu      sync.RWMutex
+	entries map[string]string
+	// dirty reports whether entries changed since the last Load or Flush
+	dirty bool
+}
+
+// NewFileCache returns a FileCache persisted to path, loading the entries
+// stored there. A missing file is an empty cache.
+func NewFileCache(path string) (*FileCache, error) {
+	c := &FileCache{path: path, entries: make(map[string]string)}
+	if err := c.Load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get implements Cache.
+func (c *FileCache) Get(classList string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entries[classList]
+}
+
+// Set implements Cache.
+func (c *FileCache) Set(classList, merged string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.entries[classList]; ok && current == merged {
+		return
+	}
+	c.entries[classList] = merged
+	c.dirty = true
+}
+
+// Len returns the number of cached class lists.
+func (c *FileCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Load adds the entries stored in the file to the cache. A missing file is
+// not an error.
+func (c *FileCache) Load() error {
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading cache file: %w", err)
+	}
+	var entries map[string]string
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return fmt.Errorf("error parsing cache file %s: %w", c.path, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for classList, merged := range entries {
+		c.entries[classList] = merged
+	}
+	return nil
+}
+
+// Flush writes the cache to its file if it changed since it was loaded or
+// last flushed. The file is replaced atomically, so a reader never sees a
+// partially written cache.
+func (c *FileCache) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("error encoding cache: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(c.path), "."+filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
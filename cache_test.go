@@ -0,0 +1,40 @@
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache, err := NewFileCache(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cache.Len())
+
+	m := New(&Config{Conflicts: DefaultConflictConfig(), Cache: cache})
+	assert.Equal(t, "p-4", m.Merge("p-2 p-4"))
+	assert.Equal(t, "p-4", cache.Get("p-2 p-4"))
+	assert.NoError(t, cache.Flush())
+
+	// results are read back instead of merged again
+	reloaded, err := NewFileCache(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reloaded.Len())
+	reloaded.Set("m-1 m-2", "m-cached")
+	m = New(&Config{Conflicts: DefaultConflictConfig(), Cache: reloaded})
+	assert.Equal(t, "p-4", m.Merge("p-2 p-4"))
+	assert.Equal(t, "m-cached", m.Merge("m-1 m-2"))
+
+	// unchanged caches are not written
+	assert.NoError(t, os.Remove(path))
+	assert.NoError(t, cache.Flush())
+	assert.NoFileExists(t, path)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err = NewFileCache(path)
+	assert.ErrorContains(t, err, "error parsing cache file")
+}
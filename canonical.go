@@ -0,0 +1,45 @@
+package twerge
+
+import "sync/atomic"
+
+var (
+	// quietMerge merges like Merge without recording the class strings, for
+	// Canonical and the Mergers of NewScope. It is replaced with Merge by
+	// SetConfig.
+	quietMerge atomic.Pointer[twMergeFn]
+	// canonicalKeys is Config.CanonicalKeys of the settings of Merge, read
+	// by every RuntimeGenerate
+	canonicalKeys atomic.Bool
+)
+
+func init() {
+	merge := createTwMerge(defaultConfig, nil, nil)
+	quietMerge.Store(&merge)
+}
+
+// Canonical returns the merged classes of classes, sorted and joined by
+// single spaces, so class strings differing only in the order of their
+// classes, their whitespace or overridden classes share it:
+//
+//	twerge.Canonical("p-4 flex")     // "flex p-4"
+//	twerge.Canonical("flex\tp-2 p-4") // "flex p-4"
+//
+// Unlike Merge, Canonical does not record classes in the class map. Keying
+// the class map by canonical class strings keeps equivalent class strings
+// from generating a class each, see Config.CanonicalKeys.
+func Canonical(classes string) string {
+	merge := *quietMerge.Load()
+	return normalizeMerged(merge(classes))
+}
+
+// runtimeKey returns the class string RuntimeGenerate looks up for classes,
+// its canonical form if Config.CanonicalKeys is set.
+func runtimeKey(classes string) string {
+	if ProductionMode {
+		return classes
+	}
+	if canonicalKeys.Load() {
+		return Canonical(classes)
+	}
+	return classes
+}
@@ -0,0 +1,29 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeGenerateCanonicalKeys(t *testing.T) {
+	conf := DefaultConfig()
+	conf.CanonicalKeys = true
+	SetConfig(conf)
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	className := RuntimeGenerate("p-4 canonical-key")
+	assert.Equal(t, className, RuntimeGenerate("canonical-key p-4"))
+	assert.Equal(t, className, RuntimeGenerate("canonical-key  p-2 p-4"))
+
+	classMap := TakeSnapshot().ClassMap
+	assert.Equal(t, className, classMap["canonical-key p-4"])
+	assert.NotContains(t, classMap, "p-4 canonical-key")
+
+	// without CanonicalKeys class strings are recorded as written
+	SetConfig(DefaultConfig())
+	RuntimeGenerate("p-4 canonical-key")
+	assert.Contains(t, TakeSnapshot().ClassMap, "p-4 canonical-key")
+}
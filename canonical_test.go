@@ -0,0 +1,20 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	assert.Equal(t, "flex p-4", Canonical("p-4 flex"))
+	assert.Equal(t, "flex p-4", Canonical("flex p-4"))
+	assert.Equal(t, "flex p-4", Canonical("flex\n\tp-2 p-4"))
+	assert.Empty(t, Canonical(" "))
+
+	_, recorded := TakeSnapshot().ClassMap["canonical-test p-2 p-4"]
+	Canonical("canonical-test p-2 p-4")
+	_, recordedAfter := TakeSnapshot().ClassMap["canonical-test p-2 p-4"]
+	assert.False(t, recorded)
+	assert.False(t, recordedAfter, "Canonical does not record class strings")
+}
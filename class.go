@@ -1,62 +1,27 @@
 package twerge
 
 import (
-	"regexp"
 	"strings"
 )
 
 // getClassGroupIDFn returns the class group id for a given class
 type getClassGroupIDFn func(string) (isTwClass bool, groupId string)
 
-var arbitraryPropertyRegex = regexp.MustCompile(`^\[(.+)\]$`)
-
 // makeGetClassGroupID returns a getClassGroupIdfn
 func makeGetClassGroupID(conf *config) getClassGroupIDFn {
-	var getClassGroupIDRecursive func(
-		classParts []string,
-		i int,
-		classMap *classPart,
-	) (isTwClass bool, groupId string)
-	getClassGroupIDRecursive = func(
-		classParts []string,
-		i int,
-		classMap *classPart,
-	) (isTwClass bool, groupId string) {
-		if i >= len(classParts) {
-			if classMap.ClassGroupID != "" {
-				return true, classMap.ClassGroupID
-			}
-
-			return false, ""
-		}
-
-		if classMap.NextPart != nil {
-			nextClassMap := classMap.NextPart[classParts[i]]
-			isTw, id := getClassGroupIDRecursive(classParts, i+1, &nextClassMap)
-			if isTw {
-				return isTw, id
-			}
-		}
-
-		if len(classMap.Validators) > 0 {
-			remainingClass := strings.Join(classParts[i:], string(conf.ClassSeparator))
-
-			for _, validator := range classMap.Validators {
-				if validator.Fn(remainingClass) {
-					return true, validator.ClassGroupID
-				}
-			}
-
-		}
-		return false, ""
-	}
+	return makeGetClassGroupIDFromTrie(conf, compileTrie(conf))
+}
 
+// makeGetClassGroupIDFromTrie returns a getClassGroupIDFn looking classes up
+// in the trie compiled from conf
+func makeGetClassGroupIDFromTrie(conf *config, trie *classTrie) getClassGroupIDFn {
 	getGroupIDForArbitraryProperty := func(class string) (bool, string) {
-		if arbitraryPropertyRegex.MatchString(class) {
-			arbitraryPropertyClassName := arbitraryPropertyRegex.FindStringSubmatch(class)[1]
-			property := arbitraryPropertyClassName[:strings.Index(arbitraryPropertyClassName, ":")]
+		if len(class) > 2 && class[0] == '[' && class[len(class)-1] == ']' {
+			arbitraryPropertyClassName := class[1 : len(class)-1]
+			// [abc] has no property and is not a Tailwind class
+			property, _, found := strings.Cut(arbitraryPropertyClassName, ":")
 
-			if property != "" {
+			if found && property != "" {
 				// two dots here because one dot is used as prefix for class groups in plugins
 				return true, "arbitrary.." + property
 			}
@@ -66,12 +31,21 @@ func makeGetClassGroupID(conf *config) getClassGroupIDFn {
 	}
 
 	return func(baseClass string) (isTwClass bool, groupdId string) {
-		classParts := strings.Split(baseClass, string(conf.ClassSeparator))
-		// remove first element if empty for things like -px-4
-		if len(classParts) > 0 && classParts[0] == "" {
-			classParts = classParts[1:]
+		baseClass, ok := stripPrefix(conf, baseClass)
+		if !ok {
+			return false, ""
+		}
+		// skip the separator of negative values like -mt-4
+		class := baseClass
+		negative := len(class) > 0 && class[0] == byte(conf.ClassSeparator)
+		if negative {
+			class = class[1:]
+		}
+		groupID, isTwClass := trie.find(class)
+		if isTwClass && negative && conf.NegativeClassGroups != nil && !conf.NegativeClassGroups[groupID] {
+			// -p-4 is not a Tailwind class
+			return false, ""
 		}
-		isTwClass, groupID := getClassGroupIDRecursive(classParts, 0, &conf.ClassGroups)
 		if isTwClass {
 			return isTwClass, groupID
 		}
@@ -80,3 +54,21 @@ func makeGetClassGroupID(conf *config) getClassGroupIDFn {
 	}
 
 }
+
+// stripPrefix returns baseClass without the Tailwind prefix of conf, keeping
+// the sign of negative values -> -tw-mt-4 is -mt-4. It returns false if
+// a prefix is configured and baseClass does not have it.
+func stripPrefix(conf *config, baseClass string) (string, bool) {
+	if conf.Prefix == "" {
+		return baseClass, true
+	}
+	sign := ""
+	if strings.HasPrefix(baseClass, string(conf.ClassSeparator)) {
+		sign = string(conf.ClassSeparator)
+	}
+	unprefixed, ok := strings.CutPrefix(baseClass[len(sign):], conf.Prefix)
+	if !ok || unprefixed == "" {
+		return "", false
+	}
+	return sign + unprefixed, true
+}
@@ -0,0 +1,49 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// benchmarkClasses are base classes of typical templates, covering exact
+// matches, validators and unknown classes
+var benchmarkClasses = []string{
+	"flex", "items-center", "justify-between", "p-4", "px-2.5", "mt-[10px]",
+	"-translate-x-1/2", "bg-red-500", "text-lg", "text-[#abc]", "w-1/2",
+	"rounded-lg", "shadow-[0_1px_2px_black]", "bg-[url(/a.png)]", "grid-cols-3",
+	"hover-card", "border-b-2", "font-bold", "leading-7", "z-10",
+}
+
+func TestGetClassGroupID(t *testing.T) {
+	getClassGroupID := makeGetClassGroupID(defaultConfig)
+	for class, want := range map[string]string{
+		"flex":                     "display",
+		"items-center":             "align-items",
+		"p-4":                      "p",
+		"px-2.5":                   "px",
+		"mt-[10px]":                "mt",
+		"-translate-x-1/2":         "translate-x",
+		"bg-red-500":               "bg-color",
+		"text-lg":                  "font-size",
+		"text-[#abc]":              "text-color",
+		"bg-[url(/a.png)]":         "bg-image",
+		"shadow-[0_1px_2px_black]": "shadow",
+		"[mask-type:luminance]":    "arbitrary..mask-type",
+		"-":                        "",
+		"p-":                       "",
+		"hover-card":               "",
+	} {
+		ok, groupID := getClassGroupID(class)
+		assert.Equal(t, want != "", ok, class)
+		assert.Equal(t, want, groupID, class)
+	}
+}
+
+func BenchmarkGetClassGroupID(b *testing.B) {
+	getClassGroupID := makeGetClassGroupID(defaultConfig)
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		getClassGroupID(benchmarkClasses[i%len(benchmarkClasses)])
+	}
+}
@@ -0,0 +1,85 @@
+package twerge
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Extend adds classes to the class group with the given ID and returns c.
+//
+// A class ending in "-*" matches every class with that prefix, so "btn-*"
+// matches "btn-primary" and "btn-lg". Any other class is matched exactly.
+//
+//	config := twerge.DefaultConfig().
+//		Extend("btn", "btn", "btn-*").
+//		Extend("shadow", "shadow-glow")
+//
+// Classes of the same group and with the same variants conflict, so only the
+// last one is kept. Extending a group of Tailwind, like "shadow" above, makes
+// the classes conflict with the classes of that group, and with the groups it
+// conflicts with. Classes added by Extend take precedence over the classes of
+// Tailwind, so "shadow-glow" is no longer mistaken for a shadow color.
+//
+// Unlike PluginGroups, which only apply to classes twerge does not know,
+// class groups can replace and extend the class groups of Tailwind.
+func (c *Config) Extend(group string, classes ...string) *Config {
+	if c.ClassGroups == nil {
+		c.ClassGroups = make(map[string][]string)
+	}
+	c.ClassGroups[group] = append(c.ClassGroups[group], classes...)
+	return c
+}
+
+// RegisterClassGroup adds classes to the class group with the given ID of the
+// Config Merge uses, see Config.Extend.
+//
+// Like SetConfig, it resets the merge cache and is not safe to call
+// concurrently with Merge, so it should be called once at program start.
+func RegisterClassGroup(group string, classes ...string) {
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	settings.ClassGroups = cloneClassGroups(settings.ClassGroups)
+	settings.Extend(group, classes...)
+	rebuildMerge()
+}
+
+// classGroupParts returns the class parts of the custom class groups, keyed
+// by class prefix like the parts passed to extendConfig.
+func classGroupParts(groups map[string][]string) map[string]classPart {
+	parts := make(map[string]classPart)
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		for _, class := range groups[id] {
+			prefix, isPattern := strings.CutSuffix(class, "-*")
+			part := parts[prefix]
+			if isPattern {
+				part.Validators = append(part.Validators, classGroupValidator{
+					Fn:           isAny,
+					ClassGroupID: id,
+				})
+			} else {
+				part.ClassGroupID = id
+			}
+			parts[prefix] = part
+		}
+	}
+	return parts
+}
+
+// cloneClassGroups returns a copy of the custom class groups.
+func cloneClassGroups(groups map[string][]string) map[string][]string {
+	if groups == nil {
+		return nil
+	}
+	cloned := make(map[string][]string, len(groups))
+	for id, classes := range groups {
+		cloned[id] = slices.Clone(classes)
+	}
+	return cloned
+}
@@ -0,0 +1,47 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigExtend(t *testing.T) {
+	config := DefaultConfig().
+		Extend("btn", "btn", "btn-*").
+		Extend("alert", "alert-info", "alert-error").
+		Extend("shadow", "shadow-glow")
+	merge := NewMerge(config)
+
+	assert.Equal(t, "btn-secondary", merge("btn btn-primary btn-secondary"))
+	assert.Equal(t, "btn-primary md:btn-lg", merge("btn-primary md:btn-lg"))
+	assert.Equal(t, "alert-error", merge("alert-info alert-error"))
+	// unlisted classes are passed through
+	assert.Equal(t, "alert-info alert-warning", merge("alert-info alert-warning"))
+
+	// extended Tailwind groups conflict with their classes
+	assert.Equal(t, "shadow-glow", merge("shadow-lg shadow-glow"))
+	assert.Equal(t, "shadow-glow shadow-red-500", merge("shadow-glow shadow-red-500"))
+	assert.Equal(t, "shadow-lg", merge("shadow-glow shadow-lg"))
+
+	// the default class groups are left untouched
+	_, ok := defaultConfig.ClassGroups.NextPart["btn"]
+	assert.False(t, ok)
+	_, ok = defaultConfig.ClassGroups.NextPart["shadow"].NextPart["glow"]
+	assert.False(t, ok)
+}
+
+func TestRegisterClassGroup(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	assert.Equal(t, "badge-info badge-error", Merge("badge-info badge-error"))
+
+	RegisterClassGroup("badge", "badge-*")
+	RegisterClassGroup("badge-size", "badge-sm", "badge-lg")
+	assert.Equal(t, "badge-error", Merge("badge-info badge-error"))
+	assert.Equal(t, "badge-error badge-lg", Merge("badge-info badge-sm badge-error badge-lg"))
+
+	// class groups are kept when other settings change
+	SetConflictConfig(DefaultConflictConfig())
+	assert.Equal(t, "badge-error", Merge("badge-info badge-error"))
+}
@@ -1,3 +1,5 @@
 module github.com/conneroisu/twerge/cmd/hasher
 
 go 1.24.1
+
+require github.com/cespare/xxhash/v2 v2.3.0
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// fileHash is the hash of a file of the hashed directory
+type fileHash struct {
+	// rel is the slash separated path relative to the hashed directory
+	rel  string
+	path string
+	sum  uint64
+}
+
+// calculateDirectoryHash computes the xxHash of the paths and contents of the
+// files in dirPath, hashing them with the given number of workers.
+//
+// Files matching excludes or the .gitignore files of dirPath are skipped.
+// The hash does not depend on the order files are hashed in, as the hashes
+// of the files are combined in the order of their paths.
+func calculateDirectoryHash(ctx context.Context, dirPath string, excludes []string, workers int) (string, error) {
+	files, err := hashFiles(ctx, dirPath, excludes, workers)
+	if err != nil {
+		return "", err
+	}
+	return combineFileHashes(files), nil
+}
+
+// hashFiles computes the xxHash of every file of dirPath to hash, with the
+// given number of workers, ordered by path.
+func hashFiles(ctx context.Context, dirPath string, excludes []string, workers int) ([]fileHash, error) {
+	files, err := listFiles(dirPath, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	indexes := make(chan int)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sum, err := calculateFileHash(files[i].path)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					cancel()
+					return
+				}
+				files[i].sum = sum
+			}
+		}()
+	}
+send:
+	for i := range files {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// combineFileHashes combines the hashes of files, ordered by path, into the
+// hash of their directory.
+func combineFileHashes(files []fileHash) string {
+	hasher := xxhash.New()
+	for _, f := range files {
+		_, _ = io.WriteString(hasher, f.rel+"\x00"+strconv.FormatUint(f.sum, 16)+"\n")
+	}
+	return fmt.Sprintf("%016x", hasher.Sum64())
+}
+
+// changedFiles returns the paths of the files added, removed or changed
+// between before and after.
+func changedFiles(before, after []fileHash) []string {
+	sums := make(map[string]uint64, len(before))
+	for _, f := range before {
+		sums[f.rel] = f.sum
+	}
+	var changed []string
+	for _, f := range after {
+		if sum, ok := sums[f.rel]; !ok || sum != f.sum {
+			changed = append(changed, f.rel)
+		}
+		delete(sums, f.rel)
+	}
+	for rel := range sums {
+		changed = append(changed, rel)
+	}
+	return changed
+}
+
+// listFiles returns the files of dirPath to hash, ordered by path.
+func listFiles(dirPath string, excludes []string) ([]fileHash, error) {
+	var files []fileHash
+	// rules holds the rules of the .gitignore files of the directories
+	// entered, by directory
+	rules := make(map[string][]ignoreRule)
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		parent := filepath.Dir(path)
+		inherited := rules[parent]
+		if path == dirPath {
+			inherited = nil
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" || (path != dirPath && (excluded(rel, excludes) || ignored(inherited, rel, true))) {
+				return filepath.SkipDir
+			}
+			base := rel
+			if path == dirPath {
+				base = ""
+			}
+			own, err := readIgnoreRules(path, base)
+			if err != nil {
+				return err
+			}
+			rules[path] = append(inherited[:len(inherited):len(inherited)], own...)
+			return nil
+		}
+		// Skip the hash file itself
+		if d.Name() == defaultHashFileName || !d.Type().IsRegular() {
+			return nil
+		}
+		if excluded(rel, excludes) || ignored(inherited, rel, false) {
+			return nil
+		}
+		files = append(files, fileHash{rel: rel, path: path})
+		return nil
+	})
+	return files, err
+}
+
+// excluded reports whether the slash separated path rel matches one of the
+// exclude patterns.
+func excluded(rel string, excludes []string) bool {
+	for _, pattern := range excludes {
+		if matched, _ := filepath.Match(pattern, filepath.FromSlash(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateFileHash computes the xxHash of a single file
+func calculateFileHash(filePath string) (uint64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	hasher := xxhash.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return 0, err
+	}
+	return hasher.Sum64(), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCalculateDirectoryHash(t *testing.T) {
+	dir := t.TempDir()
+	excludes := []string{"node_modules"}
+	files := map[string]string{
+		".gitignore":           "*.log\n/build/\n!keep.log\n",
+		"views/page.templ":     "templ Page() {}\n",
+		"views/page_templ.go":  "package views\n",
+		"views/.gitignore":     "*_templ.go\n",
+		"app.log":              "ignored",
+		"keep.log":             "hashed",
+		"build/out.css":        "ignored",
+		"node_modules/a.js":    "excluded",
+		defaultHashFileName:    "{}",
+		".git/HEAD":            "ref: refs/heads/main\n",
+		"static/css/input.css": "@tailwind base;\n",
+	}
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := func(workers int) string {
+		t.Helper()
+		sum, err := calculateDirectoryHash(context.Background(), dir, excludes, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sum
+	}
+	for name, content := range files {
+		write(name, content)
+	}
+
+	listed, err := listFiles(dir, excludes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rels []string
+	for _, f := range listed {
+		rels = append(rels, f.rel)
+	}
+	want := []string{".gitignore", "keep.log", "static/css/input.css", "views/.gitignore", "views/page.templ"}
+	if !slices.Equal(rels, want) {
+		t.Errorf("listFiles() = %q, want %q", rels, want)
+	}
+
+	// the hash does not depend on the number of workers
+	initial := hash(1)
+	if len(initial) != 16 {
+		t.Errorf("hash %q is not 16 hex digits", initial)
+	}
+	for _, workers := range []int{0, 2, 8} {
+		if got := hash(workers); got != initial {
+			t.Errorf("hash with %d workers = %s, want %s", workers, got, initial)
+		}
+	}
+
+	// ignored files do not change the hash, hashed files and their paths do
+	write("app.log", "changed")
+	write("views/page_templ.go", "package changed\n")
+	if got := hash(4); got != initial {
+		t.Errorf("hash after changing ignored files = %s, want %s", got, initial)
+	}
+	write("views/page.templ", "templ Page() { <p></p> }\n")
+	changed := hash(4)
+	if changed == initial {
+		t.Error("hash did not change with a hashed file")
+	}
+	if err := os.Rename(filepath.Join(dir, "keep.log"), filepath.Join(dir, "kept.log")); err != nil {
+		t.Fatal(err)
+	}
+	if hash(4) == changed {
+		t.Error("hash did not change with a renamed file")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := calculateDirectoryHash(ctx, dir, excludes, 4); !errors.Is(err, context.Canceled) {
+		t.Errorf("hash with a canceled context: got error %v", err)
+	}
+}
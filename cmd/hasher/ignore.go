@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a pattern of a .gitignore file
+type ignoreRule struct {
+	// base is the slash separated directory of the .gitignore file,
+	// relative to the hashed directory, empty for the directory itself
+	base    string
+	pattern string
+	// negate re-includes the paths matched, for patterns starting with "!"
+	negate bool
+	// dirOnly matches directories only, for patterns ending in "/"
+	dirOnly bool
+	// anchored matches the path relative to base, for patterns holding a
+	// slash, instead of the name at any depth below base
+	anchored bool
+}
+
+// readIgnoreRules returns the rules of the .gitignore file in the directory
+// dir, which is base relative to the hashed directory, if any.
+func readIgnoreRules(dir, base string) ([]ignoreRule, error) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		line, rule.negate = strings.CutPrefix(line, "!")
+		line = strings.TrimPrefix(line, `\`)
+		line, rule.dirOnly = strings.CutSuffix(line, "/")
+		// "**/name" matches at any depth like "name"
+		line = strings.TrimPrefix(line, "**/")
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// ignored reports whether the slash separated path relative to the hashed
+// directory is ignored by rules, the last matching rule winning like in git.
+func ignored(rules []ignoreRule, rel string, isDir bool) bool {
+	result := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.match(rel) {
+			result = !rule.negate
+		}
+	}
+	return result
+}
+
+// match reports whether the rule matches the slash separated path rel.
+func (r ignoreRule) match(rel string) bool {
+	if r.base != "" {
+		var ok bool
+		rel, ok = strings.CutPrefix(rel, r.base+"/")
+		if !ok {
+			return false
+		}
+	}
+	if !r.anchored {
+		rel = path.Base(rel)
+	} else if prefix, suffix, ok := strings.Cut(r.pattern, "/**"); ok {
+		// "dir/**" matches everything below dir
+		if suffix == "" || suffix == "/" {
+			return strings.HasPrefix(rel, prefix+"/")
+		}
+	}
+	matched, err := path.Match(r.pattern, rel)
+	return err == nil && matched
+}
@@ -3,18 +3,16 @@ package main
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"hash"
-	"io"
-	"io/fs"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 )
 
 var (
@@ -23,6 +21,10 @@ var (
 	verbose         = flag.Bool("v", false, "Enable verbose output")
 	excludePatterns = flag.String("exclude", "", "Comma-separated list of glob patterns to exclude")
 	hashFilePath    = flag.String("cache", "", "Path to the cache file (defaults to .dir_hash.json in the directory)")
+	workers         = flag.Int("j", runtime.NumCPU(), "Number of files hashed concurrently")
+	watchMode       = flag.Bool("watch", false, "Keep hashing the directory, running the -exec command on every change, until interrupted")
+	execCommand     = flag.String("exec", "", "Shell command to run when changes are detected, like \"templ generate && twerge gen\"")
+	interval        = flag.Duration("interval", 500*time.Millisecond, "Time between two hashes of the directory in -watch mode")
 )
 
 const defaultHashFileName = ".cache.json"
@@ -93,6 +95,11 @@ func run() error {
 	var excludes []string
 	if *excludePatterns != "" {
 		excludes = strings.Split(*excludePatterns, ",")
+		for _, pattern := range excludes {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+			}
+		}
 		if *verbose {
 			fmt.Println("Excluding patterns:", excludes)
 		}
@@ -119,8 +126,22 @@ func run() error {
 		return fmt.Errorf("error loading cache: %w", err)
 	}
 
+	// a cache file in the directory would change its hash on every write
+	if rel, err := filepath.Rel(dirPathValue, hashFilePathValue); err == nil && filepath.IsLocal(rel) {
+		excludes = append(excludes, rel)
+	}
+
+	if *watchMode {
+		if *execCommand == "" {
+			return fmt.Errorf("-watch requires a command to run with -exec")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return watch(ctx, dirPathValue, excludes, cache, *execCommand, *interval, *workers)
+	}
+
 	// Calculate the hash of the directory
-	currentHash, err := calculateDirectoryHash(dirPathValue, excludes)
+	currentHash, err := calculateDirectoryHash(context.Background(), dirPathValue, excludes, *workers)
 	if err != nil {
 		if err == context.Canceled {
 			return err
@@ -158,98 +179,32 @@ func run() error {
 			fmt.Printf("Changes detected in %s\n", dirPathValue)
 		}
 
+		// With a command, the cache is only updated once it succeeded, so a
+		// failed command runs again next time, and holds the hash of the
+		// files it wrote
+		if *execCommand != "" {
+			if err := runCommand(context.Background(), *execCommand); err != nil {
+				return err
+			}
+			currentHash, err = calculateDirectoryHash(context.Background(), dirPathValue, excludes, *workers)
+			if err != nil {
+				return fmt.Errorf("error calculating directory hash: %w", err)
+			}
+		}
+
 		// Update the cache
 		cache.Hashes[dirPathValue] = currentHash
 		if err := cache.Close(); err != nil {
 			return fmt.Errorf("error writing cache: %w", err)
 		}
 
-		// Exit with code 1 to indicate changes were detected
+		// Exit with code 1 to indicate changes were detected, unless they
+		// were handled by the command
+		if *execCommand != "" {
+			return nil
+		}
 		os.Exit(1)
 	}
 	fmt.Printf("No changes detected in %s\n", dirPathValue)
 	return nil
 }
-
-// calculateDirectoryHash computes a hash of all files in the directory using MD5
-func calculateDirectoryHash(dirPath string, excludes []string) (string, error) {
-	var (
-		pattern    string
-		matched    bool
-		relPath    string
-		fileHash   string
-		hasher     = md5.New()
-		file       *os.File
-		fileHasher = md5.New()
-	)
-	walkErr := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		// Skip the hash file itself
-		if d.Name() == defaultHashFileName {
-			return nil
-		}
-		// Skip directories themselves (we'll recurse into them)
-		if d.IsDir() {
-			return nil
-		}
-		// Check if this path should be excluded
-		relPath, err = filepath.Rel(dirPath, path)
-		if err != nil {
-			return err
-		}
-		for _, pattern = range excludes {
-			matched, err = filepath.Match(pattern, relPath)
-			if err != nil {
-				return err
-			}
-			if matched {
-				return nil
-			}
-		}
-		// Calculate file hash
-		defer fileHasher.Reset()
-		fileHash, err = calculateFileHash(fileHasher, file, path)
-		if err != nil {
-			return err
-		}
-		_, err = io.WriteString(hasher, fileHash+"\n")
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-	if walkErr != nil {
-		if walkErr == context.Canceled {
-			return "", walkErr
-		}
-		return "", walkErr
-	}
-
-	hash := hasher.Sum(nil)
-	return hex.EncodeToString(hash), nil
-}
-
-// calculateFileHash computes the MD5 hash of a single file
-func calculateFileHash(hasher hash.Hash, file *os.File, filePath string) (string, error) {
-	var err error
-	file, err = os.Open(filePath)
-	if err != nil {
-		return "", err
-	}
-	defer func() {
-		err = file.Close()
-		if err != nil {
-			log.Printf("Error closing file: %s", err)
-		}
-	}()
-
-	// Use io.Copy to efficiently copy file content to hasher
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", err
-	}
-
-	hash := hasher.Sum(nil)
-	return hex.EncodeToString(hash), nil
-}
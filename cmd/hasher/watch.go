@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// watch hashes dirPath every interval until ctx is done, and runs command
+// whenever the hash differs from the one of the cache, including on start.
+//
+// The files changed while the command ran are told apart by what changed
+// them: files changed between runs are inputs, and the others are the
+// outputs of the command. The command runs again as long as it ran while an
+// input or a file it did not write before changed, so edits saved while it
+// runs are not lost, and files written by the command, like the output of
+// templ generate, do not run it again once it wrote them. A failed command is
+// logged and run again on the next change.
+func watch(ctx context.Context, dirPath string, excludes []string, cache *Cache, command string, interval time.Duration, workers int) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	inputs, outputs := map[string]bool{}, map[string]bool{}
+	var last []fileHash
+	for {
+		files, err := hashFiles(ctx, dirPath, excludes, workers)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error calculating directory hash: %w", err)
+		}
+		if last != nil {
+			for _, rel := range changedFiles(last, files) {
+				inputs[rel] = true
+			}
+		}
+
+		if combineFileHashes(files) != cache.Hashes[dirPath] {
+			fmt.Printf("Changes detected in %s, running %s\n", dirPath, command)
+			for {
+				err = runCommand(ctx, command)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if err != nil {
+					log.Printf("Error: %v", err)
+				}
+				after, err := hashFiles(ctx, dirPath, excludes, workers)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("error calculating directory hash: %w", err)
+				}
+				rerun := false
+				for _, rel := range changedFiles(files, after) {
+					if inputs[rel] || !outputs[rel] {
+						rerun = true
+					}
+					if !inputs[rel] {
+						outputs[rel] = true
+					}
+				}
+				files = after
+				if !rerun {
+					break
+				}
+				fmt.Printf("Changes detected in %s while running %s, running it again\n", dirPath, command)
+			}
+			cache.Hashes[dirPath] = combineFileHashes(files)
+			if err := cache.Close(); err != nil {
+				return fmt.Errorf("error writing cache: %w", err)
+			}
+		}
+		last = files
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runCommand runs the shell command with the output of the hasher, until it
+// exits or ctx is done.
+func runCommand(ctx context.Context, command string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %q: %w", command, err)
+	}
+	return nil
+}
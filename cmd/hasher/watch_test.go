@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(t.TempDir(), "runs")
+	if err := os.WriteFile(filepath.Join(dir, "page.templ"), []byte("templ Page() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := &Cache{Hashes: map[string]string{}, HashFile: filepath.Join(t.TempDir(), "cache.json")}
+
+	// the command writes into the watched directory, which must not run it
+	// again, and counts its runs outside of it
+	command := "echo run >> " + runs + " && date +%N > " + filepath.Join(dir, "out.txt")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watch(ctx, dir, nil, cache, command, 10*time.Millisecond, 2) }()
+
+	count := func() int {
+		content, _ := os.ReadFile(runs)
+		return strings.Count(string(content), "run")
+	}
+	waitFor := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for count() < want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := count(); got != want {
+			t.Fatalf("command ran %d times, want %d", got, want)
+		}
+	}
+
+	// the first run writes out.txt, which is not known yet as an output of
+	// the command, so the command runs once more
+	waitFor(2)
+	time.Sleep(100 * time.Millisecond)
+	waitFor(2)
+
+	if err := os.WriteFile(filepath.Join(dir, "page.templ"), []byte("templ Page() { <p></p> }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(3)
+	time.Sleep(100 * time.Millisecond)
+	waitFor(3)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if cache.Hashes[dir] == "" {
+		t.Fatal("watch did not cache the hash")
+	}
+}
+
+func TestWatchRerunsOnEditsDuringCommand(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(t.TempDir(), "runs")
+	page := filepath.Join(dir, "page.templ")
+	if err := os.WriteFile(page, []byte("templ Page() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := &Cache{Hashes: map[string]string{}, HashFile: filepath.Join(t.TempDir(), "cache.json")}
+
+	command := "echo run >> " + runs + " && sleep 0.3"
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watch(ctx, dir, nil, cache, command, 10*time.Millisecond, 2) }()
+
+	count := func() int {
+		content, _ := os.ReadFile(runs)
+		return strings.Count(string(content), "run")
+	}
+	waitFor := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for count() < want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := count(); got != want {
+			t.Fatalf("command ran %d times, want %d", got, want)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(page, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a file first changed while the command runs is not known as one of
+	// its outputs
+	waitFor(1)
+	write("templ Page() { <p></p> }\n")
+	waitFor(2)
+	time.Sleep(500 * time.Millisecond)
+	waitFor(2)
+
+	// an input saved again while the command runs
+	write("templ Page() { <p>1</p> }\n")
+	waitFor(3)
+	write("templ Page() { <p>2</p> }\n")
+	waitFor(4)
+	time.Sleep(500 * time.Millisecond)
+	waitFor(4)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
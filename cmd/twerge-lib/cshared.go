@@ -0,0 +1,37 @@
+//go:build cgo && !wasip1
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/conneroisu/twerge"
+)
+
+// TwergeMerge merges classes like twerge.Merge. The result is freed with
+// TwergeFree.
+//
+//export TwergeMerge
+func TwergeMerge(classes *C.char) *C.char {
+	return C.CString(twerge.Merge(C.GoString(classes)))
+}
+
+// TwergeGenerate returns the class name of classes like twerge.It. The
+// result is freed with TwergeFree.
+//
+//export TwergeGenerate
+func TwergeGenerate(classes *C.char) *C.char {
+	return C.CString(twerge.It(C.GoString(classes)))
+}
+
+// TwergeFree frees a string returned by the library.
+//
+//export TwergeFree
+func TwergeFree(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
@@ -0,0 +1,15 @@
+// Package main builds twerge as a library for programs not written in Go,
+// like Node based Tailwind pipelines and editor extensions, so they merge
+// classes and generate class names with the same logic as Go code.
+//
+// As a WASI reactor module, without cgo:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o twerge.wasm ./cmd/twerge-lib
+//
+// As a C shared library, which Go only builds with cgo:
+//
+//	go build -buildmode=c-shared -o libtwerge.so ./cmd/twerge-lib
+package main
+
+// main is not called by the hosts of the library.
+func main() {}
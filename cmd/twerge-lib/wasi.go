@@ -0,0 +1,62 @@
+//go:build wasip1
+
+package main
+
+import (
+	"unsafe"
+
+	"github.com/conneroisu/twerge"
+)
+
+// buffers keeps the memory shared with the host alive, by address, until the
+// host frees it
+var buffers = make(map[uint32][]byte)
+
+// alloc returns the address of size bytes of memory the host writes a class
+// string to, freed with twerge_free.
+//
+//go:wasmexport twerge_alloc
+func alloc(size uint32) uint32 {
+	buf := make([]byte, max(size, 1))
+	ptr := uint32(uintptr(unsafe.Pointer(unsafe.SliceData(buf))))
+	buffers[ptr] = buf
+	return ptr
+}
+
+// free releases the memory at ptr returned by twerge_alloc or as a result.
+//
+//go:wasmexport twerge_free
+func free(ptr uint32) {
+	delete(buffers, ptr)
+}
+
+// merge merges the class string of size bytes at ptr like twerge.Merge and
+// returns the address of the result in the high and its length in the low 32
+// bits.
+//
+//go:wasmexport twerge_merge
+func merge(ptr, size uint32) uint64 {
+	return result(twerge.Merge(input(ptr, size)))
+}
+
+// generate returns the class name of the class string of size bytes at ptr
+// like twerge.It, as twerge_merge.
+//
+//go:wasmexport twerge_generate
+func generate(ptr, size uint32) uint64 {
+	return result(twerge.It(input(ptr, size)))
+}
+
+// input returns the string of size bytes at ptr, allocated with alloc.
+func input(ptr, size uint32) string {
+	buf := buffers[ptr]
+	return string(buf[:min(size, uint32(len(buf)))])
+}
+
+// result copies s to memory the host frees and returns its address and
+// length.
+func result(s string) uint64 {
+	ptr := alloc(uint32(len(s)))
+	copy(buffers[ptr], s)
+	return uint64(ptr)<<32 | uint64(len(s))
+}
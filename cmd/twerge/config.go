@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/conneroisu/twerge"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the name of the twerge configuration file
+const configFileName = "twerge.yaml"
+
+// config is the content of twerge.yaml
+type config struct {
+	// InputCSS is the Tailwind input CSS file holding the twerge markers
+	InputCSS string `yaml:"input_css"`
+	// Package is the directory of the generated Go package
+	Package string `yaml:"package"`
+	// Templates are the directories scanned for .templ files
+	Templates []string `yaml:"templates"`
+	// TailwindVersion overrides the detected Tailwind major version, 3 or 4
+	TailwindVersion int `yaml:"tailwind_version,omitempty"`
+	// ClassPrefix is the prefix of generated class names, "tw-" if empty
+	ClassPrefix string `yaml:"class_prefix,omitempty"`
+	// Naming is the naming strategy of generated class names, sequential,
+	// sha1, sha256, xxhash or readable
+	Naming string `yaml:"naming,omitempty"`
+	// HashLength is the number of hash characters of hashed class names
+	HashLength int `yaml:"hash_length,omitempty"`
+	// Dedupe gives class strings merging to the same classes one class name
+	Dedupe bool `yaml:"dedupe,omitempty"`
+	// TemplStub is the .templ file written with a TwergeStyles component,
+	// none if empty
+	TemplStub string `yaml:"templ_stub,omitempty"`
+	// ShardFile, if not empty, splits the class map into Go files of this
+	// name in the directories of the templates, instead of the one file in
+	// Package, see twerge.GenOptions.ShardFile
+	ShardFile string `yaml:"shard_file,omitempty"`
+	// TailwindConfig is the Tailwind configuration whose theme extends the
+	// class groups, the tailwind.config.* file next to twerge.yaml if empty
+	TailwindConfig string `yaml:"tailwind_config,omitempty"`
+}
+
+// tailwindConfigNames are the names of the Tailwind configuration files
+// looked up by loadTheme
+var tailwindConfigNames = []string{
+	"tailwind.config.js",
+	"tailwind.config.cjs",
+	"tailwind.config.mjs",
+	"tailwind.config.ts",
+	"tailwind.config.json",
+}
+
+// applyTheme extends conf with the theme of the Tailwind configuration of cfg
+// below root, if any, and makes conf the config of twerge.Validate.
+func applyTheme(root string, cfg config, conf *twerge.Config) error {
+	path := ""
+	if cfg.TailwindConfig != "" {
+		path = filepath.Join(root, cfg.TailwindConfig)
+	} else {
+		for _, name := range tailwindConfigNames {
+			if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+				path = filepath.Join(root, name)
+				break
+			}
+		}
+	}
+	if path != "" {
+		theme, err := twerge.LoadTailwindTheme(path)
+		if err != nil {
+			return err
+		}
+		conf.WithTheme(theme)
+	}
+	// the cache of conf is not shared with the merger of Validate
+	global := *conf
+	global.Cache = nil
+	twerge.SetConfig(&global)
+	return nil
+}
+
+// mergeConfig returns the twerge.Config of the Tailwind version, naming and
+// theme configured in cfg below root, see applyTheme.
+func mergeConfig(root string, cfg config) (*twerge.Config, error) {
+	version, err := selectTailwindVersion(root, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := twerge.DefaultConfig()
+	if version != twerge.TailwindUnknown {
+		conf.TailwindVersion = version
+	}
+	conf.ClassPrefix = cfg.ClassPrefix
+	conf.HashLength = cfg.HashLength
+	conf.Dedupe = cfg.Dedupe
+	if cfg.Naming != "" {
+		conf.Naming, err = twerge.ParseNamingStrategy(cfg.Naming)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = applyTheme(root, cfg, conf)
+	if err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// selectTailwindVersion selects the Tailwind version configured in cfg, or
+// the one detected in dir if none is configured.
+func selectTailwindVersion(dir string, cfg config) (twerge.TailwindVersion, error) {
+	if cfg.TailwindVersion != 0 {
+		v := twerge.TailwindVersion(cfg.TailwindVersion)
+		if v != twerge.TailwindV3 && v != twerge.TailwindV4 {
+			return v, fmt.Errorf("unsupported tailwind_version %d", cfg.TailwindVersion)
+		}
+		twerge.SetTailwindVersion(v)
+		return v, nil
+	}
+	return twerge.UseDetectedTailwindVersion(dir)
+}
+
+// defaultConfig returns the configuration written by twerge init
+func defaultConfig() config {
+	return config{
+		InputCSS:  "static/input.css",
+		Package:   "classes",
+		Templates: []string{"."},
+	}
+}
+
+// loadConfig reads the configuration from path
+func loadConfig(path string) (config, error) {
+	cfg := defaultConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	err = yaml.Unmarshal(data, &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// writeConfig writes the configuration to path
+func writeConfig(path string, cfg config) error {
+	body, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	body = append([]byte("# twerge configuration, see https://github.com/conneroisu/twerge\n"), body...)
+	err = os.WriteFile(path, body, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/conneroisu/twerge"
+)
+
+// tempFileRegex matches the temporary files left behind by an interrupted
+// generation, e.g. .input.css.123456
+var tempFileRegex = regexp.MustCompile(`^\..+\.\d+$`)
+
+// check is a single diagnostic of twerge doctor
+type check struct {
+	// name describes what is checked
+	name string
+	// run returns a problem with its fix, or an empty string if the check passed
+	run func() (problem, fix string)
+}
+
+func runDoctor(args []string) error {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Path to the module root")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return doctor(os.Stdout, *dir)
+}
+
+// doctor runs every check against the module in dir, printing the results
+// to w, and returns an error if any check failed
+func doctor(w io.Writer, dir string) error {
+	cfg, err := loadConfig(filepath.Join(dir, configFileName))
+	if err != nil {
+		fmt.Fprintf(w, "FAIL %s\n     %v\n     fix: run twerge init\n", configFileName, err)
+		return errors.New("1 check failed")
+	}
+	inputCSS := filepath.Join(dir, cfg.InputCSS)
+	if _, err := selectTailwindVersion(dir, cfg); err != nil {
+		return err
+	}
+
+	var rules map[string]string
+	generated, classMap, classMapErr := readGenerated(dir, cfg)
+	checks := []check{
+		{
+			name: "twerge markers in " + cfg.InputCSS,
+			run: func() (string, string) {
+				rules, err = twerge.ReadTailwindSection(inputCSS)
+				if err != nil {
+					return err.Error(), "run twerge init to add the markers"
+				}
+				return "", ""
+			},
+		},
+		{
+			name: "generated class map matches " + cfg.InputCSS,
+			run: func() (string, string) {
+				if classMapErr != nil {
+					return classMapErr.Error(), "run go generate ./" + cfg.Package
+				}
+				if rules == nil {
+					return "the input CSS could not be read", "fix the twerge markers first"
+				}
+				var missing []string
+				for _, name := range classMap {
+					if _, ok := rules[name]; !ok {
+						missing = append(missing, name)
+					}
+				}
+				if len(missing) > 0 {
+					sort.Strings(missing)
+					return "no rules for " + strings.Join(slices.Compact(missing), ", "), "run twerge templ to regenerate the input CSS"
+				}
+				return "", ""
+			},
+		},
+		{
+			name: cmp.Or(cfg.ShardFile, genFileName) + " is not edited by hand",
+			run: func() (string, string) {
+				for _, path := range generated {
+					code, err := os.ReadFile(path)
+					if err != nil {
+						continue
+					}
+					if checksum, ok := twerge.ClassMapCodeChecksum(code); checksum != "" && !ok {
+						file := "the file"
+						if cfg.ShardFile != "" {
+							file, _ = filepath.Rel(dir, path)
+						}
+						return file + " does not match its checksum " + checksum, "run go generate ./" + cfg.Package + " and register custom names with twerge.RegisterClasses instead"
+					}
+				}
+				return "", ""
+			},
+		},
+		{
+			name: "no class name collisions",
+			run: func() (string, string) {
+				if collisions := findCollisions(classMap); len(collisions) > 0 {
+					return strings.Join(collisions, "; "), "register the colliding class strings under distinct names"
+				}
+				return "", ""
+			},
+		},
+		{
+			name: "Tailwind scans .templ files",
+			run: func() (string, string) {
+				return checkTemplContent(dir, inputCSS)
+			},
+		},
+		{
+			name: "no stale temporary files",
+			run: func() (string, string) {
+				stale, err := findStaleFiles(filepath.Dir(inputCSS))
+				if err != nil {
+					return err.Error(), "check the permissions of " + filepath.Dir(inputCSS)
+				}
+				if len(stale) > 0 {
+					return "found " + strings.Join(stale, ", "), "remove the files, they were left by an interrupted generation"
+				}
+				return "", ""
+			},
+		},
+	}
+
+	failed := 0
+	for _, c := range checks {
+		problem, fix := c.run()
+		if problem == "" {
+			fmt.Fprintf(w, "ok   %s\n", c.name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL %s\n     %s\n     fix: %s\n", c.name, problem, fix)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
+
+// readGenerated returns the paths of the Go files twerge gen writes the class
+// map of cfg to below dir, and their class map: the file in the package of
+// cfg, or the shards named cfg.ShardFile in the template directories.
+func readGenerated(dir string, cfg config) ([]string, map[string]string, error) {
+	if cfg.ShardFile == "" {
+		classMap, err := readGeneratedClassMap(filepath.Join(dir, cfg.Package))
+		return []string{filepath.Join(dir, cfg.Package, genFileName)}, classMap, err
+	}
+	var paths []string
+	classMap := make(map[string]string)
+	for _, templates := range cfg.Templates {
+		err := filepath.WalkDir(filepath.Join(dir, templates), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || d.Name() != cfg.ShardFile {
+				return err
+			}
+			code, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			shard, err := twerge.ReadClassMapCode(code)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", path, err)
+			}
+			paths = append(paths, path)
+			maps.Copy(classMap, shard)
+			return nil
+		})
+		if err != nil {
+			return paths, nil, err
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no generated %s found", cfg.ShardFile)
+	}
+	return paths, classMap, nil
+}
+
+// readGeneratedClassMap returns the ClassMapStr literal of the generated Go
+// package in dir
+func readGeneratedClassMap(dir string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range matches {
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if name.Name != "ClassMapStr" || i >= len(vs.Values) {
+						continue
+					}
+					lit, ok := vs.Values[i].(*ast.CompositeLit)
+					if !ok {
+						return nil, fmt.Errorf("ClassMapStr in %s is not a map literal", path)
+					}
+					return mapLiteral(lit), nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("no generated ClassMapStr found in %s", dir)
+}
+
+// mapLiteral returns the string entries of a map composite literal
+func mapLiteral(lit *ast.CompositeLit) map[string]string {
+	m := make(map[string]string, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, keyOK := kv.Key.(*ast.BasicLit)
+		value, valueOK := kv.Value.(*ast.BasicLit)
+		if !keyOK || !valueOK {
+			continue
+		}
+		k, errK := strconv.Unquote(key.Value)
+		v, errV := strconv.Unquote(value.Value)
+		if errK == nil && errV == nil {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// findCollisions returns the class names used for class strings merging to
+// different values
+func findCollisions(classMap map[string]string) []string {
+	merged := make(map[string]map[string]bool)
+	for original, name := range classMap {
+		fields := strings.Fields(twerge.Merge(original))
+		sort.Strings(fields)
+		if merged[name] == nil {
+			merged[name] = make(map[string]bool)
+		}
+		merged[name][strings.Join(fields, " ")] = true
+	}
+
+	var collisions []string
+	for name, values := range merged {
+		if len(values) > 1 {
+			collisions = append(collisions, fmt.Sprintf("%s is used for %d different class sets", name, len(values)))
+		}
+	}
+	sort.Strings(collisions)
+	return collisions
+}
+
+// checkTemplContent checks that Tailwind scans the .templ files of the module
+func checkTemplContent(dir, inputCSS string) (problem, fix string) {
+	for _, name := range tailwindConfigs {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(string(content), ".templ") {
+			return name + " content does not include .templ files", `add "./**/*.templ" to the content setting of ` + name
+		}
+		return "", ""
+	}
+
+	// v4 detects sources automatically unless disabled
+	css, err := os.ReadFile(inputCSS)
+	if err != nil {
+		return "", ""
+	}
+	if strings.Contains(string(css), "source(none)") && !strings.Contains(string(css), ".templ") {
+		return "automatic source detection is disabled", `add @source "./**/*.templ"; to ` + filepath.Base(inputCSS)
+	}
+	return "", ""
+}
+
+// findStaleFiles returns the temporary files left in dir by an interrupted
+// generation
+func findStaleFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, entry := range entries {
+		if !entry.IsDir() && tempFileRegex.MatchString(entry.Name()) {
+			stale = append(stale, entry.Name())
+		}
+	}
+	return stale, nil
+}
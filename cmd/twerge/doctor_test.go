@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctor(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":             "module example.com/app\n",
+		"tailwind.config.js": "module.exports = { content: ['./**/*.html'] }\n",
+		configFileName:       "input_css: static/input.css\npackage: classes\n",
+		"static/input.css": "@tailwind base;\n/* twerge:begin */\n" +
+			".tw-a { \n\t@apply p-4; \n}\n/* twerge:end */\n",
+		"static/.input.css.12345": "",
+		"classes/classes_gen.go": "package classes\n\nvar ClassMapStr = map[string]string{\n" +
+			"\t\"p-2 p-4\": \"tw-a\",\n\t\"flex\": \"tw-b\",\n\t\"block\": \"tw-b\",\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	var out strings.Builder
+	err := doctor(&out, dir)
+	assert.EqualError(t, err, "4 checks failed")
+	assert.Contains(t, out.String(), "ok   twerge markers in static/input.css")
+	assert.Contains(t, out.String(), "no rules for tw-b")
+	assert.Contains(t, out.String(), "tw-b is used for 2 different class sets")
+	assert.Contains(t, out.String(), `add "./**/*.templ" to the content setting of tailwind.config.js`)
+	assert.Contains(t, out.String(), "found .input.css.12345")
+
+	// fix everything
+	files["tailwind.config.js"] = "module.exports = { content: ['./**/*.templ'] }\n"
+	files["classes/classes_gen.go"] = "package classes\n\nvar ClassMapStr = map[string]string{\"p-2 p-4\": \"tw-a\"}\n"
+	for _, name := range []string{"tailwind.config.js", "classes/classes_gen.go"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(files[name]), 0644))
+	}
+	assert.NoError(t, os.Remove(filepath.Join(dir, "static/.input.css.12345")))
+
+	out.Reset()
+	assert.NoError(t, doctor(&out, dir))
+	assert.NotContains(t, out.String(), "FAIL")
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/conneroisu/twerge"
+	"github.com/conneroisu/twerge/scan"
+	"github.com/fsnotify/fsnotify"
+)
+
+// genFileName is the Go file written by twerge gen
+const genFileName = "classes_gen.go"
+
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	configPath := flags.String("config", "", "Path to "+configFileName+" (defaults to the nearest one in the current or a parent directory)")
+	goPath := flags.String("out", "", "Go file to write (defaults to "+genFileName+" in the package of "+configFileName+", or none with shard_file)")
+	cssPath := flags.String("css", "", "Tailwind input CSS to update (defaults to input_css of "+configFileName+")")
+	cachePath := flags.String("cache", "", "Cache merge results in this file, so unchanged class strings are not merged again")
+	watch := flags.Bool("watch", false, "Regenerate whenever a source file changes, until interrupted")
+	strict := flags.Bool("strict", false, "Fail when a class is not a known Tailwind utility or color")
+	prune := flags.Bool("prune", false, "Remove the class strings of the existing class map no longer found in the templates")
+	checkOnly := flags.Bool("check", false, "Print the diff of the generated files to the files on disk instead of writing them, and fail if they differ")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *checkOnly && *watch {
+		return fmt.Errorf("%w: -check and -watch can not be combined", errUsage)
+	}
+
+	path := *configPath
+	if path == "" {
+		var err error
+		path, err = findConfig(".")
+		if err != nil {
+			return err
+		}
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	root := filepath.Dir(path)
+	if *goPath == "" && cfg.ShardFile == "" {
+		*goPath = filepath.Join(root, cfg.Package, genFileName)
+	}
+	if *cssPath == "" {
+		*cssPath = filepath.Join(root, cfg.InputCSS)
+	}
+
+	if *checkOnly {
+		return genCheck(os.Stdout, root, cfg, *goPath, *cssPath, *strict, *prune)
+	}
+
+	regenerate := func() error {
+		err := gen(root, cfg, *goPath, *cssPath, *cachePath, *strict, *prune)
+		if err != nil {
+			return err
+		}
+		if *goPath != "" {
+			fmt.Println("generated", *goPath)
+		}
+		if cfg.ShardFile != "" {
+			fmt.Println("generated", cfg.ShardFile, "shards")
+		}
+		fmt.Println("updated", *cssPath)
+		return nil
+	}
+	err = regenerate()
+	if !*watch {
+		return err
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	dirs := make([]string, len(cfg.Templates))
+	for i, dir := range cfg.Templates {
+		dirs[i] = filepath.Join(root, dir)
+	}
+	fmt.Println("watching", strings.Join(dirs, ", "))
+	return watchSources(ctx, dirs, *goPath, func() {
+		if err := regenerate(); err != nil {
+			log.Printf("Error: %v", err)
+		}
+	})
+}
+
+// gen scans the templates of cfg below root, writes the class map to goPath
+// and the rules to the twerge section of cssPath, see twerge.GenerateAll.
+//
+// If cachePath is not empty, merge results are read from and saved to the
+// file at cachePath.
+//
+// Classes that are likely typos, see twerge.Validate, are logged, or fail
+// the generation before any file is written if strict is true.
+//
+// The class strings of the existing class map at goPath are kept, unless
+// prune is true and they are no longer found in the templates.
+func gen(root string, cfg config, goPath, cssPath, cachePath string, strict, prune bool) error {
+	conf, err := mergeConfig(root, cfg)
+	if err != nil {
+		return err
+	}
+	var cache *twerge.FileCache
+	if cachePath != "" {
+		cache, err = twerge.NewFileCache(cachePath)
+		if err != nil {
+			return err
+		}
+		conf.Cache = cache
+	}
+
+	err = twerge.GenerateAll(genOptions(root, cfg, goPath, cssPath, conf, strict, prune))
+	if err != nil {
+		return err
+	}
+	if cache != nil {
+		return cache.Flush()
+	}
+	return nil
+}
+
+// errStale is returned by twerge gen -check if a generated file is out of
+// date
+var errStale = errors.New("generated files are out of date, run twerge gen")
+
+// genCheck writes the unified diff of the files generated by gen, with the same
+// arguments, to the files on disk to w, and returns errStale if there is a
+// difference. No file is written.
+func genCheck(w io.Writer, root string, cfg config, goPath, cssPath string, strict, prune bool) error {
+	conf, err := mergeConfig(root, cfg)
+	if err != nil {
+		return err
+	}
+	diff, err := twerge.CheckAll(genOptions(root, cfg, goPath, cssPath, conf, strict, prune))
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		return nil
+	}
+	_, err = io.WriteString(w, diff)
+	if err != nil {
+		return err
+	}
+	return errStale
+}
+
+// genOptions returns the options of twerge.GenerateAll for gen.
+func genOptions(root string, cfg config, goPath, cssPath string, conf *twerge.Config, strict, prune bool) twerge.GenOptions {
+	opts := twerge.GenOptions{
+		GoFile:    goPath,
+		ShardFile: cfg.ShardFile,
+		CSSFile:   cssPath,
+		Config:    conf,
+		Prune:     prune,
+	}
+	if goPath != "" {
+		opts.Package = packageName(filepath.Dir(goPath))
+	}
+	for _, dir := range cfg.Templates {
+		opts.Dirs = append(opts.Dirs, filepath.Join(root, dir))
+	}
+	if cfg.TemplStub != "" {
+		opts.TemplFile = filepath.Join(root, cfg.TemplStub)
+	}
+	opts.OnWarning = func(classes string, warning twerge.Warning) error {
+		if strict {
+			return fmt.Errorf("%q: %s", classes, warning)
+		}
+		log.Printf("warning: %q: %s", classes, warning)
+		return nil
+	}
+	return opts
+}
+
+// watchDebounce is how long watchSources waits for more changes before
+// calling onChange, as editors and templ write several files at once
+const watchDebounce = 100 * time.Millisecond
+
+// watchSources calls onChange whenever a file scanned by twerge gen changes
+// below dirs, until ctx is done. Changes to the generated file at ignore are
+// skipped so regenerating does not trigger itself.
+func watchSources(ctx context.Context, dirs []string, ignore string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, dir := range dirs {
+		if err := watchDirs(watcher, dir); err != nil {
+			return err
+		}
+	}
+	ignore, _ = filepath.Abs(ignore)
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchDirs(watcher, event.Name); err != nil {
+						log.Printf("Error: %v", err)
+					}
+					continue
+				}
+			}
+			if event.Op == fsnotify.Chmod || !scan.Scanned(event.Name) {
+				continue
+			}
+			if path, _ := filepath.Abs(event.Name); path == ignore {
+				continue
+			}
+			debounce.Reset(watchDebounce)
+		case <-debounce.C:
+			onChange()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Error: %v", err)
+		}
+	}
+}
+
+// watchDirs adds dir and its subdirectories to the watcher, skipping the
+// directories twerge.ScanClasses skips.
+func watchDirs(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != dir && (name == "node_modules" || name == "vendor" || strings.HasPrefix(name, ".")) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("error watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// findConfig returns the path of the nearest configuration file in dir or
+// one of its parents.
+func findConfig(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("error resolving %s: %w", dir, err)
+	}
+	for {
+		path := filepath.Join(abs, configFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("error reading %s: %w", path, err)
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", fmt.Errorf("no %s found in %s or its parents, run twerge init first", configFileName, dir)
+		}
+		abs = parent
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/twerge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunGen(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n",
+		configFileName:     "input_css: static/input.css\npackage: classes\ntemplates: [views]\ntempl_stub: views/styles.templ\n",
+		"static/input.css": "@tailwind base;\n/* twerge:begin */\n/* twerge:end */\n",
+		"views/page.templ": "package views\n\ntempl Page() {\n" +
+			"\t<div class={ twerge.It(\"p-2 p-4\") }></div>\n" +
+			"\t<span class=\"flex items-center\"></span>\n}\n",
+		"views/handler.go": "package views\n\nvar title = twerge.Merge(\"text-sm text-lg\")\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// the config is found in a parent directory, like with go generate
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(filepath.Join(dir, "views")))
+	defer func() { _ = os.Chdir(wd) }()
+	assert.NoError(t, run([]string{"gen"}))
+
+	code, err := os.ReadFile(filepath.Join(dir, "classes", genFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), "package classes")
+	assert.Contains(t, string(code), "twerge.RegisterClasses(ClassMapStr)")
+	// names follow the sorted class strings
+	for classes, name := range map[string]string{"flex items-center": "tw-0", "p-2 p-4": "tw-1", "text-sm text-lg": "tw-2"} {
+		assert.Regexp(t, `"`+classes+`":\s+"`+name+`"`, string(code))
+	}
+
+	stub, err := os.ReadFile(filepath.Join(dir, "views", "styles.templ"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(stub), "templ TwergeStyles() {")
+
+	rules, err := twerge.ReadTailwindSection(filepath.Join(dir, "static", "input.css"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"tw-0": "flex items-center", "tw-1": "p-4", "tw-2": "text-lg"}, rules)
+
+	// an up to date class map is not rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "classes", genFileName), past, past))
+	assert.NoError(t, run([]string{"gen"}))
+	info, err := os.Stat(filepath.Join(dir, "classes", genFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, past, info.ModTime())
+
+	// checks pass for up to date files and print the diff of stale ones
+	assert.NoError(t, run([]string{"gen", "-check"}))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "views", "typo.templ"), []byte("package views\n\ntempl Typo() {\n\t<p class=\"text-red-5000\"></p>\n}\n"), 0644))
+	cfg, err := loadConfig(filepath.Join(dir, configFileName))
+	assert.NoError(t, err)
+	var out strings.Builder
+	goPath, cssPath := filepath.Join(dir, "classes", genFileName), filepath.Join(dir, "static", "input.css")
+	assert.ErrorIs(t, genCheck(&out, dir, cfg, goPath, cssPath, false, false), errStale)
+	assert.Contains(t, out.String(), "+++ "+goPath+" (generated)\n")
+	assert.Contains(t, out.String(), "+.tw-3 { \n+\t@apply text-red-5000; \n+}\n")
+	assert.ErrorIs(t, run([]string{"gen", "-check", "-watch"}), errUsage)
+
+	// typos fail strict generations
+	assert.ErrorContains(t, run([]string{"gen", "-strict"}), `"text-red-5000": text-red-5000: unknown color "red-5000"`)
+	assert.NoError(t, run([]string{"gen"}))
+
+	// removed class strings stay until pruned
+	assert.NoError(t, os.Remove(filepath.Join(dir, "views", "typo.templ")))
+	assert.NoError(t, run([]string{"gen"}))
+	code, err = os.ReadFile(filepath.Join(dir, "classes", genFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), `"text-red-5000"`)
+	assert.NoError(t, run([]string{"gen", "-prune"}))
+	code, err = os.ReadFile(filepath.Join(dir, "classes", genFileName))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(code), `"text-red-5000"`)
+	assert.Regexp(t, `"text-sm text-lg":\s+"tw-2"`, string(code))
+
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	assert.ErrorContains(t, run([]string{"gen"}), "run twerge init first")
+}
+
+func TestWatchSources(t *testing.T) {
+	dir := t.TempDir()
+	generated := filepath.Join(dir, genFileName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- watchSources(ctx, []string{dir}, generated, func() { changes <- struct{}{} })
+	}()
+	// give the watcher time to start
+	time.Sleep(50 * time.Millisecond)
+
+	write := func(name string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte("templ A() {}\n"), 0644))
+	}
+	expect := func(changed bool) {
+		t.Helper()
+		select {
+		case <-changes:
+			assert.True(t, changed, "unexpected change")
+		case <-time.After(10 * watchDebounce):
+			assert.False(t, changed, "missed change")
+		}
+	}
+
+	write("page.templ")
+	write("page_templ.go")
+	expect(true)
+
+	// generated and unrelated files do not trigger a regeneration
+	write(genFileName)
+	write("page_templ.go")
+	write("styles.css")
+	expect(false)
+
+	// new directories are watched
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "views"), 0755))
+	time.Sleep(50 * time.Millisecond)
+	write("views/list.templ")
+	expect(true)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
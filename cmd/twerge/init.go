@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/conneroisu/twerge"
+)
+
+// skippedDirs are directories never scanned for project files
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// tailwindConfigs are the file names of a Tailwind config
+var tailwindConfigs = []string{
+	"tailwind.config.js",
+	"tailwind.config.cjs",
+	"tailwind.config.mjs",
+	"tailwind.config.ts",
+}
+
+// twergeModule is the module the generated package imports
+const twergeModule = "github.com/conneroisu/twerge"
+
+// goGet adds module to the requirements of the module rooted at dir,
+// replaced by tests
+var goGet = func(dir, module string) error {
+	cmd := exec.Command("go", "get", module)
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running go get %s: %w", module, err)
+	}
+	return nil
+}
+
+// project is what twerge init detected about a module
+type project struct {
+	// Dir is the module root
+	Dir string
+	// HasTempl is true if the module uses templ
+	HasTempl bool
+	// HasTwerge is true if the module requires twerge, or is twerge
+	HasTwerge bool
+	// HasTailwind is true if the module uses Tailwind
+	HasTailwind bool
+	// InputCSS is the Tailwind input CSS file relative to Dir, if found
+	InputCSS string
+}
+
+func runInit(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Path to the module root")
+	cssPath := flags.String("css", "", "Tailwind input CSS file (detected if empty)")
+	pkgDir := flags.String("pkg", "", "Directory of the generated Go package (defaults to classes)")
+	force := flags.Bool("force", false, "Overwrite an existing "+configFileName)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	proj, err := detectProject(*dir)
+	if err != nil {
+		return err
+	}
+	if !proj.HasTempl {
+		fmt.Println("warning: templ was not found in this module")
+	}
+	if !proj.HasTailwind {
+		fmt.Println("warning: Tailwind was not found in this module")
+	}
+
+	cfg := defaultConfig()
+	if proj.InputCSS != "" {
+		cfg.InputCSS = proj.InputCSS
+	}
+	if *cssPath != "" {
+		cfg.InputCSS = *cssPath
+	}
+	if *pkgDir != "" {
+		cfg.Package = *pkgDir
+	}
+
+	version, err := selectTailwindVersion(proj.Dir, cfg)
+	if err != nil {
+		return err
+	}
+	if version != twerge.TailwindUnknown {
+		fmt.Println("detected Tailwind", version)
+	}
+
+	configPath := filepath.Join(proj.Dir, configFileName)
+	if _, err := os.Stat(configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", configPath)
+	}
+	if err := writeConfig(configPath, cfg); err != nil {
+		return err
+	}
+	fmt.Println("wrote", configPath)
+
+	inputCSS := filepath.Join(proj.Dir, cfg.InputCSS)
+	if err := twerge.EnsureMarkers(inputCSS); err != nil {
+		return err
+	}
+	fmt.Println("added twerge markers to", inputCSS)
+
+	if err := writeGenPackage(filepath.Join(proj.Dir, cfg.Package)); err != nil {
+		return err
+	}
+	fmt.Println("created generated package", filepath.Join(proj.Dir, cfg.Package))
+
+	// the generated package imports twerge, so the module must require it
+	// for go generate and go build
+	if !proj.HasTwerge {
+		if err := goGet(proj.Dir, twergeModule); err != nil {
+			fmt.Printf("warning: %v\nrun this in %s before go generate:\n\tgo get %s\n", err, proj.Dir, twergeModule)
+			return nil
+		}
+		fmt.Println("added", twergeModule, "to", filepath.Join(proj.Dir, "go.mod"))
+	}
+	return nil
+}
+
+// detectProject inspects the module rooted at dir
+func detectProject(dir string) (project, error) {
+	proj := project{Dir: dir}
+
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return proj, fmt.Errorf("%s is not a module root: %w", dir, err)
+	}
+	proj.HasTempl = bytes.Contains(goMod, []byte("github.com/a-h/templ"))
+	proj.HasTwerge = bytes.Contains(goMod, []byte(twergeModule+" ")) ||
+		bytes.Contains(goMod, []byte("module "+twergeModule+"\n"))
+
+	for _, name := range tailwindConfigs {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			proj.HasTailwind = true
+		}
+	}
+	if pkg, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		proj.HasTailwind = proj.HasTailwind || bytes.Contains(pkg, []byte(`"tailwindcss"`))
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".templ":
+			proj.HasTempl = true
+		case ".css":
+			if proj.InputCSS != "" {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if isTailwindInput(content) {
+				proj.HasTailwind = true
+				proj.InputCSS, err = filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return proj, fmt.Errorf("error scanning %s: %w", dir, err)
+	}
+	return proj, nil
+}
+
+// isTailwindInput returns true if the CSS content contains Tailwind directives
+func isTailwindInput(content []byte) bool {
+	return bytes.Contains(content, []byte("@tailwind ")) ||
+		bytes.Contains(content, []byte(`@import "tailwindcss"`)) ||
+		bytes.Contains(content, []byte(`@import 'tailwindcss'`))
+}
+
+// writeGenPackage creates the generated Go package in dir, keeping existing files
+func writeGenPackage(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	pkgName := packageName(dir)
+
+	files := map[string]string{
+		"generate.go": "// Package " + pkgName + " holds the class map generated by twerge.\n" +
+			"package " + pkgName + "\n\n" +
+			"//go:generate go run github.com/conneroisu/twerge/cmd/twerge gen\n",
+		"classes_gen.go": twerge.GenerateClassMapCode(pkgName),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// packageName derives a Go package name from a directory
+func packageName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, filepath.Base(abs))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "classes"
+	}
+	return name
+}
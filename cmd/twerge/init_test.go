@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/twerge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":              "module example.com/app\n\nrequire github.com/a-h/templ v0.3.857\n",
+		"tailwind.config.js":  "module.exports = {}\n",
+		"assets/css/main.css": "@tailwind base;\n@tailwind utilities;\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	var got []string
+	stubGoGet(t, func(dir, module string) error {
+		got = append(got, dir, module)
+		return nil
+	})
+	assert.NoError(t, run([]string{"init", "-dir", dir}))
+	assert.Equal(t, []string{dir, twergeModule}, got)
+
+	cfg, err := loadConfig(filepath.Join(dir, configFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("assets", "css", "main.css"), cfg.InputCSS)
+	assert.Equal(t, "classes", cfg.Package)
+
+	css, err := os.ReadFile(filepath.Join(dir, "assets", "css", "main.css"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(css), "/* twerge:begin */")
+
+	generate, err := os.ReadFile(filepath.Join(dir, "classes", "generate.go"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(generate), "// Package classes"))
+	assert.Contains(t, string(generate), "//go:generate go run github.com/conneroisu/twerge/cmd/twerge gen")
+	_, err = os.Stat(filepath.Join(dir, "classes", "classes_gen.go"))
+	assert.NoError(t, err)
+
+	// existing configs are not overwritten without -force
+	assert.Error(t, run([]string{"init", "-dir", dir}))
+	assert.NoError(t, run([]string{"init", "-dir", dir, "-force"}))
+}
+
+func TestRunInitThenGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a module")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.24\n",
+		"static/input.css": "@tailwind base;\n@tailwind utilities;\n",
+		"views/page.templ": "package views\n\ntempl Page() {\n\t<div class=\"p-2 p-4\"></div>\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	assert.NoError(t, err)
+	goCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	// twerge is required from this tree instead of the module proxy
+	stubGoGet(t, func(dir, module string) error {
+		goCmd("mod", "edit", "-replace="+module+"="+root)
+		goCmd("get", module+"@v0.0.0")
+		return nil
+	})
+
+	assert.NoError(t, run([]string{"init", "-dir", dir}))
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(goMod), twergeModule+" v0.0.0")
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+	assert.NoError(t, run([]string{"gen"}))
+	code, err := os.ReadFile(filepath.Join(dir, "classes", genFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), `"p-2 p-4"`)
+	goCmd("build", "./...")
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	assert.ErrorIs(t, run(nil), errUsage)
+	assert.ErrorIs(t, run([]string{"nope"}), errUsage)
+}
+
+func TestWriteSuggestionSnippet(t *testing.T) {
+	var out strings.Builder
+	err := writeSuggestionSnippet(&out, []twerge.Suggestion{{
+		Classes:  "flex items-center p-4",
+		Variants: []string{"p-4 flex items-center"},
+		Name:     "flex-items-center",
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "twerge.RegisterClasses(map[string]string{\n"+
+		"\t\"flex items-center p-4\": \"flex-items-center\",\n"+
+		"\t\"p-4 flex items-center\": \"flex-items-center\",\n"+
+		"})\n", out.String())
+}
+
+// stubGoGet replaces goGet with get until the end of the test.
+func stubGoGet(t *testing.T, get func(dir, module string) error) {
+	previous := goGet
+	goGet = get
+	t.Cleanup(func() { goGet = previous })
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/conneroisu/twerge"
+	"github.com/conneroisu/twerge/scan"
+)
+
+// lintFinding is a problem reported by twerge lint
+type lintFinding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Kind is duplicate, ordering or unknown
+	Kind    string `json:"kind"`
+	Classes string `json:"classes"`
+	Message string `json:"message"`
+}
+
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Directory holding the templates")
+	format := flags.String("format", "text", "Output format, text or json")
+	unknown := flags.Bool("unknown", true, "Report classes that are not Tailwind utilities or colors")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+	return lint(os.Stdout, *dir, *format, *unknown)
+}
+
+// lint reports the problems of the class strings below dir to w in format,
+// and returns an error if there are any, so CI can fail on them.
+func lint(w io.Writer, dir, format string, unknown bool) error {
+	cfg, err := loadConfig(filepath.Join(dir, configFileName))
+	if err != nil {
+		cfg = defaultConfig()
+	}
+	version, err := selectTailwindVersion(dir, cfg)
+	if err != nil {
+		return err
+	}
+	occurrences, err := scan.Dir(dir)
+	if err != nil {
+		return err
+	}
+
+	conf := twerge.DefaultConfig()
+	if version != twerge.TailwindUnknown {
+		conf.TailwindVersion = version
+	}
+	err = applyTheme(dir, cfg, conf)
+	if err != nil {
+		return err
+	}
+	findings := lintOccurrences(twerge.New(conf), occurrences, unknown)
+
+	if format == "json" {
+		if findings == nil {
+			findings = []lintFinding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Fprintf(w, "%s:%d: %s: %s\n", f.File, f.Line, f.Kind, f.Message)
+		}
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("%d problems found", len(findings))
+	}
+	return nil
+}
+
+// lintOccurrences returns the findings of occurrences, ordered by file and
+// line:
+//   - class strings with the same classes as another one in a different order
+//   - class strings merging to the same classes as another one
+//   - classes that are likely typos, see twerge.Validate, if unknown is true
+func lintOccurrences(m *twerge.Merger, occurrences []scan.Occurrence, unknown bool) []lintFinding {
+	// the first occurrence of every class string and class
+	first := make(map[string]scan.Occurrence)
+	var classStrings []string
+	firstClass := make(map[string]scan.Occurrence)
+	var classes []string
+	for _, o := range occurrences {
+		if _, ok := first[o.Classes]; !ok {
+			first[o.Classes] = o
+			classStrings = append(classStrings, o.Classes)
+		}
+		for _, class := range strings.Fields(o.Classes) {
+			if _, ok := firstClass[class]; !ok {
+				firstClass[class] = o
+				classes = append(classes, class)
+			}
+		}
+	}
+
+	var findings []lintFinding
+	report := func(o scan.Occurrence, kind, classes, message string) {
+		findings = append(findings, lintFinding{File: o.File, Line: o.Line, Kind: kind, Classes: classes, Message: message})
+	}
+	at := func(classes string) string {
+		o := first[classes]
+		return fmt.Sprintf("%q (%s:%d)", classes, o.File, o.Line)
+	}
+
+	// class strings are grouped by their sorted classes, and those groups
+	// by their merged classes
+	ordered := make(map[string]string)
+	merged := make(map[string]string)
+	for _, classes := range classStrings {
+		key := sortedFields(classes)
+		if previous, ok := ordered[key]; ok {
+			report(first[classes], "ordering", classes, "same classes as "+at(previous)+" in a different order")
+			continue
+		}
+		ordered[key] = classes
+
+		value := sortedFields(m.Merge(classes))
+		if previous, ok := merged[value]; ok {
+			report(first[classes], "duplicate", classes, "merges to the same classes as "+at(previous))
+			continue
+		}
+		merged[value] = classes
+	}
+
+	if unknown {
+		for _, class := range classes {
+			for _, warning := range twerge.Validate(class) {
+				o := firstClass[class]
+				report(o, "unknown", o.Classes, warning.Message)
+			}
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b lintFinding) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return findings
+}
+
+// sortedFields returns the classes of s sorted and joined by single spaces
+func sortedFields(s string) string {
+	fields := strings.Fields(s)
+	slices.Sort(fields)
+	return strings.Join(fields, " ")
+}
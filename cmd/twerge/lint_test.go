@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/twerge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n",
+		"views/page.templ": "package views\n\ntempl Page() {\n" +
+			"\t<div class=\"flex p-4\"></div>\n" +
+			"\t<div class=\"p-4 flex\"></div>\n" +
+			"\t<div class=\"p-2 p-4 flex\"></div>\n" +
+			"\t<p class=\"flexx text-red-5000\"></p>\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	page := filepath.Join(dir, "views", "page.templ")
+
+	var out strings.Builder
+	assert.EqualError(t, lint(&out, dir, "text", true), "4 problems found")
+	assert.Equal(t,
+		page+`:5: ordering: same classes as "flex p-4" (`+page+`:4) in a different order`+"\n"+
+			page+`:6: duplicate: merges to the same classes as "flex p-4" (`+page+`:4)`+"\n"+
+			page+`:7: unknown: unknown utility "flexx"`+"\n"+
+			page+`:7: unknown: unknown color "red-5000"`+"\n",
+		out.String(),
+	)
+
+	out.Reset()
+	assert.EqualError(t, lint(&out, dir, "json", false), "2 problems found")
+	var findings []lintFinding
+	assert.NoError(t, json.Unmarshal([]byte(out.String()), &findings))
+	assert.Len(t, findings, 2)
+	assert.Equal(t, lintFinding{File: page, Line: 6, Kind: "duplicate", Classes: "p-2 p-4 flex",
+		Message: `merges to the same classes as "flex p-4" (` + page + `:4)`}, findings[1])
+
+	assert.NoError(t, os.WriteFile(page, []byte("package views\n\ntempl Page() {\n\t<div class=\"flex\"></div>\n}\n"), 0644))
+	out.Reset()
+	assert.NoError(t, lint(&out, dir, "json", true))
+	assert.Equal(t, "[]\n", out.String())
+
+	assert.ErrorIs(t, run([]string{"lint", "-format", "xml"}), errUsage)
+}
+
+func TestLintTheme(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { twerge.SetConfig(twerge.DefaultConfig()) })
+	files := map[string]string{
+		"go.mod":             "module example.com/app\n",
+		"tailwind.config.js": "module.exports = {\n  theme: { extend: { colors: { brand: { 500: '#0a84ff' } } } },\n}\n",
+		"views/page.templ":   "package views\n\ntempl Page() {\n\t<div class=\"bg-brand-500 text-brand-600\"></div>\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	var out strings.Builder
+	assert.EqualError(t, lint(&out, dir, "text", true), "1 problems found")
+	assert.Contains(t, out.String(), `unknown color "brand-600"`)
+}
@@ -0,0 +1,94 @@
+// Package main is the twerge command line tool.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+// command is a twerge subcommand
+type command struct {
+	// usage is a one line description of the command
+	usage string
+	// run runs the command with the arguments following its name
+	run func(args []string) error
+}
+
+// commands are the available subcommands keyed by name
+var commands = map[string]command{
+	"doctor": {
+		usage: "check the twerge setup of the current module",
+		run:   runDoctor,
+	},
+	"gen": {
+		usage: "generate the class map and input CSS from the templates",
+		run:   runGen,
+	},
+	"init": {
+		usage: "scaffold twerge in the current module",
+		run:   runInit,
+	},
+	"lint": {
+		usage: "report duplicate, reordered and unknown classes",
+		run:   runLint,
+	},
+	"serve": {
+		usage: "answer editors with merged classes, class names and lint findings over JSON-RPC",
+		run:   runServe,
+	},
+	"stats": {
+		usage: "print the most used class strings and the bytes their names save",
+		run:   runStats,
+	},
+	"suggest": {
+		usage: "rank class strings worth registering",
+		run:   runSuggest,
+	},
+	"templ": {
+		usage: "scan classes, run templ generate and update the input CSS",
+		run:   runTempl,
+	},
+}
+
+// errUsage is returned when the command line is invalid
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	log.SetFlags(0)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			printUsage()
+			os.Exit(2)
+		}
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: twerge <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
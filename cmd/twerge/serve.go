@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/conneroisu/twerge"
+	"github.com/conneroisu/twerge/scan"
+)
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Directory holding "+configFileName+" and the generated class map")
+	lsp := flags.Bool("lsp", false, "Frame messages with Content-Length headers like the Language Server Protocol, instead of one per line")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	s, err := newServer(*dir)
+	if err != nil {
+		return err
+	}
+	return s.serve(os.Stdin, os.Stdout, *lsp)
+}
+
+// server answers the JSON-RPC requests of editors with merged classes,
+// class names and lint findings of class strings
+type server struct {
+	dir string
+	cfg config
+	m   *twerge.Merger
+	// generated is the class map of the generated Go files
+	generated map[string]string
+	// documents maps the URIs of the open documents to their text
+	documents map[string]string
+
+	out  io.Writer
+	lsp  bool
+	exit bool
+}
+
+// newServer returns a server for the twerge.yaml of dir, if any.
+func newServer(dir string) (*server, error) {
+	cfg, err := loadConfig(filepath.Join(dir, configFileName))
+	if err != nil {
+		cfg = defaultConfig()
+	}
+	conf, err := mergeConfig(dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Validate and ClassName use the settings of Merge
+	twerge.SetConfig(conf)
+	s := &server{
+		dir:       dir,
+		cfg:       cfg,
+		m:         twerge.New(conf),
+		documents: make(map[string]string),
+	}
+	s.reload()
+	return s, nil
+}
+
+// reload reads the class map of the generated Go files again.
+func (s *server) reload() {
+	_, s.generated, _ = readGenerated(s.dir, s.cfg)
+}
+
+// rpcMessage is a JSON-RPC 2.0 request, response or notification
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// serve answers the messages read from r on w until the exit notification or
+// the end of r. With lsp, messages are framed by Content-Length headers,
+// otherwise they are separated by newlines.
+func (s *server) serve(r io.Reader, w io.Writer, lsp bool) error {
+	s.out, s.lsp, s.exit = w, lsp, false
+	br := bufio.NewReader(r)
+	for !s.exit {
+		body, err := s.read(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			null := json.RawMessage("null")
+			err = s.write(rpcMessage{ID: &null, Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read returns the body of the next message of r.
+func (s *server) read(r *bufio.Reader) ([]byte, error) {
+	if !s.lsp {
+		for {
+			line, err := r.ReadBytes('\n')
+			if len(strings.TrimSpace(string(line))) > 0 {
+				return line, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid header %q", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// write writes msg to the output of s.
+func (s *server) write(msg rpcMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if s.lsp {
+		_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	} else {
+		_, err = fmt.Fprintf(s.out, "%s\n", body)
+	}
+	return err
+}
+
+// notify sends a notification of method with params.
+func (s *server) notify(method string, params any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(rpcMessage{Method: method, Params: body})
+}
+
+// handle answers msg, if it is a request.
+func (s *server) handle(msg rpcMessage) error {
+	result, rpcErr, err := s.call(msg)
+	if err != nil || msg.ID == nil {
+		return err
+	}
+	reply := rpcMessage{ID: msg.ID, Error: rpcErr}
+	if rpcErr == nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		reply.Result = body
+	}
+	return s.write(reply)
+}
+
+// The parameters and results of the supported methods
+type (
+	textDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	}
+	position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+	lspRange struct {
+		Start position `json:"start"`
+		End   position `json:"end"`
+	}
+	diagnostic struct {
+		Range    lspRange `json:"range"`
+		Severity int      `json:"severity"`
+		Source   string   `json:"source"`
+		Code     string   `json:"code"`
+		Message  string   `json:"message"`
+	}
+	// mergeResult is the result of twerge/merge
+	mergeResult struct {
+		Merged string `json:"merged"`
+		// ClassName is the name in the generated class map, or the
+		// predicted name with hashed or readable naming, else empty
+		ClassName string `json:"class_name,omitempty"`
+		// Generated reports whether ClassName is in the generated class map
+		Generated bool     `json:"generated"`
+		Warnings  []string `json:"warnings,omitempty"`
+	}
+)
+
+// call runs the method of msg and returns its result or its error. Errors
+// writing notifications are returned as err.
+func (s *server) call(msg rpcMessage) (result any, rpcErr *rpcError, err error) {
+	var params struct {
+		TextDocument   textDocument   `json:"textDocument"`
+		ContentChanges []textDocument `json:"contentChanges"`
+		Position       position       `json:"position"`
+		Classes        string         `json:"classes"`
+		URI            string         `json:"uri"`
+		Text           string         `json:"text"`
+	}
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}, nil
+		}
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				// full text on open and change, and a notification on save
+				"textDocumentSync": map[string]any{"openClose": true, "change": 1, "save": true},
+				"hoverProvider":    true,
+			},
+			"serverInfo": map[string]string{"name": "twerge"},
+		}, nil, nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil, nil
+	case "shutdown":
+		return nil, nil, nil
+	case "exit":
+		s.exit = true
+		return nil, nil, nil
+	case "textDocument/didOpen":
+		s.documents[uri] = params.TextDocument.Text
+		return nil, nil, s.publishDiagnostics(uri)
+	case "textDocument/didChange":
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[uri] = params.ContentChanges[n-1].Text
+		}
+		return nil, nil, s.publishDiagnostics(uri)
+	case "textDocument/didSave":
+		s.reload()
+		return nil, nil, nil
+	case "textDocument/didClose":
+		delete(s.documents, uri)
+		return nil, nil, nil
+	case "textDocument/hover":
+		return s.hover(uri, params.Position), nil, nil
+	case "twerge/merge":
+		return s.merge(params.Classes), nil, nil
+	case "twerge/lint":
+		findings, err := s.lint(params.URI, params.Text)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}, nil
+		}
+		if findings == nil {
+			findings = []lintFinding{}
+		}
+		return findings, nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + msg.Method}, nil
+}
+
+// merge returns the merged classes, the class name and the warnings of
+// classes.
+func (s *server) merge(classes string) mergeResult {
+	result := mergeResult{Merged: s.m.Merge(classes)}
+	if className, ok := s.generated[classes]; ok {
+		result.ClassName, result.Generated = className, true
+	} else if className, ok := twerge.ClassName(result.Merged); ok {
+		result.ClassName = className
+	}
+	for _, w := range twerge.Validate(classes) {
+		result.Warnings = append(result.Warnings, w.String())
+	}
+	return result
+}
+
+// occurrences returns the class strings of the document at uri with text.
+func occurrences(uri, text string) ([]scan.Occurrence, error) {
+	path := uri
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		path = u.Path
+	}
+	switch filepath.Ext(path) {
+	case ".templ":
+		return scan.Templ(path, []byte(text))
+	case ".go":
+		return scan.Go(path, []byte(text)), nil
+	case ".html":
+		return scan.HTML(path, []byte(text)), nil
+	}
+	return nil, fmt.Errorf("unsupported document %s", uri)
+}
+
+// lint returns the lint findings of the document at uri with text.
+func (s *server) lint(uri, text string) ([]lintFinding, error) {
+	found, err := occurrences(uri, text)
+	if err != nil {
+		return nil, err
+	}
+	return lintOccurrences(s.m, found, true), nil
+}
+
+// publishDiagnostics sends the lint findings of the open document at uri,
+// if it is supported.
+func (s *server) publishDiagnostics(uri string) error {
+	text := s.documents[uri]
+	findings, err := s.lint(uri, text)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	diagnostics := []diagnostic{}
+	for _, f := range findings {
+		r := classRange(lines, f.Line, f.Classes)
+		diagnostics = append(diagnostics, diagnostic{Range: r, Severity: 2, Source: "twerge", Code: f.Kind, Message: f.Message})
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": diagnostics})
+}
+
+// classRange returns the range of classes in the 1-based line of lines, the
+// whole line if it is not found there.
+func classRange(lines []string, line int, classes string) lspRange {
+	r := lspRange{Start: position{Line: line - 1}, End: position{Line: line - 1}}
+	if line < 1 || line > len(lines) {
+		return r
+	}
+	if i := strings.Index(lines[line-1], classes); i >= 0 {
+		r.Start.Character, r.End.Character = i, i+len(classes)
+	} else {
+		r.End.Character = len(lines[line-1])
+	}
+	return r
+}
+
+// hover returns the merged classes and the class name of the class string at
+// pos of the open document at uri, or nil if there is none.
+func (s *server) hover(uri string, pos position) any {
+	text := s.documents[uri]
+	found, err := occurrences(uri, text)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for _, o := range found {
+		r := classRange(lines, o.Line, o.Classes)
+		if r.Start.Line != pos.Line || pos.Character < r.Start.Character || pos.Character > r.End.Character {
+			continue
+		}
+		result := s.merge(o.Classes)
+		value := "`" + result.Merged + "`"
+		if result.ClassName != "" {
+			value = "**" + result.ClassName + "**: " + value
+		}
+		for _, w := range result.Warnings {
+			value += "\n\n" + w
+		}
+		return map[string]any{
+			"contents": map[string]string{"kind": "markdown", "value": value},
+			"range":    r,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/twerge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { twerge.SetConfig(twerge.DefaultConfig()) })
+	files := map[string]string{
+		"go.mod":       "module example.com/app\n",
+		configFileName: "naming: xxhash\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	s, err := newServer(dir)
+	assert.NoError(t, err)
+
+	page := "package views\n\ntempl Page() {\n\t<div class=\"flex p-4\"></div>\n\t<div class=\"p-4 flex\"></div>\n}\n"
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"twerge/merge","params":{"classes":"p-2 p-4 flexx"}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///app/views/page.templ","text":%q}}}`, page),
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///app/views/page.templ"},"position":{"line":3,"character":15}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///app/views/page.templ"},"position":{"line":0,"character":0}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"twerge/unknown"}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":6,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"shutdown"}`,
+	}
+	var out strings.Builder
+	assert.NoError(t, s.serve(strings.NewReader(strings.Join(requests, "\n")), &out, false))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 8)
+	assert.Contains(t, lines[0], `"hoverProvider":true`)
+	var merged struct {
+		Result mergeResult
+	}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &merged))
+	assert.Equal(t, "p-4 flexx", merged.Result.Merged)
+	assert.Equal(t, className(t, "p-4 flexx"), merged.Result.ClassName)
+	assert.False(t, merged.Result.Generated)
+	assert.Equal(t, []string{`flexx: unknown utility "flexx"`}, merged.Result.Warnings)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"file:///app/views/page.templ","diagnostics":[
+		{"range":{"start":{"line":4,"character":13},"end":{"line":4,"character":21}},"severity":2,"source":"twerge","code":"ordering",
+		"message":"same classes as \"flex p-4\" (/app/views/page.templ:4) in a different order"}]}}`, lines[2])
+	var hover struct {
+		Result struct {
+			Contents struct{ Value string }
+		}
+	}
+	assert.NoError(t, json.Unmarshal([]byte(lines[3]), &hover))
+	assert.Equal(t, "**"+className(t, "flex p-4")+"**: `flex p-4`", hover.Result.Contents.Value)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":4,"result":null}`, lines[4])
+	assert.Contains(t, lines[5], `"code":-32601`)
+	assert.Contains(t, lines[6], `"code":-32700`)
+	// requests after exit are not answered
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":6,"result":null}`, lines[7])
+
+	// with lsp, messages are framed by Content-Length headers
+	body := `{"jsonrpc":"2.0","id":1,"method":"twerge/merge","params":{"classes":"p-2 p-4"}}`
+	out.Reset()
+	assert.NoError(t, s.serve(strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)), &out, true))
+	reply := `{"jsonrpc":"2.0","id":1,"result":{"merged":"p-4","class_name":"` + className(t, "p-4") + `","generated":false}}`
+	assert.Equal(t, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(reply), reply), out.String())
+}
+
+// className returns the predicted class name of merged.
+func className(t *testing.T, merged string) string {
+	t.Helper()
+	className, ok := twerge.ClassName(merged)
+	assert.True(t, ok)
+	return className
+}
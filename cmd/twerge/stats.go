@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/conneroisu/twerge"
+)
+
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Directory holding the .templ files")
+	url := flags.String("url", "", "URL of a twerge.UsageHandler of a running application, read instead of the templates")
+	top := flags.Int("top", 20, "Number of class strings to print, 0 for all")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	var usage []twerge.Usage
+	var err error
+	if *url != "" {
+		usage, err = fetchUsage(*url)
+	} else {
+		usage, err = templateUsage(*dir)
+	}
+	if err != nil {
+		return err
+	}
+	return writeUsageTable(os.Stdout, usage, *top)
+}
+
+// fetchUsage reads the usage report served by twerge.UsageHandler at url
+func fetchUsage(url string) ([]twerge.Usage, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching %s: %s", url, resp.Status)
+	}
+	var usage []twerge.Usage
+	err = json.NewDecoder(resp.Body).Decode(&usage)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", url, err)
+	}
+	return usage, nil
+}
+
+// templateUsage counts the uses of the class strings in the .templ files
+// below dir, named as twerge gen would with the twerge.yaml of dir, if any.
+func templateUsage(dir string) ([]twerge.Usage, error) {
+	cfg, err := loadConfig(filepath.Join(dir, configFileName))
+	if err != nil {
+		cfg = defaultConfig()
+	}
+	conf, err := mergeConfig(dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := twerge.CountClassUsage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// names are generated in the sorted order of the class strings, like
+	// twerge gen does
+	m := twerge.New(conf)
+	var usage []twerge.Usage
+	for _, classes := range slices.Sorted(maps.Keys(counts)) {
+		className := m.Generate(classes)
+		count := counts[classes]
+		usage = append(usage, twerge.Usage{
+			Classes:    classes,
+			ClassName:  className,
+			Count:      uint64(count),
+			SavedBytes: int64(count * (len(classes) - len(className))),
+		})
+	}
+	twerge.SortUsage(usage)
+	return usage, nil
+}
+
+// writeUsageTable prints the top most used class strings and the totals of
+// all of usage
+func writeUsageTable(w io.Writer, usage []twerge.Usage, top int) error {
+	if len(usage) == 0 {
+		_, err := fmt.Fprintln(w, "No class strings used.")
+		return err
+	}
+	classStrings := len(usage)
+	var uses uint64
+	var saved int64
+	for _, u := range usage {
+		uses += u.Count
+		saved += u.SavedBytes
+	}
+	if top > 0 && len(usage) > top {
+		usage = usage[:top]
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tUSES\tSAVED\tNAME\tCLASSES")
+	for i, u := range usage {
+		fmt.Fprintf(tw, "%d\t%d\t%dB\t%s\t%q\n", i+1, u.Count, u.SavedBytes, u.ClassName, u.Classes)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d uses of %d class strings, %dB saved by class names\n", uses, classStrings, saved)
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/twerge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { twerge.SetConfig(twerge.DefaultConfig()) })
+	files := map[string]string{
+		"go.mod": "module example.com/app\n",
+		"views/page.templ": "package views\n\ntempl Page() {\n" +
+			"\t<div class=\"flex items-center justify-between\"></div>\n" +
+			"\t<div class=\"flex items-center justify-between\"></div>\n" +
+			"\t<p class=\"p-4\"></p>\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	usage, err := templateUsage(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []twerge.Usage{
+		{Classes: "flex items-center justify-between", ClassName: "tw-0", Count: 2, SavedBytes: 58},
+		{Classes: "p-4", ClassName: "tw-1", Count: 1, SavedBytes: -1},
+	}, usage)
+
+	var out strings.Builder
+	assert.NoError(t, writeUsageTable(&out, usage, 1))
+	assert.Equal(t,
+		"RANK  USES  SAVED  NAME  CLASSES\n"+
+			"1     2     58B    tw-0  \"flex items-center justify-between\"\n"+
+			"\n3 uses of 2 class strings, 57B saved by class names\n",
+		out.String(),
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"classes":"p-4 flex","class_name":"tw-a","count":4,"saved_bytes":20}]`))
+	}))
+	defer server.Close()
+	usage, err = fetchUsage(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []twerge.Usage{{Classes: "p-4 flex", ClassName: "tw-a", Count: 4, SavedBytes: 20}}, usage)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/conneroisu/twerge"
+	"github.com/dave/jennifer/jen"
+)
+
+func runSuggest(args []string) error {
+	flags := flag.NewFlagSet("suggest", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Directory holding the .templ files")
+	limit := flags.Int("limit", 20, "Maximum number of suggestions, 0 for all")
+	minCount := flags.Int("min", 2, "Minimum number of uses of a suggestion")
+	goSnippet := flags.Bool("go", false, "Print a Go snippet registering the suggestions")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	usage, err := twerge.CountClassUsage(*dir)
+	if err != nil {
+		return err
+	}
+
+	var suggestions []twerge.Suggestion
+	for _, s := range twerge.Suggest(usage) {
+		if s.Count < *minCount {
+			continue
+		}
+		suggestions = append(suggestions, s)
+		if *limit > 0 && len(suggestions) == *limit {
+			break
+		}
+	}
+
+	if *goSnippet {
+		return writeSuggestionSnippet(os.Stdout, suggestions)
+	}
+	return writeSuggestionTable(os.Stdout, suggestions)
+}
+
+// writeSuggestionTable prints the suggestions ranked by saved bytes
+func writeSuggestionTable(w io.Writer, suggestions []twerge.Suggestion) error {
+	if len(suggestions) == 0 {
+		_, err := fmt.Fprintln(w, "No class strings worth registering found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tUSES\tSAVED\tNAME\tCLASSES")
+	for i, s := range suggestions {
+		fmt.Fprintf(tw, "%d\t%d\t%dB\t%s\t%q\n", i+1, s.Count, s.SavedBytes, s.Name, s.Classes)
+		for _, variant := range s.Variants {
+			fmt.Fprintf(tw, "\t\t\t\t%q\n", variant)
+		}
+	}
+	return tw.Flush()
+}
+
+// writeSuggestionSnippet prints a Go statement registering the suggestions
+func writeSuggestionSnippet(w io.Writer, suggestions []twerge.Suggestion) error {
+	stmt := jen.Qual("github.com/conneroisu/twerge", "RegisterClasses").Call(
+		jen.Map(jen.String()).String().Values(jen.DictFunc(func(d jen.Dict) {
+			for _, s := range suggestions {
+				d[jen.Lit(s.Classes)] = jen.Lit(s.Name)
+				for _, variant := range s.Variants {
+					d[jen.Lit(variant)] = jen.Lit(s.Name)
+				}
+			}
+		})),
+	)
+	_, err := fmt.Fprintf(w, "%#v\n", stmt)
+	return err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/conneroisu/twerge"
+)
+
+func runTempl(args []string) error {
+	flags := flag.NewFlagSet("templ", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "Directory holding the .templ files")
+	cssPath := flags.String("css", "", "Tailwind input CSS to update (defaults to input_css of "+configFileName+")")
+	templBin := flags.String("templ", "templ", "Path to the templ binary")
+	skipTempl := flags.Bool("skip-templ", false, "Only run the twerge steps, not templ generate")
+	patch := flags.Bool("patch", false, "Only add new and remove pruned rules instead of rewriting the twerge section")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	css := *cssPath
+	cfg, err := loadConfig(filepath.Join(*dir, configFileName))
+	if css == "" && err == nil {
+		css = filepath.Join(*dir, cfg.InputCSS)
+	}
+	if _, err := selectTailwindVersion(*dir, cfg); err != nil {
+		return err
+	}
+
+	if err := twerge.RunBeforeTemplGenerate(*dir); err != nil {
+		return err
+	}
+	fmt.Println("wrote", filepath.Join(*dir, twerge.TemplGenFileName))
+
+	if !*skipTempl {
+		cmd := exec.Command(*templBin, "generate", "-path", *dir)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("error running templ generate: %w", err)
+		}
+	}
+
+	if css != "" {
+		update := twerge.RunAfterTemplGenerate
+		if *patch {
+			update = func(cssPath string) error { return twerge.PatchTailwind(cssPath) }
+		}
+		if err := update(css); err != nil {
+			return err
+		}
+		fmt.Println("updated", css)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package twerge
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// collectorKey is the context key holding the Collector of a render
+type collectorKey struct{}
+
+// Collector records the class names generated with ItContext during a
+// render, so that a page can inline exactly the rules it uses:
+//
+//	ctx, collector := twerge.WithCollector(r.Context())
+//	err := page().Render(ctx, &body)
+//	css := collector.CSS()
+//
+// A Collector is safe for concurrent use.
+type Collector struct {
+	mu         sync.Mutex
+	classNames map[string]bool
+}
+
+// WithCollector returns a copy of ctx carrying a new Collector, and the
+// Collector.
+func WithCollector(ctx context.Context) (context.Context, *Collector) {
+	c := &Collector{classNames: make(map[string]bool)}
+	return context.WithValue(ctx, collectorKey{}, c), c
+}
+
+// CollectorFromContext returns the Collector stored in ctx by WithCollector.
+func CollectorFromContext(ctx context.Context) (*Collector, bool) {
+	c, ok := ctx.Value(collectorKey{}).(*Collector)
+	return c, ok
+}
+
+// Add records the generated class name.
+func (c *Collector) Add(className string) {
+	c.mu.Lock()
+	c.classNames[className] = true
+	c.mu.Unlock()
+}
+
+// ClassNames returns the recorded class names, sorted.
+func (c *Collector) ClassNames() []string {
+	c.mu.Lock()
+	classNames := make([]string, 0, len(c.classNames))
+	for className := range c.classNames {
+		classNames = append(classNames, className)
+	}
+	c.mu.Unlock()
+	slices.Sort(classNames)
+	return classNames
+}
+
+// WriteCSS writes the @apply rules of the recorded class names to w.
+func (c *Collector) WriteCSS(w io.Writer, opts ...MapOption) error {
+	c.mu.Lock()
+	recorded := make(map[string]bool, len(c.classNames))
+	for className := range c.classNames {
+		recorded[className] = true
+	}
+	c.mu.Unlock()
+
+	return writeGenClasses(w, func(className string) bool {
+		return recorded[className]
+	}, opts)
+}
+
+// CSS returns the @apply rules of the recorded class names.
+func (c *Collector) CSS(opts ...MapOption) string {
+	var builder strings.Builder
+	_ = c.WriteCSS(&builder, opts...)
+	return builder.String()
+}
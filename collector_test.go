@@ -0,0 +1,38 @@
+package twerge
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	SetMapping(map[string]string{"px-2 px-4": "tw-pad", "hidden": "tw-hidden"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	ctx, collector := WithCollector(WithRoute(context.Background(), "/collected"))
+	got, ok := CollectorFromContext(ctx)
+	assert.True(t, ok)
+	assert.Same(t, collector, got)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "tw-pad", ItContext(ctx, "px-2 px-4"))
+		}()
+	}
+	wg.Wait()
+	It("hidden")
+
+	assert.Equal(t, []string{"tw-pad"}, collector.ClassNames())
+	assert.Equal(t, ".tw-pad { \n\t@apply px-4; \n}\n", collector.CSS())
+	// routes are still recorded
+	assert.Equal(t, map[string]string{"tw-pad": "px-4"}, RouteBundles()["/collected"])
+
+	_, ok = CollectorFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,78 @@
+package twerge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CollisionError reports generated class names used for class strings that
+// merge to different classes.
+type CollisionError struct {
+	// Collisions maps each colliding class name to the sorted class strings
+	// using it
+	Collisions map[string][]string
+}
+
+// Error implements the error interface.
+func (e *CollisionError) Error() string {
+	names := make([]string, 0, len(e.Collisions))
+	for name := range e.Collisions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	descriptions := make([]string, len(names))
+	for i, name := range names {
+		descriptions[i] = fmt.Sprintf("%s is used for %q", name, e.Collisions[name])
+	}
+	return "twerge: class name collisions: " + strings.Join(descriptions, "; ")
+}
+
+// VerifyNoCollisions checks that no generated class name in ClassMapStr is
+// used for class strings merging to different classes.
+//
+// Generated names never collide, It skips names that are already taken, but
+// names passed to RegisterClasses are used as given. It returns a
+// *CollisionError listing the colliding names.
+func VerifyNoCollisions() error {
+	mapMutex.RLock()
+	classMap := make(map[string]string, len(ClassMapStr))
+	for original, className := range ClassMapStr {
+		classMap[original] = className
+	}
+	mapMutex.RUnlock()
+
+	return findCollisions(classMap, Merge)
+}
+
+// findCollisions returns a *CollisionError for the class names of classMap
+// used for class strings that merge to different classes, or nil.
+func findCollisions(classMap map[string]string, merge func(string) string) error {
+	merged := make(map[string]map[string]string)
+	for original, className := range classMap {
+		if merged[className] == nil {
+			merged[className] = make(map[string]string)
+		}
+		merged[className][original] = normalizeMerged(merge(original))
+	}
+
+	collisions := make(map[string][]string)
+	for className, originals := range merged {
+		values := make(map[string]bool, len(originals))
+		for _, value := range originals {
+			values[value] = true
+		}
+		if len(values) < 2 {
+			continue
+		}
+		for original := range originals {
+			collisions[className] = append(collisions[className], original)
+		}
+		slices.Sort(collisions[className])
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	return &CollisionError{Collisions: collisions}
+}
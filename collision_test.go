@@ -0,0 +1,43 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyNoCollisions(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	classID = 0
+	mapMutex.Unlock()
+
+	// names frozen by code generation are skipped by It
+	RegisterClasses(map[string]string{"flex": "tw-0", "block": "tw-1"})
+	className := It("inline-flex")
+	assert.Equal(t, "tw-2", className)
+	assert.Equal(t, "inline-flex", GenClassMergeStr[className])
+	assert.Equal(t, "flex", GenClassMergeStr["tw-0"])
+	assert.NoError(t, VerifyNoCollisions())
+
+	// class strings merging to the same classes may share a name
+	RegisterClasses(map[string]string{"block flex": "tw-0"})
+	assert.NoError(t, VerifyNoCollisions())
+
+	RegisterClasses(map[string]string{"grid": "tw-1"})
+	err := VerifyNoCollisions()
+	var collisionErr *CollisionError
+	assert.ErrorAs(t, err, &collisionErr)
+	assert.Equal(t, map[string][]string{"tw-1": {"block", "grid"}}, collisionErr.Collisions)
+	assert.EqualError(t, err, `twerge: class name collisions: tw-1 is used for ["block" "grid"]`)
+
+	m := New(nil)
+	m.RegisterClasses(map[string]string{"p-2": "tw-0"})
+	assert.Equal(t, "tw-1", m.Generate("p-4"))
+	assert.NoError(t, m.VerifyNoCollisions())
+	m.RegisterClasses(map[string]string{"p-8": "tw-0"})
+	assert.Error(t, m.VerifyNoCollisions())
+}
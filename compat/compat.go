@@ -0,0 +1,86 @@
+// Package compat holds the test fixtures of tailwind-merge, the JavaScript
+// library twerge ports, so the parity of a merge function can be verified.
+//
+// The fixtures are class strings with the result tailwind-merge returns for
+// them. Configurations extending the defaults, with plugin groups or class
+// groups of their own, can check that they keep the core behavior:
+//
+//	func TestMergeCompat(t *testing.T) {
+//		merge := twerge.NewMerge(myConfig())
+//		if err := compat.Verify(merge); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+package compat
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// Fixture is a class string merged by tailwind-merge.
+type Fixture struct {
+	// Name is the description of the tailwind-merge test the fixture comes
+	// from
+	Name string `json:"name"`
+	// Input is the class string merged
+	Input string `json:"input"`
+	// Output is the result of tailwind-merge
+	Output string `json:"output"`
+}
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixtures returns the fixtures of all fixture files, in the order of the
+// files and of the fixtures in them.
+func Fixtures() ([]Fixture, error) {
+	files, err := fs.Glob(fixtures, "fixtures/*.json")
+	if err != nil {
+		return nil, err
+	}
+	var all []Fixture
+	for _, file := range files {
+		data, err := fixtures.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var loaded []Fixture
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", file, err)
+		}
+		all = append(all, loaded...)
+	}
+	return all, nil
+}
+
+// Verify merges the input of every fixture with merge, and returns an error
+// listing the fixtures whose result differs from the one of tailwind-merge,
+// including in the order of the classes.
+func Verify(merge func(classes string) string) error {
+	all, err := Fixtures()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range all {
+		if got := merge(f.Input); !Equal(got, f.Output) {
+			errs = append(errs, fmt.Errorf("%s: merging %q returned %q, want %q", f.Name, f.Input, got, f.Output))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d fixtures failed:\n%w", len(errs), len(all), errors.Join(errs...))
+	}
+	return nil
+}
+
+// Equal reports whether the class strings a and b hold the same classes in
+// the same order, regardless of their whitespace.
+func Equal(a, b string) bool {
+	return slices.Equal(strings.Fields(a), strings.Fields(b))
+}
@@ -0,0 +1,36 @@
+package compat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/conneroisu/twerge"
+)
+
+func TestFixtures(t *testing.T) {
+	all, err := Fixtures()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, all)
+	for _, f := range all {
+		assert.NotEmpty(t, f.Name)
+		assert.NotEmpty(t, f.Input)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	assert.NoError(t, Verify(twerge.NewMerge(twerge.DefaultConfig())))
+
+	// a merge function keeping every class fails the fixtures with conflicts
+	err := Verify(func(classes string) string { return classes })
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `merging "z-20 z-[99]" returned "z-20 z-[99]", want "z-[99]"`)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal("p-2 flex", "p-2\tflex "))
+	assert.False(t, Equal("p-2 flex", "flex p-2"))
+	assert.False(t, Equal("p-2 flex", "p-2"))
+	assert.False(t, Equal("p-2 p-2", "p-2"))
+}
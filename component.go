@@ -0,0 +1,119 @@
+package twerge
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// componentParts maps a component to the class names of its parts
+// It is protected by mapMutex for concurrent access
+var componentParts = make(map[string]map[string]string)
+
+// RegisterComponent registers the class strings of the parts of a component
+// and returns the class name of every part.
+//
+// Parts are named like the fields of the struct generated for the component
+// by GenerateComponentStylesCode, and their class names are the class prefix
+// followed by the component and part names, so the "Header" part of "Card" is
+// "tw-card-header" with the default prefix.
+//
+//	styles, err := twerge.RegisterComponent("Card", map[string]string{
+//		"Root":   "rounded-lg border p-4",
+//		"Header": "text-lg font-bold",
+//	})
+//
+// It returns an error, and registers nothing, if a class name is already the
+// name of another part or of other classes, like the parts "Root" of
+// "CardHeader" and "HeaderRoot" of "Card", or if parts have the same class
+// string.
+func RegisterComponent(component string, parts map[string]string) (map[string]string, error) {
+	merged := make(map[string]string, len(parts))
+	for _, original := range parts {
+		merged[original] = Merge(original)
+	}
+
+	mapMutex.Lock()
+	defer mapMutex.Unlock()
+	classes := make(map[string]string, len(parts))
+	names := make(map[string]string, len(parts))
+	for _, part := range SortedKeys(parts) {
+		original := parts[part]
+		className := classNaming.prefix + kebabCase(component) + "-" + kebabCase(part)
+		if other, ok := classes[original]; ok {
+			return nil, fmt.Errorf("parts %s and %s of component %s have the same classes %q", other, className, component, original)
+		}
+		owner := componentOwner(className)
+		if owner != "" && owner != component+"."+part {
+			return nil, fmt.Errorf("class name %s of part %s.%s is already the name of part %s", className, component, part, owner)
+		}
+		if rule, ok := GenClassMergeStr[className]; ok && owner == "" && rule != merged[original] {
+			return nil, fmt.Errorf("class name %s of part %s.%s is already the name of %q", className, component, part, rule)
+		}
+		classes[original] = className
+		names[part] = className
+	}
+
+	registerClasses(ClassMapStr, GenClassMergeStr, classes, merged)
+	mapVersion.Add(1)
+	publishClassMap()
+	if componentParts[component] == nil {
+		componentParts[component] = make(map[string]string, len(names))
+	}
+	maps.Copy(componentParts[component], names)
+	return names, nil
+}
+
+// componentOwner returns the component and part, joined by a dot, a class
+// name was registered for by RegisterComponent, or an empty string.
+//
+// mapMutex must be held.
+func componentOwner(className string) string {
+	for component, parts := range componentParts {
+		for part, name := range parts {
+			if name == className {
+				return component + "." + part
+			}
+		}
+	}
+	return ""
+}
+
+// kebabCase converts a Go style name into a class name part, e.g. "CardHeader"
+// into "card-header".
+func kebabCase(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			builder.WriteRune(r)
+		default:
+			builder.WriteByte('-')
+		}
+	}
+	return strings.Trim(builder.String(), "-")
+}
+
+// exportedIdentifier converts a name into an exported Go identifier, e.g.
+// "card-header" into "CardHeader".
+func exportedIdentifier(name string) string {
+	id := classIdentifier(name)
+	return strings.ToUpper(id[:1]) + id[1:]
+}
+
+// sortedComponentKeys returns the component names in ascending order.
+func sortedComponentKeys(components map[string]map[string]string) []string {
+	keys := make([]string, 0, len(components))
+	for k := range components {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
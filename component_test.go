@@ -0,0 +1,53 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterComponentCollisions(t *testing.T) {
+	resetComponents(t)
+	SetConfig(&Config{TailwindVersion: TailwindV3, Conflicts: DefaultConflictConfig(), ClassPrefix: "ui-"})
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	names, err := RegisterComponent("Text", map[string]string{"Center": "text-center font-bold"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Center": "ui-text-center"}, names)
+
+	_, err = RegisterComponent("CardHeader", map[string]string{"Root": "p-4"})
+	assert.NoError(t, err)
+	_, err = RegisterComponent("Card", map[string]string{"HeaderRoot": "p-2"})
+	assert.ErrorContains(t, err, "CardHeader.Root")
+
+	_, err = RegisterComponent("Card", map[string]string{"Root": "p-2", "Body": "p-2"})
+	assert.ErrorContains(t, err, "same classes")
+	assert.Equal(t, map[string]string{"Root": "ui-card-header-root"}, TakeSnapshot().Components["CardHeader"])
+	assert.NotContains(t, TakeSnapshot().Components, "Card")
+
+	// registering a component again replaces the classes of its parts
+	names, err = RegisterComponent("CardHeader", map[string]string{"Root": "p-6"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ui-card-header-root", names["Root"])
+	assert.Equal(t, "p-6", TakeSnapshot().Rules["ui-card-header-root"])
+}
+
+// resetComponents clears the class maps and registered components for the
+// test.
+func resetComponents(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		SetMapping(nil)
+		mapMutex.Lock()
+		componentParts = make(map[string]map[string]string)
+		mapMutex.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestKebabCase(t *testing.T) {
+	assert.Equal(t, "card-header", kebabCase("CardHeader"))
+	assert.Equal(t, "nav-bar", kebabCase("nav_bar"))
+	assert.Equal(t, "root", kebabCase("Root"))
+}
@@ -0,0 +1,81 @@
+package twerge
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxComposedNames is the number of class names a function of With caches
+// before its cache is cleared
+const maxComposedNames = 1024
+
+// composer caches the class names of a function returned by With
+type composer struct {
+	base string
+
+	mu sync.Mutex
+	// version is the mapVersion names were cached at
+	version uint64
+	// names maps the extra classes to their class name
+	names map[string]string
+}
+
+// With returns a function merging the classes passed to it onto the base
+// classes and returning their class name, like It, for components accepting
+// a class prop overriding their defaults:
+//
+//	var card = twerge.With("rounded-lg border p-4")
+//
+//	templ Card(class string) {
+//		<div class={ card(class) }>{ children... }</div>
+//	}
+//
+// card("p-8 shadow") is the class name of "rounded-lg border p-8 shadow".
+// The class names are cached for every extra class string, so rendering a
+// component again does not join and look up the classes again. The cache is
+// cleared whenever a class name is generated or registered.
+func With(base string) func(extra string) string {
+	c := &composer{base: strings.Join(strings.Fields(base), " ")}
+	return c.className
+}
+
+// className returns the class name of the base classes of c merged with extra.
+func (c *composer) className(extra string) string {
+	version := mapVersion.Load()
+	c.mu.Lock()
+	if c.version == version {
+		if className, ok := c.names[extra]; ok {
+			c.mu.Unlock()
+			return className
+		}
+	}
+	c.mu.Unlock()
+
+	className := It(c.classes(extra))
+	// a class name generated or registered meanwhile may replace it, so it
+	// is cached once the class map did not change during It
+	if mapVersion.Load() != version {
+		return className
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version || len(c.names) >= maxComposedNames {
+		c.version = version
+		c.names = make(map[string]string)
+	}
+	c.names[extra] = className
+	return className
+}
+
+// classes returns the base classes of c followed by extra.
+func (c *composer) classes(extra string) string {
+	extra = strings.TrimSpace(extra)
+	switch {
+	case extra == "":
+		return c.base
+	case c.base == "":
+		return extra
+	}
+	return c.base + " " + extra
+}
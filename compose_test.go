@@ -0,0 +1,26 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWith(t *testing.T) {
+	card := With(" rounded-lg  border p-4 ")
+	assert.Equal(t, It("rounded-lg border p-4"), card(""))
+
+	className := card("p-8 shadow")
+	assert.Equal(t, It("rounded-lg border p-4 p-8 shadow"), className)
+	mapMutex.RLock()
+	assert.Equal(t, "rounded-lg border p-8 shadow", GenClassMergeStr[className])
+	mapMutex.RUnlock()
+	assert.Equal(t, className, card("p-8 shadow"))
+
+	// a registered name replaces the cached one
+	RegisterClasses(map[string]string{"rounded-lg border p-4 p-8 shadow": "tw-card-large"})
+	assert.Equal(t, "tw-card-large", card("p-8 shadow"))
+	assert.Equal(t, "tw-card-large", card(" p-8 shadow"))
+}
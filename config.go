@@ -2,7 +2,7 @@ package twerge
 
 import (
 	"regexp"
-	"strconv"
+	"strings"
 )
 
 var (
@@ -12,10 +12,44 @@ var (
 		"screen": true,
 	}
 	lengthUnitRegex = regexp.MustCompile(`\d+(%|px|r?em|[sdl]?v([hwib]|min|max)|pt|pc|in|cm|mm|cap|ch|ex|r?lh|cq(w|h|i|b|min|max))|\b(calc|min|max|clamp)\(.+\)|^0$`)
-	colorFnRegex    = regexp.MustCompile(`^(rgba?|hsla?|hwb|(ok)?(lab|lch))\(.+\)$`)
+	colorFnRegex    = regexp.MustCompile(`^(rgba?|hsla?|hwb|(ok)?(lab|lch)|color(-mix)?|light-dark)\(.+\)$`)
 	arbitraryRegex  = regexp.MustCompile(`(?i)^\[(?:([a-z-]+):)?(.+)\]$`)
-	shirtPattern    = regexp.MustCompile(`^(\d+(\.\d+)?)?(xs|sm|md|lg|xl)$`)
-	shardowPattern  = regexp.MustCompile(`^(inset_)?-?((\d+)?\.?(\d+)[a-z]+|0)_-?((\d+)?\.?(\d+)[a-z]+|0)`)
+	// v4 shorthand for var() values -> bg-(--brand), text-(length:--size)
+	arbitraryVariableRegex = regexp.MustCompile(`(?i)^\((?:([a-z-]+):)?(--[\w-]+)\)$`)
+	shardowPattern         = regexp.MustCompile(`^(inset_)?-?((\d+)?\.?(\d+)[a-z]+|0)_-?((\d+)?\.?(\d+)[a-z]+|0)`)
+	imageRegex             = regexp.MustCompile(`^(url|image|image-set|cross-fade|element|(repeating-)?(linear|radial|conic)-gradient)\(.+\)$`)
+
+	fontStretches = map[string]bool{
+		"ultra-condensed": true,
+		"extra-condensed": true,
+		"condensed":       true,
+		"semi-condensed":  true,
+		"normal":          true,
+		"semi-expanded":   true,
+		"expanded":        true,
+		"extra-expanded":  true,
+		"ultra-expanded":  true,
+	}
+
+	perspectives = map[string]bool{
+		"none":     true,
+		"dramatic": true,
+		"near":     true,
+		"normal":   true,
+		"midrange": true,
+		"distant":  true,
+	}
+	origins = map[string]bool{
+		"center":       true,
+		"top":          true,
+		"top-right":    true,
+		"right":        true,
+		"bottom-right": true,
+		"bottom":       true,
+		"bottom-left":  true,
+		"left":         true,
+		"top-left":     true,
+	}
 
 	sizeLabels  = map[string]bool{"length": true, "size": true, "percentage": true}
 	imageLabels = map[string]bool{"image": true, "url": true}
@@ -36,11 +70,29 @@ type config struct {
 	Prefix string
 	// CACHE
 	MaxCacheSize int
+	// notified of the cache hits, misses and evictions, optional
+	CacheMetrics CacheMetrics
 	// This is a large map of all the classes and their validators -> see default-config.go
 	ClassGroups classPart
 	// class group with conflict + conflicting groups -> if "p" is set all others are removed
 	// p: ['px', 'py', 'ps', 'pe', 'pt', 'pr', 'pb', 'pl']
 	ConflictingClassGroups conflictingClassGroups
+	// groups of plugin classes not in ClassGroups -> see SetPluginGroups
+	PluginGroups []PluginGroup
+	// class groups accepting negative values -> -mt-4, -translate-x-1/2
+	// nil accepts negative values for every class group
+	NegativeClassGroups map[string]bool
+	// how dark: is written as plain CSS -> see DarkMode
+	DarkMode     DarkMode
+	DarkSelector string
+	// selector templates or media queries of custom variants -> see Config.Variants
+	Variants map[string]string
+	// media queries of the breakpoints and other media variants, in the
+	// order their rules are written, the default breakpoints if nil -> see Config.Screens
+	MediaVariants []mediaVariant
+	// classes kept as is and classes removed -> see Config.Safelist
+	Safelist  *classPatterns
+	Blocklist *classPatterns
 }
 
 // classGroupValidator is a validator for a class group
@@ -120,7 +172,7 @@ func isArbitraryLength(val string) bool {
 
 // isArbitraryNumber returns true if the given value is an arbitrary number
 func isArbitraryNumber(val string) bool {
-	return labelIsArbitraryValue(val, "number", isNumber)
+	return labelIsArbitraryValue(val, "number", isSignedNumber)
 }
 
 // isArbitraryPosition returns true if the given value is an arbitrary position
@@ -137,19 +189,56 @@ func isArbitraryImage(val string) bool {
 	return labelIsArbitraryValue(val, imageLabels, isImage)
 }
 func isArbitraryShadow(val string) bool {
+	if res := arbitraryVariableRegex.FindStringSubmatch(val); res != nil {
+		// shadows are the only type matching unlabeled variables
+		return res[1] == "" || res[1] == "shadow"
+	}
 	return labelIsArbitraryValue(val, "", isShadow)
 }
 
+// isArbitraryValue returns true if the given value is an arbitrary value or
+// a CSS variable shorthand, with or without a label
 func isArbitraryValue(val string) bool {
-	return arbitraryRegex.MatchString(val)
+	return arbitraryRegex.MatchString(val) || arbitraryVariableRegex.MatchString(val)
 }
 
 func isPercent(val string) bool {
-	return val[len(val)-1] == '%' && isNumber(val[:len(val)-1])
+	return val != "" && val[len(val)-1] == '%' && isNumber(val[:len(val)-1])
+}
+
+// isFontStretch returns true if the given value is a font stretch keyword,
+// a percentage or an arbitrary value
+func isFontStretch(val string) bool {
+	return fontStretches[val] || isPercent(val) || isArbitraryValue(val)
 }
 
+// isPerspective returns true if the given value is a perspective keyword
+func isPerspective(val string) bool {
+	return perspectives[val]
+}
+
+// isOrigin returns true if the given value is a transform origin position
+func isOrigin(val string) bool {
+	return origins[val]
+}
+
+// isTshirtSize returns true if the given value is a t-shirt size like sm, xl
+// or 2xl
 func isTshirtSize(val string) bool {
-	return shirtPattern.MatchString(val)
+	if len(val) < 2 {
+		return false
+	}
+	switch val[len(val)-2:] {
+	case "xs", "sm", "md", "lg", "xl":
+	default:
+		return false
+	}
+	size := val[:len(val)-2]
+	if size == "" {
+		return true
+	}
+	whole, fraction, found := strings.Cut(size, ".")
+	return isInteger(whole) && (!found || isInteger(fraction))
 }
 
 func isShadow(val string) bool {
@@ -157,27 +246,56 @@ func isShadow(val string) bool {
 }
 
 func isImage(val string) bool {
-	pattern := regexp.MustCompile(`^(url|image|image-set|cross-fade|element|(repeating-)?(linear|radial|conic)-gradient)\(.+\)$`)
-	return pattern.MatchString(val)
+	return imageRegex.MatchString(val)
+}
+
+// isGridTemplate returns true if the given value is a grid template value
+// like 3, none, subgrid or [1fr_2fr]
+func isGridTemplate(val string) bool {
+	return val == "none" || val == "subgrid" || isInteger(val) || isArbitraryValue(val)
 }
 
 func isFraction(val string) bool {
-	pattern := regexp.MustCompile(`^\d+\/\d+$`)
-	return pattern.MatchString(val)
+	numerator, denominator, found := strings.Cut(val, "/")
+	return found && isInteger(numerator) && isInteger(denominator)
 }
 
+// isNumber returns true if the given value is an unsigned number like 4 or
+// 1.5, negative values are written with a leading dash -> -mt-4
 func isNumber(val string) bool {
 	return isInteger(val) || isFloat(val)
 }
 
+// isInteger returns true if the given value is made of ASCII digits only
 func isInteger(val string) bool {
-	_, err := strconv.Atoi(val)
-	return err == nil
+	if val == "" {
+		return false
+	}
+	for i := 0; i < len(val); i++ {
+		if val[i] < '0' || val[i] > '9' {
+			return false
+		}
+	}
+	return true
 }
 
+// isFloat returns true if the given value is a decimal number like 1.5, 1.
+// or .5
 func isFloat(val string) bool {
-	_, err := strconv.ParseFloat(val, 64)
-	return err == nil
+	whole, fraction, found := strings.Cut(val, ".")
+	if !found {
+		return isInteger(whole)
+	}
+	if whole == "" {
+		return isInteger(fraction)
+	}
+	return isInteger(whole) && (fraction == "" || isInteger(fraction))
+}
+
+// isSignedNumber returns true if the given value is a number, optionally
+// negative, as written in arbitrary values -> z-[-1]
+func isSignedNumber(val string) bool {
+	return isFloat(strings.TrimPrefix(val, "-"))
 }
 
 func isLengthOnly(val string) bool {
@@ -187,11 +305,17 @@ func isLengthOnly(val string) bool {
 // labelIsArbitraryValue returns true if the given value is an arbitrary value
 // with the given label. The label can be a string, a map[string]bool or a
 // function that takes a string and returns a bool.
+//
+// CSS variable shorthands only match with a label, as the type of the
+// variable is unknown otherwise -> text-(length:--size) is a font size
 func labelIsArbitraryValue(
 	val string,
 	label any,
 	testValue func(string) bool,
 ) bool {
+	if res := arbitraryVariableRegex.FindStringSubmatch(val); res != nil {
+		return res[1] != "" && labelMatches(res[1], label)
+	}
 	res := arbitraryRegex.FindStringSubmatch(val)
 	if len(res) > 1 {
 		if res[1] != "" {
@@ -210,6 +334,18 @@ func labelIsArbitraryValue(
 	return false
 }
 
+// labelMatches returns true if got is the label, a string or a
+// map[string]bool, of labelIsArbitraryValue
+func labelMatches(got string, label any) bool {
+	switch t := label.(type) {
+	case string:
+		return got == t
+	case map[string]bool:
+		return t[got]
+	}
+	return false
+}
+
 // defaultConfig is the default TwMergeConfig
 var defaultConfig = &config{
 	ModifierSeparator: ':',
@@ -217,6 +353,22 @@ var defaultConfig = &config{
 	ImportantModifier: '!',
 	PostfixModifier:   '/',
 	MaxCacheSize:      1000,
+	NegativeClassGroups: map[string]bool{
+		"inset": true, "inset-x": true, "inset-y": true, "start": true, "end": true,
+		"top": true, "right": true, "bottom": true, "left": true,
+		"z": true, "order": true,
+		"col-start": true, "col-end": true, "row-start": true, "row-end": true,
+		"m": true, "mx": true, "my": true, "ms": true, "me": true, "mt": true, "mr": true, "mb": true, "ml": true,
+		"space-x": true, "space-y": true,
+		"scroll-m": true, "scroll-mx": true, "scroll-my": true, "scroll-ms": true, "scroll-me": true,
+		"scroll-mt": true, "scroll-mr": true, "scroll-mb": true, "scroll-ml": true,
+		"translate-x": true, "translate-y": true, "translate-z": true,
+		"rotate": true, "rotate-x": true, "rotate-y": true, "rotate-z": true,
+		"skew-x": true, "skew-y": true,
+		"scale": true, "scale-x": true, "scale-y": true, "scale-z": true,
+		"hue-rotate": true, "backdrop-hue-rotate": true,
+		"tracking": true, "indent": true, "outline-offset": true,
+	},
 	ConflictingClassGroups: conflictingClassGroups{
 		"overflow":         {"overflow-x", "overflow-y"},
 		"overscroll":       {"overscroll-x", "overscroll-y"},
@@ -282,6 +434,10 @@ var defaultConfig = &config{
 					},
 				},
 				Validators: []classGroupValidator{
+					{
+						Fn:           isFraction,
+						ClassGroupID: "aspect",
+					},
 					{
 						Fn:           isArbitraryValue,
 						ClassGroupID: "aspect",
@@ -501,7 +657,7 @@ var defaultConfig = &config{
 					"cols": {
 						Validators: []classGroupValidator{
 							{
-								Fn:           isAny,
+								Fn:           isGridTemplate,
 								ClassGroupID: "grid-cols",
 							},
 						},
@@ -509,7 +665,7 @@ var defaultConfig = &config{
 					"rows": {
 						Validators: []classGroupValidator{
 							{
-								Fn:           isAny,
+								Fn:           isGridTemplate,
 								ClassGroupID: "grid-rows",
 							},
 						},
@@ -2347,6 +2503,16 @@ var defaultConfig = &config{
 					"black": {
 						ClassGroupID: "font-weight",
 					},
+					// Font Stretch
+					// @see https://tailwindcss.com/docs/font-stretch
+					"stretch": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isFontStretch,
+								ClassGroupID: "font-stretch",
+							},
+						},
+					},
 				},
 				Validators: []classGroupValidator{
 					{
@@ -4161,6 +4327,14 @@ var defaultConfig = &config{
 					"transform": {
 						ClassGroupID: "transition",
 					},
+					// Transition Behavior
+					// @see https://tailwindcss.com/docs/transition-behavior
+					"discrete": {
+						ClassGroupID: "transition-behavior",
+					},
+					"normal": {
+						ClassGroupID: "transition-behavior",
+					},
 				},
 				Validators: []classGroupValidator{
 					{
@@ -4251,9 +4425,59 @@ var defaultConfig = &config{
 					"none": {
 						ClassGroupID: "transform",
 					},
+					// Transform Style
+					// @see https://tailwindcss.com/docs/transform-style
+					"3d": {
+						ClassGroupID: "transform-style",
+					},
+					"flat": {
+						ClassGroupID: "transform-style",
+					},
 				},
 				ClassGroupID: "transform",
 			},
+			// Perspective
+			// @see https://tailwindcss.com/docs/perspective
+			"perspective": {
+				NextPart: map[string]classPart{
+					// Perspective Origin
+					// @see https://tailwindcss.com/docs/perspective-origin
+					"origin": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isOrigin,
+								ClassGroupID: "perspective-origin",
+							},
+							{
+								Fn:           isArbitraryValue,
+								ClassGroupID: "perspective-origin",
+							},
+						},
+					},
+				},
+				Validators: []classGroupValidator{
+					{
+						Fn:           isPerspective,
+						ClassGroupID: "perspective",
+					},
+					{
+						Fn:           isArbitraryValue,
+						ClassGroupID: "perspective",
+					},
+				},
+			},
+			// Backface Visibility
+			// @see https://tailwindcss.com/docs/backface-visibility
+			"backface": {
+				NextPart: map[string]classPart{
+					"visible": {
+						ClassGroupID: "backface",
+					},
+					"hidden": {
+						ClassGroupID: "backface",
+					},
+				},
+			},
 			"scale": {
 				NextPart: map[string]classPart{
 					"x": {
@@ -4280,6 +4504,21 @@ var defaultConfig = &config{
 							},
 						},
 					},
+					"z": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isNumber,
+								ClassGroupID: "scale-z",
+							},
+							{
+								Fn:           isArbitraryNumber,
+								ClassGroupID: "scale-z",
+							},
+						},
+					},
+					"3d": {
+						ClassGroupID: "scale-3d",
+					},
 				},
 				Validators: []classGroupValidator{
 					{
@@ -4293,6 +4532,46 @@ var defaultConfig = &config{
 				},
 			},
 			"rotate": {
+				NextPart: map[string]classPart{
+					// Rotate 3D
+					// @see https://tailwindcss.com/docs/rotate
+					"x": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isInteger,
+								ClassGroupID: "rotate-x",
+							},
+							{
+								Fn:           isArbitraryValue,
+								ClassGroupID: "rotate-x",
+							},
+						},
+					},
+					"y": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isInteger,
+								ClassGroupID: "rotate-y",
+							},
+							{
+								Fn:           isArbitraryValue,
+								ClassGroupID: "rotate-y",
+							},
+						},
+					},
+					"z": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isInteger,
+								ClassGroupID: "rotate-z",
+							},
+							{
+								Fn:           isArbitraryValue,
+								ClassGroupID: "rotate-z",
+							},
+						},
+					},
+				},
 				Validators: []classGroupValidator{
 					{
 						Fn:           isInteger,
@@ -4338,6 +4617,22 @@ var defaultConfig = &config{
 							},
 						},
 					},
+					"z": {
+						Validators: []classGroupValidator{
+							{
+								Fn:           isArbitraryValue,
+								ClassGroupID: "translate-z",
+							},
+							{
+								Fn:           isLength,
+								ClassGroupID: "translate-z",
+							},
+							{
+								Fn:           isArbitraryLength,
+								ClassGroupID: "translate-z",
+							},
+						},
+					},
 				},
 			},
 			"skew": {
@@ -1,6 +1,7 @@
 package twerge
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +22,73 @@ func TestArbitraryShadow(t *testing.T) {
 	assert.Equal(t, false, isArbitraryShadow("[#00f]"))
 	assert.Equal(t, false, isArbitraryShadow("[something-else]"))
 }
+
+func TestGridTemplate(t *testing.T) {
+	assert.Equal(t, true, isGridTemplate("3"))
+	assert.Equal(t, true, isGridTemplate("none"))
+	assert.Equal(t, true, isGridTemplate("subgrid"))
+	assert.Equal(t, true, isGridTemplate("[1fr_2fr]"))
+	assert.Equal(t, true, isGridTemplate("[repeat(auto-fill,minmax(0,1fr))]"))
+
+	assert.Equal(t, false, isGridTemplate("foo"))
+	assert.Equal(t, false, isGridTemplate("1/2"))
+	assert.Equal(t, false, isGridTemplate(""))
+}
+
+func TestFontStretch(t *testing.T) {
+	assert.Equal(t, true, isFontStretch("ultra-condensed"))
+	assert.Equal(t, true, isFontStretch("normal"))
+	assert.Equal(t, true, isFontStretch("125%"))
+	assert.Equal(t, true, isFontStretch("[66.66%]"))
+
+	assert.Equal(t, false, isFontStretch("sans"))
+	assert.Equal(t, false, isFontStretch(""))
+}
+
+func TestLengthOnlyExcludesColors(t *testing.T) {
+	assert.Equal(t, true, isLengthOnly("12px"))
+	assert.Equal(t, true, isLengthOnly("calc(100%-2rem)"))
+
+	assert.Equal(t, false, isLengthOnly("oklch(70%_0.1_200)"))
+	assert.Equal(t, false, isLengthOnly("color-mix(in_oklch,red_50%,blue)"))
+	assert.Equal(t, false, isLengthOnly("color(display-p3_1_0_0_/_50%)"))
+	assert.Equal(t, false, isLengthOnly("light-dark(#fff,#000)"))
+}
+
+func TestNumberIsUnsigned(t *testing.T) {
+	assert.Equal(t, true, isNumber("4"))
+	assert.Equal(t, true, isNumber("1.5"))
+	assert.Equal(t, true, isArbitraryNumber("[-1]"))
+	assert.Equal(t, true, isArbitraryNumber("[number:-1.5]"))
+
+	// negative values are written with a leading dash before the class
+	assert.Equal(t, false, isNumber("-4"))
+	assert.Equal(t, false, isNumber("+4"))
+	assert.Equal(t, false, isNumber("1e3"))
+	assert.Equal(t, false, isNumber("inf"))
+	assert.Equal(t, false, isNumber("NaN"))
+	assert.Equal(t, false, isInteger("1.5"))
+}
+
+func TestNumberMatchersMatchPatterns(t *testing.T) {
+	values := []string{
+		"", "0", "4", "12", "1.5", "1.", ".5", ".", "-1", "-1.5", "-.5", "--1", "-",
+		"1/2", "12/5", "1/", "/2", "1/2/3", "1.5/2", "a1",
+		"xs", "sm", "md", "lg", "xl", "2xl", "1.5xl", "1.xl", ".5xl", "-2xl", "x", "2xs", "sm2",
+	}
+	for _, tc := range []struct {
+		pattern string
+		match   func(string) bool
+	}{
+		{`^\d+$`, isInteger},
+		{`^(\d+(\.\d*)?|\.\d+)$`, isFloat},
+		{`^-?(\d+(\.\d*)?|\.\d+)$`, isSignedNumber},
+		{`^\d+\/\d+$`, isFraction},
+		{`^(\d+(\.\d+)?)?(xs|sm|md|lg|xl)$`, isTshirtSize},
+	} {
+		re := regexp.MustCompile(tc.pattern)
+		for _, v := range values {
+			assert.Equal(t, re.MatchString(v), tc.match(v), "%s %q", tc.pattern, v)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package twerge
+
+import (
+	"maps"
+	"strings"
+)
+
+// v4Config is the config of Tailwind v4: the v3 class groups extended with
+// the utilities added in v4.
+//
+// Container query variants like @md: and @max-lg: need no class groups, they
+// are modifiers like any other variant.
+var v4Config = extendConfig(defaultConfig, map[string]classPart{
+	"@container": {
+		NextPart: map[string]classPart{
+			"normal": {
+				ClassGroupID: "container-type",
+			},
+		},
+		ClassGroupID: "container-type",
+	},
+	"text-shadow": {
+		NextPart: map[string]classPart{
+			"none": {
+				ClassGroupID: "text-shadow",
+			},
+		},
+		Validators: []classGroupValidator{
+			{
+				Fn:           isTshirtSize,
+				ClassGroupID: "text-shadow",
+			},
+			{
+				Fn:           isArbitraryShadow,
+				ClassGroupID: "text-shadow",
+			},
+			{
+				Fn:           isAny,
+				ClassGroupID: "text-shadow-color",
+			},
+		},
+	},
+	"inset-shadow": {
+		NextPart: map[string]classPart{
+			"none": {
+				ClassGroupID: "inset-shadow",
+			},
+		},
+		Validators: []classGroupValidator{
+			{
+				Fn:           isTshirtSize,
+				ClassGroupID: "inset-shadow",
+			},
+			{
+				Fn:           isArbitraryShadow,
+				ClassGroupID: "inset-shadow",
+			},
+			{
+				Fn:           isAny,
+				ClassGroupID: "inset-shadow-color",
+			},
+		},
+	},
+	"inset-ring": {
+		Validators: []classGroupValidator{
+			{
+				Fn:           isLength,
+				ClassGroupID: "inset-ring-w",
+			},
+			{
+				Fn:           isArbitraryLength,
+				ClassGroupID: "inset-ring-w",
+			},
+			{
+				Fn:           isAny,
+				ClassGroupID: "inset-ring-color",
+			},
+		},
+		ClassGroupID: "inset-ring-w",
+	},
+	"field-sizing": {
+		NextPart: map[string]classPart{
+			"fixed": {
+				ClassGroupID: "field-sizing",
+			},
+			"content": {
+				ClassGroupID: "field-sizing",
+			},
+		},
+	},
+	"bg-linear": {
+		NextPart: map[string]classPart{
+			"to": {
+				NextPart: map[string]classPart{
+					"t":  {ClassGroupID: "bg-image"},
+					"tr": {ClassGroupID: "bg-image"},
+					"r":  {ClassGroupID: "bg-image"},
+					"br": {ClassGroupID: "bg-image"},
+					"b":  {ClassGroupID: "bg-image"},
+					"bl": {ClassGroupID: "bg-image"},
+					"l":  {ClassGroupID: "bg-image"},
+					"tl": {ClassGroupID: "bg-image"},
+				},
+			},
+		},
+		Validators: []classGroupValidator{
+			{
+				Fn:           isNumber,
+				ClassGroupID: "bg-image",
+			},
+			{
+				Fn:           isArbitraryValue,
+				ClassGroupID: "bg-image",
+			},
+		},
+	},
+	"bg-radial": {
+		Validators: []classGroupValidator{
+			{
+				Fn:           isArbitraryValue,
+				ClassGroupID: "bg-image",
+			},
+		},
+		ClassGroupID: "bg-image",
+	},
+	"bg-conic": {
+		Validators: []classGroupValidator{
+			{
+				Fn:           isNumber,
+				ClassGroupID: "bg-image",
+			},
+			{
+				Fn:           isArbitraryValue,
+				ClassGroupID: "bg-image",
+			},
+		},
+		ClassGroupID: "bg-image",
+	},
+})
+
+// extendConfig returns a copy of conf with the class parts added to its class
+// groups, keyed by the class prefix they are found under, e.g. "text-shadow".
+//
+// Only the class parts along the prefixes are copied, conf is not modified.
+func extendConfig(conf *config, parts map[string]classPart) *config {
+	updated := *conf
+	for prefix, part := range parts {
+		path := strings.Split(prefix, string(conf.ClassSeparator))
+		updated.ClassGroups = withClassPart(updated.ClassGroups, path, part)
+	}
+	return &updated
+}
+
+// withClassPart returns a copy of parent with part added at path below it.
+//
+// A part already at path is merged with part: the validators of part are
+// tried first and its class group and next parts take precedence.
+func withClassPart(parent classPart, path []string, part classPart) classPart {
+	next := maps.Clone(parent.NextPart)
+	if next == nil {
+		next = make(map[string]classPart)
+	}
+	if len(path) > 1 {
+		next[path[0]] = withClassPart(next[path[0]], path[1:], part)
+		parent.NextPart = next
+		return parent
+	}
+
+	existing := next[path[0]]
+	if part.ClassGroupID == "" {
+		part.ClassGroupID = existing.ClassGroupID
+	}
+	part.Validators = append(part.Validators[:len(part.Validators):len(part.Validators)], existing.Validators...)
+	if len(existing.NextPart) > 0 {
+		merged := maps.Clone(existing.NextPart)
+		maps.Copy(merged, part.NextPart)
+		part.NextPart = merged
+	}
+	next[path[0]] = part
+	parent.NextPart = next
+	return parent
+}
@@ -0,0 +1,69 @@
+package twerge
+
+import (
+	"maps"
+	"slices"
+)
+
+// ConflictConfig toggles conflicts between class groups of different CSS
+// properties, where a class of one group removes classes of another group.
+//
+// The defaults, returned by DefaultConflictConfig, match tailwind-merge.
+type ConflictConfig struct {
+	// LineClampDisplay makes line-clamp-* remove display classes like block,
+	// because line-clamp sets display: -webkit-box. Default: true
+	LineClampDisplay bool
+	// LineClampOverflow makes line-clamp-* remove overflow-* classes, because
+	// line-clamp sets overflow: hidden. Default: true
+	LineClampOverflow bool
+	// FontSizeLeading makes text-* font sizes remove leading-* classes,
+	// because font sizes set a line-height. Default: true
+	FontSizeLeading bool
+}
+
+// DefaultConflictConfig returns the ConflictConfig used by Merge by default.
+func DefaultConflictConfig() ConflictConfig {
+	return ConflictConfig{
+		LineClampDisplay:  true,
+		LineClampOverflow: true,
+		FontSizeLeading:   true,
+	}
+}
+
+// SetConflictConfig replaces Merge with a merger using the given cross-group
+// conflicts.
+//
+// It resets the merge cache and is not safe to call concurrently with Merge,
+// so it should be called once at program start.
+func SetConflictConfig(c ConflictConfig) {
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	settings.Conflicts = c
+	rebuildMerge()
+}
+
+// apply returns a copy of conf with the cross-group conflicts toggled.
+func (c ConflictConfig) apply(conf *config) *config {
+	updated := *conf
+	updated.ConflictingClassGroups = maps.Clone(conf.ConflictingClassGroups)
+
+	toggle := func(group, conflict string, enabled bool) {
+		conflicts := slices.DeleteFunc(
+			slices.Clone(updated.ConflictingClassGroups[group]),
+			func(g string) bool { return g == conflict },
+		)
+		if enabled {
+			conflicts = append(conflicts, conflict)
+		}
+		if len(conflicts) == 0 {
+			delete(updated.ConflictingClassGroups, group)
+			return
+		}
+		updated.ConflictingClassGroups[group] = conflicts
+	}
+	toggle("line-clamp", "display", c.LineClampDisplay)
+	toggle("line-clamp", "overflow", c.LineClampOverflow)
+	toggle("font-size", "leading", c.FontSizeLeading)
+
+	return &updated
+}
@@ -0,0 +1,27 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictConfig(t *testing.T) {
+	defer SetConflictConfig(DefaultConflictConfig())
+
+	assert.Equal(t, "line-clamp-2", Merge("block overflow-auto line-clamp-2"))
+
+	c := DefaultConflictConfig()
+	c.LineClampDisplay = false
+	SetConflictConfig(c)
+	assert.Equal(t, "block line-clamp-2", Merge("block overflow-auto line-clamp-2"))
+
+	c.LineClampOverflow = false
+	c.FontSizeLeading = false
+	SetConflictConfig(c)
+	assert.Equal(t, "block overflow-auto line-clamp-2", Merge("block overflow-auto line-clamp-2"))
+	assert.Equal(t, "leading-9 text-lg", Merge("leading-9 text-lg"))
+
+	// the default config is left untouched
+	assert.Equal(t, []string{"display", "overflow"}, defaultConfig.ConflictingClassGroups["line-clamp"])
+}
@@ -0,0 +1,82 @@
+package twerge
+
+import (
+	"io"
+)
+
+// criticalClasses is the set of generated class names marked as critical
+// It is protected by mapMutex for concurrent access
+var criticalClasses = make(map[string]bool)
+
+// markCritical marks the generated class name as critical.
+func markCritical(className string) {
+	mapMutex.Lock()
+	defer mapMutex.Unlock()
+	if !criticalClasses[className] {
+		criticalClasses[className] = true
+		// the stylesheets of CSSHandler change
+		mapVersion.Add(1)
+	}
+}
+
+// MarkCritical marks the class names of the class strings as critical,
+// generating them like It if needed. It selects the components whose rules are
+// inlined in the head of every page, typically those above the fold, when
+// their class strings are registered by generated code:
+//
+//	func init() {
+//		twerge.MarkCritical(
+//			"flex items-center justify-between px-6 py-4", // header
+//			"text-4xl font-bold tracking-tight",            // hero title
+//		)
+//	}
+func MarkCritical(classes ...string) {
+	for _, c := range classes {
+		markCritical(It(c))
+	}
+}
+
+// IsCritical reports whether the generated class name is marked as critical.
+func IsCritical(className string) bool {
+	mapMutex.RLock()
+	defer mapMutex.RUnlock()
+	return criticalClasses[className]
+}
+
+// WriteCriticalCSS writes the @apply rules for every class marked as critical to w.
+//
+// The output is meant to be inlined in a <style> block of landing pages.
+func WriteCriticalCSS(w io.Writer, opts ...MapOption) error {
+	return writeGenClasses(w, func(className string) bool {
+		return criticalClasses[className]
+	}, opts)
+}
+
+// WriteDeferredCSS writes the @apply rules for every class not marked as
+// critical to w.
+//
+// Together with WriteCriticalCSS it covers every generated class exactly once.
+func WriteDeferredCSS(w io.Writer, opts ...MapOption) error {
+	return writeGenClasses(w, func(className string) bool {
+		return !criticalClasses[className]
+	}, opts)
+}
+
+// writeGenClasses writes the rules from GenClassMergeStr whose class name is
+// selected by keep, ordered by class name unless configured otherwise.
+//
+// keep is called with mapMutex held.
+func writeGenClasses(w io.Writer, keep func(className string) bool, opts []MapOption) error {
+	o := newMapOptions(opts)
+
+	mapMutex.RLock()
+	selected := make(map[string]string, len(GenClassMergeStr))
+	for className, merged := range GenClassMergeStr {
+		if keep(className) {
+			selected[className] = merged
+		}
+	}
+	mapMutex.RUnlock()
+
+	return o.writeRules(w, selected)
+}
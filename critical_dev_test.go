@@ -0,0 +1,37 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCriticalSplit(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	criticalClasses = make(map[string]bool)
+	mapMutex.Unlock()
+
+	RegisterCriticalClasses(map[string]string{"flex items-center": "tw-hero"})
+	RegisterClasses(map[string]string{"p-2 p-4": "tw-footer"})
+	inline := ItCritical("text-lg font-bold")
+
+	assert.True(t, IsCritical("tw-hero"))
+	assert.True(t, IsCritical(inline))
+	assert.False(t, IsCritical("tw-footer"))
+
+	var critical, deferred strings.Builder
+	assert.NoError(t, WriteCriticalCSS(&critical))
+	assert.NoError(t, WriteDeferredCSS(&deferred))
+
+	assert.Contains(t, critical.String(), ".tw-hero {")
+	assert.Contains(t, critical.String(), "."+inline+" {")
+	assert.NotContains(t, critical.String(), ".tw-footer")
+
+	assert.Contains(t, deferred.String(), ".tw-footer { \n\t@apply p-4; \n}")
+	assert.NotContains(t, deferred.String(), ".tw-hero")
+}
@@ -0,0 +1,27 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkCritical(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	criticalClasses = make(map[string]bool)
+	mapMutex.Unlock()
+
+	RegisterClasses(map[string]string{"flex items-center": "tw-header", "p-2 p-4": "tw-footer"})
+	MarkCritical("flex items-center", "text-lg font-bold")
+	assert.True(t, IsCritical("tw-header"))
+	assert.True(t, IsCritical(It("text-lg font-bold")))
+	assert.False(t, IsCritical("tw-footer"))
+
+	// marking a class again does not change the stylesheets
+	version := mapVersion.Load()
+	MarkCritical("flex items-center")
+	assert.Equal(t, version, mapVersion.Load())
+
+}
@@ -0,0 +1,120 @@
+package twerge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decision explains what Merge did with a single class, see MergeDebug.
+type Decision struct {
+	// Class is the class as written
+	Class string
+	// Kept reports whether the class is part of the merge result
+	Kept bool
+	// GroupID is the class group of the class, empty for classes Merge does
+	// not know
+	GroupID string
+	// Reason describes why the class was kept or dropped
+	Reason string
+	// DisplacedBy is the later class that removed the class, if any
+	DisplacedBy string
+}
+
+// String describes the decision in a single line.
+func (d Decision) String() string {
+	verdict := "kept"
+	if !d.Kept {
+		verdict = "dropped"
+	}
+	return fmt.Sprintf("%s: %s, %s", d.Class, verdict, d.Reason)
+}
+
+// MergeDebug merges classes like Merge, and explains for every class whether
+// it was kept or dropped, the class group it belongs to and which later class
+// displaced it:
+//
+//	result, trace := twerge.MergeDebug("p-2 px-4 p-6")
+//	// result: "p-6"
+//	// trace[0]: p-2: dropped, overridden by p-6 in group p
+//	// trace[1]: px-4: dropped, conflicts with p-6 of group p
+//	// trace[2]: p-6: kept, last class of group p
+//
+// The result keeps the order of the classes. Unlike Merge, MergeDebug does
+// not use the cache and does not record the class string.
+func MergeDebug(classes string) (string, []Decision) {
+	conf, trie := currentTrie()
+	splitModifiers := makeSplitModifiers(conf)
+	getClassGroupID := makeGetClassGroupIDFromTrie(conf, trie)
+
+	var trace []Decision
+	// owners maps class groups with their modifiers to the index of the
+	// class in trace holding them
+	owners := make(map[string]int)
+	// passed holds the classes passed through, which are kept once
+	passed := make(map[string]bool)
+	pass := func(d *Decision, reason string) {
+		if passed[d.Class] {
+			d.Kept, d.Reason = false, "duplicate of an earlier class"
+			return
+		}
+		passed[d.Class] = true
+		d.Reason = reason
+	}
+	for _, class := range strings.Fields(classes) {
+		i := len(trace)
+		trace = append(trace, Decision{Class: class, Kept: true})
+		d := &trace[i]
+		switch {
+		case conf.Blocklist.match(class):
+			d.Kept, d.Reason = false, "blocklisted"
+			continue
+		case conf.Safelist.match(class):
+			pass(d, "safelisted")
+			continue
+		}
+		baseClass, modifiers, hasImportant, postFixMod := splitModifiers(class)
+		groupID, isTwClass := resolveClassGroup(conf, getClassGroupID, baseClass, postFixMod)
+		if !isTwClass {
+			pass(d, "unknown class, passed through")
+			continue
+		}
+		d.GroupID = groupID
+		d.Reason = "last class of group " + groupID
+
+		modifiers = sortModifiers(modifiers)
+		if hasImportant {
+			modifiers = append(modifiers, "!")
+		}
+		modifierKey := strings.Join(modifiers, string(conf.ModifierSeparator))
+		if owner, ok := owners[groupID+modifierKey]; ok {
+			displaced := &trace[owner]
+			displaced.Kept, displaced.DisplacedBy = false, class
+			displaced.Reason = "overridden by " + class + " in group " + groupID
+		}
+		owners[groupID+modifierKey] = i
+
+		for _, conflict := range conf.ConflictingClassGroups[groupID] {
+			owner, ok := owners[conflict+modifierKey]
+			if !ok {
+				continue
+			}
+			displaced := &trace[owner]
+			displaced.Kept, displaced.DisplacedBy = false, class
+			displaced.Reason = "conflicts with " + class + " of group " + groupID
+			delete(owners, conflict+modifierKey)
+		}
+	}
+
+	var result []string
+	for i := range trace {
+		d := &trace[i]
+		// like Merge, dev-only classes are removed after merging
+		if d.Kept && stripDevOnlyClasses(d.Class, splitModifiers) == "" {
+			d.Kept, d.Reason = false, "dev-only"
+		}
+		if d.Kept {
+			result = append(result, d.Class)
+		}
+	}
+	return strings.Join(result, " "), trace
+}
@@ -0,0 +1,31 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDebug(t *testing.T) {
+	result, trace := MergeDebug("p-2 px-4 hover:p-1 p-6 custom hover:p-3")
+	assert.Equal(t, "p-6 custom hover:p-3", result)
+	assert.Equal(t, result, Merge("p-2 px-4 hover:p-1 p-6 custom hover:p-3"))
+	assert.Equal(t, []Decision{
+		{Class: "p-2", GroupID: "p", Reason: "overridden by p-6 in group p", DisplacedBy: "p-6"},
+		{Class: "px-4", GroupID: "px", Reason: "conflicts with p-6 of group p", DisplacedBy: "p-6"},
+		{Class: "hover:p-1", GroupID: "p", Reason: "overridden by hover:p-3 in group p", DisplacedBy: "hover:p-3"},
+		{Class: "p-6", Kept: true, GroupID: "p", Reason: "last class of group p"},
+		{Class: "custom", Kept: true, Reason: "unknown class, passed through"},
+		{Class: "hover:p-3", Kept: true, GroupID: "p", Reason: "last class of group p"},
+	}, trace)
+	assert.Equal(t, "px-4: dropped, conflicts with p-6 of group p", trace[1].String())
+	assert.Equal(t, "p-6: kept, last class of group p", trace[3].String())
+
+	result, trace = MergeDebug("card\n\tp-2 card")
+	assert.Equal(t, "card p-2", result)
+	assert.Equal(t, "card: dropped, duplicate of an earlier class", trace[2].String())
+
+	result, trace = MergeDebug("")
+	assert.Empty(t, result)
+	assert.Empty(t, trace)
+}
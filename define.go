@@ -0,0 +1,77 @@
+package twerge
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Variants maps the variants of a Definition to the classes of their
+// options, like {"size": {"sm": "px-2 py-1", "lg": "px-4 py-2"}}.
+type Variants map[string]map[string]string
+
+// Definition is a base class string with variants selected by name, like
+// class-variance-authority and tailwind-variants, see Define.
+type Definition struct {
+	base     string
+	variants Variants
+	// names are the variant names, sorted
+	names []string
+}
+
+// Define returns a Definition of the base classes and their variants:
+//
+//	var button = twerge.Define("inline-flex rounded px-3 py-2", twerge.Variants{
+//		"size":   {"sm": "px-2 py-1 text-sm", "lg": "px-4 py-2 text-lg"},
+//		"intent": {"primary": "bg-blue-500 text-white", "danger": "bg-red-500 text-white"},
+//	})
+//
+//	templ Button(size string) {
+//		<button class={ button.Class("size", size, "intent", "primary") }>...</button>
+//	}
+//
+// The variants are copied, so they can not be changed afterwards.
+func Define(base string, variants Variants) *Definition {
+	d := &Definition{
+		base:     strings.Join(strings.Fields(base), " "),
+		variants: make(Variants, len(variants)),
+	}
+	for name, options := range variants {
+		d.variants[name] = maps.Clone(options)
+	}
+	d.names = slices.Sorted(maps.Keys(d.variants))
+	return d
+}
+
+// Class returns the class name of the base classes merged with the options
+// selected by selections, pairs of a variant and an option name, see It.
+// Options override the base classes they conflict with. Unknown variants and
+// options are ignored, and the last selection of a variant wins.
+func (d *Definition) Class(selections ...string) string {
+	return It(d.Classes(selections...))
+}
+
+// Classes returns the class string Class generates a class name for: the base
+// classes followed by the classes of the selected options in the sorted order
+// of their variants, so equal selections produce equal class strings. In
+// production mode, these class strings must be registered to get class names.
+func (d *Definition) Classes(selections ...string) string {
+	selected := make(map[string]string, len(selections)/2)
+	for i := 0; i+1 < len(selections); i += 2 {
+		options, ok := d.variants[selections[i]]
+		if !ok {
+			continue
+		}
+		if classes, ok := options[selections[i+1]]; ok {
+			selected[selections[i]] = classes
+		}
+	}
+
+	parts := []string{d.base}
+	for _, name := range d.names {
+		if classes, ok := selected[name]; ok {
+			parts = append(parts, classes)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
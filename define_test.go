@@ -0,0 +1,35 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefine(t *testing.T) {
+	variants := Variants{
+		"size":   {"sm": "px-2 py-1", "lg": "px-4 py-2"},
+		"intent": {"primary": "bg-blue-500", "danger": "bg-red-500"},
+	}
+	button := Define("inline-flex  rounded px-3 py-2", variants)
+	// the variants are copied
+	variants["size"]["sm"] = "p-0"
+
+	assert.Equal(t, "inline-flex rounded px-3 py-2", button.Classes())
+	assert.Equal(t, "inline-flex rounded px-3 py-2 px-2 py-1", button.Classes("size", "sm"))
+	// variants are applied in their sorted order, whatever the order of the
+	// selections
+	assert.Equal(t, "inline-flex rounded px-3 py-2 bg-red-500 px-4 py-2", button.Classes("size", "lg", "intent", "danger"))
+	assert.Equal(t, button.Classes("size", "lg", "intent", "danger"), button.Classes("intent", "danger", "size", "lg"))
+	// unknown variants and options and a missing option are ignored, the
+	// last selection of a variant wins
+	assert.Equal(t, "inline-flex rounded px-3 py-2 px-4 py-2", button.Classes("color", "red", "intent", "info", "size", "sm", "size", "lg", "intent"))
+
+	className := button.Class("size", "lg")
+	assert.Equal(t, It("inline-flex rounded px-3 py-2 px-4 py-2"), className)
+	mapMutex.RLock()
+	assert.Equal(t, "inline-flex rounded px-4 py-2", GenClassMergeStr[className])
+	mapMutex.RUnlock()
+}
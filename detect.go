@@ -0,0 +1,139 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UseDetectedTailwindVersion detects the Tailwind version of the project in
+// dir and selects it with SetTailwindVersion. The selection is left unchanged
+// if no version is detected.
+func UseDetectedTailwindVersion(dir string) (TailwindVersion, error) {
+	v, err := DetectTailwindVersion(dir)
+	if err != nil || v == TailwindUnknown {
+		return v, err
+	}
+	SetTailwindVersion(v)
+	return v, nil
+}
+
+// DetectTailwindVersion detects the Tailwind version of the project in dir.
+//
+// It checks, in order, the installed node_modules/tailwindcss package, the
+// tailwindcss dependency of package.json, a standalone tailwindcss binary in
+// dir or dir/bin, and finally the CSS files below dir, where
+// @import "tailwindcss" marks a v4 entry and @tailwind directives a v3 one.
+func DetectTailwindVersion(dir string) (TailwindVersion, error) {
+	installed, err := os.ReadFile(filepath.Join(dir, "node_modules", "tailwindcss", "package.json"))
+	if err == nil {
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(installed, &pkg) == nil {
+			if v := parseTailwindVersion(pkg.Version); v != TailwindUnknown {
+				return v, nil
+			}
+		}
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err == nil {
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if err := json.Unmarshal(manifest, &pkg); err != nil {
+			return TailwindUnknown, fmt.Errorf("error parsing package.json: %w", err)
+		}
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+			if v := parseTailwindVersion(deps["tailwindcss"]); v != TailwindUnknown {
+				return v, nil
+			}
+		}
+	}
+
+	for _, bin := range []string{
+		filepath.Join(dir, "tailwindcss"),
+		filepath.Join(dir, "bin", "tailwindcss"),
+	} {
+		if info, err := os.Stat(bin); err == nil && info.Mode()&0111 != 0 {
+			if v, err := TailwindBinaryVersion(bin); err == nil && v != TailwindUnknown {
+				return v, nil
+			}
+		}
+	}
+
+	return detectCSSTailwindVersion(dir)
+}
+
+// TailwindBinaryVersion runs the standalone Tailwind CLI at bin and returns
+// the version it reports.
+func TailwindBinaryVersion(bin string) (TailwindVersion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// v4 prints its version in the help header, v3 supports --version
+	for _, arg := range []string{"--help", "--version"} {
+		out, err := exec.CommandContext(ctx, bin, arg).CombinedOutput()
+		if err != nil && len(out) == 0 {
+			return TailwindUnknown, fmt.Errorf("error running %s: %w", bin, err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if !strings.Contains(strings.ToLower(line), "tailwind") && arg == "--help" {
+				continue
+			}
+			if v := parseTailwindVersion(line); v != TailwindUnknown {
+				return v, nil
+			}
+		}
+	}
+	return TailwindUnknown, nil
+}
+
+// detectCSSTailwindVersion detects the Tailwind version from the CSS entry
+// files below dir.
+func detectCSSTailwindVersion(dir string) (TailwindVersion, error) {
+	version := TailwindUnknown
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "node_modules" || (d.Name() != "." && strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".css" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case bytes.Contains(content, []byte(`@import "tailwindcss"`)),
+			bytes.Contains(content, []byte(`@import 'tailwindcss'`)):
+			version = TailwindV4
+			return filepath.SkipAll
+		case bytes.Contains(content, []byte("@tailwind ")):
+			version = TailwindV3
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return TailwindUnknown, fmt.Errorf("error scanning %s: %w", dir, err)
+	}
+	return version, nil
+}
@@ -0,0 +1,44 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectTailwindVersion(t *testing.T) {
+	write := func(dir, name, content string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// the installed package wins over package.json
+	dir := t.TempDir()
+	write(dir, "package.json", `{"devDependencies": {"tailwindcss": "^3.4.0"}}`)
+	write(dir, "node_modules/tailwindcss/package.json", `{"version": "4.1.3"}`)
+	v, err := DetectTailwindVersion(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, TailwindV4, v)
+
+	dir = t.TempDir()
+	write(dir, "package.json", `{"dependencies": {"tailwindcss": "^3.4.0"}}`)
+	v, err = DetectTailwindVersion(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, TailwindV3, v)
+
+	// v4 CSS entry
+	dir = t.TempDir()
+	write(dir, "static/input.css", "@import \"tailwindcss\";\n")
+	v, err = DetectTailwindVersion(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, TailwindV4, v)
+
+	v, err = DetectTailwindVersion(t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, TailwindUnknown, v)
+}
@@ -0,0 +1,105 @@
+package twerge
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// stripDevOnly enables removal of dev-only classes from merged output
+	stripDevOnly atomic.Bool
+
+	// devOnlyMutex protects the dev-only patterns
+	devOnlyMutex sync.RWMutex
+	// devOnlyClasses are classes removed entirely, e.g. "outline-red-500"
+	devOnlyClasses = make(map[string]bool)
+	// devOnlyPrefixes are class prefixes, e.g. "debug-" from "debug-*"
+	devOnlyPrefixes []string
+	// devOnlyVariants are variants, e.g. "debug" from "debug:"
+	devOnlyVariants = make(map[string]bool)
+)
+
+func init() {
+	stripDevOnly.Store(ProductionMode || os.Getenv("TWERGE_ENV") == "production")
+}
+
+// MarkDevOnly marks classes that should only be kept during development.
+//
+// A pattern ending in ":" marks a variant, so "debug:" matches "debug:outline"
+// and "md:debug:ring-2". A pattern ending in "*" marks a class prefix, so
+// "debug-*" matches "debug-grid". Any other pattern must match the class exactly.
+//
+// Dev-only classes are removed by Merge, and therefore from generated CSS,
+// when stripping is enabled. See SetStripDevOnly.
+func MarkDevOnly(patterns ...string) {
+	devOnlyMutex.Lock()
+	defer devOnlyMutex.Unlock()
+	for _, pattern := range patterns {
+		switch {
+		case strings.HasSuffix(pattern, ":"):
+			devOnlyVariants[strings.TrimSuffix(pattern, ":")] = true
+		case strings.HasSuffix(pattern, "*"):
+			devOnlyPrefixes = append(devOnlyPrefixes, strings.TrimSuffix(pattern, "*"))
+		default:
+			devOnlyClasses[pattern] = true
+		}
+	}
+}
+
+// SetStripDevOnly enables or disables removal of dev-only classes.
+//
+// It defaults to enabled in production builds (the twerge_prod build tag) or
+// when the TWERGE_ENV environment variable is "production". Merge results are
+// cached, so it should be set before the first merge.
+func SetStripDevOnly(enabled bool) {
+	stripDevOnly.Store(enabled)
+}
+
+// stripDevOnlyClasses removes the dev-only classes from a merged class list,
+// splitting their variants with splitModifiers.
+func stripDevOnlyClasses(classList string, splitModifiers splitModifiersFn) string {
+	if !stripDevOnly.Load() {
+		return classList
+	}
+
+	devOnlyMutex.RLock()
+	defer devOnlyMutex.RUnlock()
+	if len(devOnlyClasses) == 0 && len(devOnlyPrefixes) == 0 && len(devOnlyVariants) == 0 {
+		return classList
+	}
+
+	classes := strings.Fields(classList)
+	kept := classes[:0]
+	for _, class := range classes {
+		if !isDevOnly(class, splitModifiers) {
+			kept = append(kept, class)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// isDevOnly reports whether class matches a dev-only pattern. Variants are
+// split with splitModifiers, so separators in arbitrary values and variants
+// like [&:hover]:p-2 do not split them. devOnlyMutex must be held.
+func isDevOnly(class string, splitModifiers splitModifiersFn) bool {
+	if devOnlyClasses[class] {
+		return true
+	}
+	base, variants, _, _ := splitModifiers(class)
+	for _, variant := range variants {
+		if devOnlyVariants[variant] {
+			return true
+		}
+	}
+	if devOnlyClasses[base] {
+		return true
+	}
+	for _, prefix := range devOnlyPrefixes {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripDevOnly(t *testing.T) {
+	defer SetStripDevOnly(false)
+	MarkDevOnly("outline-red-500", "debug:", "debug-*")
+
+	SetStripDevOnly(false)
+	assert.Equal(t, "outline-red-500", Merge("outline-red-500"))
+
+	SetStripDevOnly(true)
+	assert.Equal(t, "p-4", Merge("p-4 outline-red-500 md:debug:ring-2 debug-grid"))
+	assert.Equal(t, "", Merge("debug:bg-red-500"))
+	split := makeSplitModifiers(defaultConfig)
+	assert.Equal(t, "p-4", stripDevOnlyClasses("hover:outline-red-500 p-4", split))
+
+	// separators in arbitrary values and variants do not split variants
+	MarkDevOnly("hover:", "http*")
+	defer func() {
+		devOnlyMutex.Lock()
+		delete(devOnlyVariants, "hover")
+		devOnlyPrefixes = devOnlyPrefixes[:len(devOnlyPrefixes)-1]
+		devOnlyMutex.Unlock()
+	}()
+	assert.Equal(t, "[&:hover]:p-2 bg-[url(http://x)]", stripDevOnlyClasses("[&:hover]:p-2 bg-[url(http://x)]", split))
+	assert.Equal(t, "", stripDevOnlyClasses("hover:[&:focus]:p-2", split))
+}
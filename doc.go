@@ -30,9 +30,9 @@
 //	className := twerge.RuntimeGenerate("p-4 m-2")
 //	// Returns a deterministic class name, stored in the runtime map
 //
-//	// Generate CSS for all registered classes
-//	css := twerge.GetRuntimeClassHTML()
-//	// Returns CSS like: ".tw-btn-blue { @apply bg-blue-500 text-white; }"
+//	// Stream the CSS of all registered classes
+//	err := twerge.WriteGeneratedCSS(w)
+//	// Writes CSS like: ".tw-btn-blue { @apply bg-blue-500 text-white; }"
 //
 // For templ users:
 //
@@ -44,9 +44,21 @@
 //	  Using runtime generated class name
 //	</div>
 //
-//	<style>
-//	  @unsafe {
-//	    twerge.GetRuntimeClassHTML()
-//	  }
-//	</style>
+//	@templ.Raw(string(twerge.StyleTag()))
+//
+// Production Builds:
+//
+// Building with the twerge_prod tag turns It and RuntimeGenerate into pure
+// lookups in the class map registered with RegisterClasses:
+//
+//	go build -tags twerge_prod ./...
+//
+// TinyGo Builds:
+//
+// TinyGo sets the tinygo tag, which leaves out everything needing net/http,
+// templ, os/exec or code generation: the HTTP handlers, the templ helpers, the
+// Go code generators, the project scanner and Tailwind version detection. The
+// standard toolchain leaves them out as well with:
+//
+//	GOOS=js GOARCH=wasm go build -tags tinygo ./...
 package twerge
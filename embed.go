@@ -0,0 +1,103 @@
+package twerge
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checksumPrefix starts the first line of CSS files written by WriteEmbedCSS
+const checksumPrefix = "/* twerge:checksum "
+
+// ErrStaleEmbed is returned by VerifyEmbeddedCSS when the embedded CSS was
+// generated from a different class map than the one compiled in.
+var ErrStaleEmbed = errors.New("embedded css is stale")
+
+// ClassMapChecksum returns a hex encoded SHA-256 checksum of the class map.
+//
+// The checksum only depends on the entries of the map, not on their order.
+func ClassMapChecksum(classMap map[string]string) string {
+	h := sha256.New()
+	for _, original := range SortedKeys(classMap) {
+		h.Write([]byte(original))
+		h.Write([]byte{0})
+		h.Write([]byte(classMap[original]))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WriteEmbedCSS writes the CSS rules for the class map to dir/name so that
+// the directory can be embedded with go:embed.
+//
+// The first line of the file records the ClassMapChecksum of the class map,
+// which VerifyEmbeddedCSS checks at program start.
+func WriteEmbedCSS(dir, name string, classMap map[string]string, opts ...MapOption) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating embed directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(checksumPrefix + ClassMapChecksum(classMap) + " */\n")
+	err = WriteCSS(&buf, classMap, opts...)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing embed css: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmbeddedCSS checks that the CSS file name in fsys was written by
+// WriteEmbedCSS for the given class map.
+//
+// It returns an error wrapping ErrStaleEmbed when the checksums differ.
+//
+//	//go:embed static
+//	var static embed.FS
+//
+//	func init() {
+//		twerge.MustVerifyEmbeddedCSS(static, "static/styles.css", ClassMapStr)
+//	}
+func VerifyEmbeddedCSS(fsys fs.FS, name string, classMap map[string]string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fmt.Errorf("error opening embedded css: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("error reading embedded css: %w", err)
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, checksumPrefix) || !strings.HasSuffix(line, "*/") {
+		return fmt.Errorf("%w: %s has no twerge checksum", ErrStaleEmbed, name)
+	}
+
+	embedded := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, checksumPrefix), "*/"))
+	expected := ClassMapChecksum(classMap)
+	if embedded != expected {
+		return fmt.Errorf("%w: %s has checksum %s, expected %s", ErrStaleEmbed, name, embedded, expected)
+	}
+	return nil
+}
+
+// MustVerifyEmbeddedCSS is like VerifyEmbeddedCSS but panics on error.
+func MustVerifyEmbeddedCSS(fsys fs.FS, name string, classMap map[string]string) {
+	err := VerifyEmbeddedCSS(fsys, name, classMap)
+	if err != nil {
+		panic(err)
+	}
+}
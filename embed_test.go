@@ -0,0 +1,30 @@
+package twerge
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedCSS(t *testing.T) {
+	classMap := map[string]string{
+		"flex items-center": "tw-row",
+		"p-2 p-4":           "tw-pad",
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, WriteEmbedCSS(dir, "styles.css", classMap))
+
+	fsys := os.DirFS(dir)
+	assert.NoError(t, VerifyEmbeddedCSS(fsys, "styles.css", classMap))
+	assert.NotPanics(t, func() { MustVerifyEmbeddedCSS(fsys, "styles.css", classMap) })
+
+	stale := MergeCSSMaps(classMap, map[string]string{"m-2": "tw-new"})
+	assert.ErrorIs(t, VerifyEmbeddedCSS(fsys, "styles.css", stale), ErrStaleEmbed)
+	assert.Panics(t, func() { MustVerifyEmbeddedCSS(fsys, "styles.css", stale) })
+
+	plain := fstest.MapFS{"styles.css": {Data: []byte(".tw-row { display: flex; }\n")}}
+	assert.ErrorIs(t, VerifyEmbeddedCSS(plain, "styles.css", classMap), ErrStaleEmbed)
+}
@@ -6,19 +6,17 @@ import (
 	"fmt"
 	"time"
 
-	"maps"
-
 	"github.com/conneroisu/twerge"
 )
 
 func main() {
 	// Populate the ClassMapStr with some frequently used class combinations
-	twerge.ClassMapStr = map[string]string{
+	twerge.SetMapping(map[string]string{
 		"flex items-center justify-center":   "tw-header",
 		"p-4 bg-blue-500 text-white rounded": "tw-button",
 		"grid grid-cols-3 gap-4":             "tw-grid3",
 		"text-xl font-bold text-gray-900":    "tw-title",
-	}
+	})
 
 	// Example 1: Direct lookup from ClassMapStr
 	fmt.Println("Example 1: Direct lookup from ClassMapStr")
@@ -44,8 +42,8 @@ func main() {
 		"flex flex-col space-y-4": "tw-colstack",
 	}
 
-	// Update the ClassMapStr map directly
-	maps.Copy(twerge.ClassMapStr, additionalClasses)
+	// Add them to the ClassMapStr map
+	twerge.RegisterClasses(additionalClasses)
 
 	// This uses ClassMapStr for quick lookup
 	fmt.Println("Example 3: Using ClassMapStr for lookups")
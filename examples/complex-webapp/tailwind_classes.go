@@ -68,9 +68,7 @@ func InitTailwindClasses() {
 		"hidden sm:block": "tw-hide-mobile",
 		"block sm:hidden": "tw-hide-desktop",
 	}
-	
+
 	// Add all classes to the ClassMapStr
-	for k, v := range classes {
-		twerge.ClassMapStr[k] = v
-	}
-}
\ No newline at end of file
+	twerge.RegisterClasses(classes)
+}
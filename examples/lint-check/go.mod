@@ -6,4 +6,11 @@ require github.com/conneroisu/twerge v0.0.0
 
 replace github.com/conneroisu/twerge => ../../
 
-require github.com/dave/jennifer v1.7.1 // indirect
+require (
+	github.com/a-h/parse v0.0.0-20250122154542-74294addb73e // indirect
+	github.com/a-h/templ v0.3.857 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dave/jennifer v1.7.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
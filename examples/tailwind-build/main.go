@@ -9,8 +9,6 @@ import (
 	"os/exec"
 	"path/filepath"
 
-	"maps"
-
 	"github.com/conneroisu/twerge"
 )
 
@@ -23,8 +21,7 @@ func main() {
 	classMap := createClassMap()
 
 	// Add to ClassMapStr for other operations
-	maps.Copy(twerge.ClassMapStr, classMap)
-	twerge.GenClassMergeStr = twerge.ClassMapStr
+	twerge.RegisterClasses(classMap)
 
 	// Step 2: Generate the input CSS file for Tailwind CLI
 	fmt.Println("Generating input CSS file...")
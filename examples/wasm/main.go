@@ -0,0 +1,41 @@
+//go:build js && wasm
+
+// Package main runs twerge in the browser.
+//
+// It exposes a twerge object to JavaScript, so class strings of HTML
+// fragments swapped in by htmx are merged with the same logic as on the
+// server.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/conneroisu/twerge"
+)
+
+func main() {
+	js.Global().Set("twerge", js.ValueOf(map[string]any{
+		// merge("p-2 p-4") returns "p-4"
+		"merge": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			if len(args) == 0 {
+				return ""
+			}
+			return twerge.Merge(args[0].String())
+		}),
+		// className("p-2 p-4") returns the class name the server generates
+		// for the merged classes, or null with sequential naming, whose
+		// names can not be predicted
+		"className": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			if len(args) == 0 {
+				return js.Null()
+			}
+			className, ok := twerge.ClassName(twerge.Merge(args[0].String()))
+			if !ok {
+				return js.Null()
+			}
+			return className
+		}),
+	}))
+	// the functions must outlive main
+	select {}
+}
@@ -4,9 +4,11 @@
 package main
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/a-h/templ"
+	"github.com/conneroisu/twerge"
 )
 
 func main() {
@@ -19,5 +21,10 @@ func main() {
 func StartServer() error {
 	mux := http.NewServeMux()
 	mux.Handle("/", templ.Handler(MainView("Title", "Content")))
+	// the stylesheet is generated from the classes rendered so far, without
+	// writing a file or running a Tailwind build
+	mux.Handle("/twerge.css", twerge.NewCSSHandler(func(w io.Writer) error {
+		return twerge.WriteGeneratedCSS(w, twerge.WithStandaloneCSS())
+	}))
 	return http.ListenAndServe(":8080", mux)
 }
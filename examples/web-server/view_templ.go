@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.850
+// templ: version: v0.3.857
 package main
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -44,7 +44,7 @@ func MainView(title string, content string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><script src=\"https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4\"></script><style>\n\t\t\t\t/* Tailwind class styles would be included in your CSS build */\n\t\t\t\t.tw-header {\n\t\t\t\t\t@apply text-center bg-gray-900 text-gray-100 p-4;\n\t\t\t\t}\n\t\t\t\t.tw-paragraph {\n\t\t\t\t\t@apply text-center bg-blue-900 text-blue-100 p-4;\n\t\t\t\t}\n\t\t\t\t.tw-green-text {\n\t\t\t\t\t@apply text-center bg-green-900 text-green-100 p-4;\n\t\t\t\t}\n\t\t\t\t.tw-outro {\n\t\t\t\t\t@apply italic text-lg text-gray-600;\n\t\t\t\t}\n\t\t\t</style></head><body><!-- Using Merge: Original Tailwind classes are preserved -->")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><script src=\"https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4\"></script><!-- The rules of the classes generated with twerge.It, served by twerge.NewCSSHandler --><link rel=\"stylesheet\" href=\"/twerge.css\"><style>\n\t\t\t\t/* Predefined classes are included in your CSS build */\n\t\t\t\t.tw-outro {\n\t\t\t\t\t@apply italic text-lg text-gray-600;\n\t\t\t\t}\n\t\t\t</style></head><body><!-- Using Merge: Original Tailwind classes are preserved -->")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -117,7 +117,7 @@ func MainView(title string, content string) templ.Component {
 		var templ_7745c5c3_Var9 string
 		templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(title)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web-server/view.templ`, Line: 49, Col: 14}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web-server/view.templ`, Line: 42, Col: 14}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 		if templ_7745c5c3_Err != nil {
@@ -130,7 +130,7 @@ func MainView(title string, content string) templ.Component {
 		var templ_7745c5c3_Var10 string
 		templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(content)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web-server/view.templ`, Line: 50, Col: 15}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web-server/view.templ`, Line: 43, Col: 15}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 		if templ_7745c5c3_Err != nil {
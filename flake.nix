@@ -172,7 +172,7 @@
         hasher = buildGoModule {
           name = "hasher";
           src = ./cmd/hasher;
-          vendorHash = null;
+          vendorHash = "sha256-G/T9ZGr7Sy6K04nMovMTMV27VeXrxLEx4T2oo0iiZTs=";
           version = "0.0.1";
           subPackages = ["."];
         };
@@ -0,0 +1,86 @@
+package twerge
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzSeeds are class strings exercising modifiers, brackets and important
+// modifiers, including malformed ones
+var fuzzSeeds = []string{
+	"",
+	"!",
+	":",
+	"/",
+	"[",
+	"]",
+	"[abc]",
+	"[color:red]",
+	"hover:",
+	":p-4",
+	"p-2 p-4",
+	"hover:focus:!p-4 p-2",
+	"bg-red-500/50 bg-[url(/a.png)]",
+	"[&>*]:p-4 [&>*]:p-2",
+	"not-supports-(display:grid):flex",
+	"p-4! -mt-2 mt-4",
+	"text-[length:var(--x)] text-lg",
+	"  flex \t items-center\n",
+	"é ü:p-4 \x00",
+}
+
+func FuzzMerge(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	merge := createTwMerge(defaultConfig, nil, nil)
+	f.Fuzz(func(t *testing.T, classes string) {
+		merged := merge(classes)
+		if utf8.ValidString(classes) && !utf8.ValidString(merged) {
+			t.Fatalf("merge of valid UTF-8 %q is not valid: %q", classes, merged)
+		}
+		// merging is idempotent
+		if again := merge(merged); again != merged {
+			t.Fatalf("merge(%q) = %q, merging again gives %q", classes, merged, again)
+		}
+		// every merged class was in the input
+		input := strings.Fields(classes)
+		for _, class := range strings.Fields(merged) {
+			if !slices.Contains(input, class) {
+				t.Fatalf("merge(%q) = %q has class %q not in the input", classes, merged, class)
+			}
+		}
+	})
+}
+
+func FuzzSplitModifiers(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	splitModifiers := makeSplitModifiers(defaultConfig)
+	getClassGroupID := makeGetClassGroupID(defaultConfig)
+	f.Fuzz(func(t *testing.T, class string) {
+		baseClass, modifiers, _, postFixMod := splitModifiers(class)
+		if postFixMod >= len(baseClass) {
+			t.Fatalf("postfix modifier position %d beyond %q", postFixMod, baseClass)
+		}
+		if len(baseClass)+len(strings.Join(modifiers, ":")) > len(class) {
+			t.Fatalf("split of %q is longer than the class: %q %q", class, baseClass, modifiers)
+		}
+		resolveClassGroup(defaultConfig, getClassGroupID, baseClass, postFixMod)
+	})
+}
+
+func TestMergeInvariants(t *testing.T) {
+	for _, classes := range append(fuzzSeeds, "p-2 p-4 m-1 hover:bg-red-500 hover:bg-blue-500 text-sm text-lg") {
+		merged := Merge(classes)
+		assert.Equal(t, merged, Merge(merged), "idempotent for %q", classes)
+		// whitespace between classes does not matter
+		spaced := "\n\t " + strings.Join(strings.Fields(classes), " \n\t") + "\n"
+		assert.Equal(t, merged, Merge(spaced), "whitespace stable for %q", classes)
+	}
+}
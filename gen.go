@@ -1,52 +1,31 @@
 package twerge
 
 import (
-	"fmt"
-	"strings"
-
+	"context"
 	"maps"
-
-	"github.com/dave/jennifer/jen"
 )
 
 // classMap is a mapping of original class strings to generated class names
 type classMap map[string]string
 
-var (
-	// cache for generated classes
-	genCache = newCache(1000)
-)
-
-// It returns a short unique CSS class name from the merged classes.
-//
-// If the class name already exists, it will return the existing class name.
+// RuntimeGenerate returns a class name for classes.
 //
-// If the class name does not exist, it will generate a new class name and return it.
-func It(classes string) string {
-	if className, exists := ClassMapStr[classes]; exists {
-		return className
-	}
-	// First check if a class name exists in ClassMapStr
-	mapMutex.RLock()
-	if className, exists := ClassMapStr[classes]; exists {
-		mapMutex.RUnlock()
-		return className
-	}
-	mapMutex.RUnlock()
-
-	// First, merge the classes
-	merged := Merge(classes)
-
-	// Store the mapping
-	mapMutex.Lock()
-	classname := fmt.Sprintf("tw-%d", classID)
-	ClassMapStr[classes] = classname
-	GenClassMergeStr[classname] = merged
-	genCache.Set(merged, classname)
-	classID++
-	mapMutex.Unlock()
+// It is equivalent to It, or to It of the Canonical classes if
+// Config.CanonicalKeys is set, and counts the call in UsageReport.
+func RuntimeGenerate(classes string) string {
+	key := runtimeKey(classes)
+	countUsage(key)
+	return It(key)
+}
 
-	return classname
+// RuntimeGenerateContext returns a class name for classes.
+//
+// It is equivalent to ItContext, or to ItContext of the Canonical classes if
+// Config.CanonicalKeys is set, and counts the call in UsageReport.
+func RuntimeGenerateContext(ctx context.Context, classes string) string {
+	key := runtimeKey(classes)
+	countUsage(key)
+	return ItContext(ctx, key)
 }
 
 // If returns the class name if the condition is true, otherwise it returns the second class name.
@@ -59,47 +38,75 @@ func If(conf bool, trueClass, falseClass string) string {
 	return It(falseClass)
 }
 
-func getMapping() classMap {
-	mapMutex.RLock()
-	defer mapMutex.RUnlock()
-
-	// Create a copy to avoid concurrent map access issues
-	mapping := make(classMap, len(ClassMapStr))
-	maps.Copy(mapping, ClassMapStr)
-
-	return mapping
+// ItCritical is like It but also marks the generated class as critical.
+//
+// Critical classes are emitted by WriteCriticalCSS and skipped by
+// WriteDeferredCSS, so they can be inlined while the rest is lazy-loaded.
+func ItCritical(classes string) string {
+	className := It(classes)
+	markCritical(className)
+	return className
 }
 
-// GenerateClassMapCode generates Go code for a variable containing the class mapping
-func GenerateClassMapCode(packageName string) string {
-	mapping := getMapping()
-
-	// Create a new file
-	f := jen.NewFile(packageName)
+// RegisterClasses registers a mapping of original class strings to class names.
+//
+// Each original class string is merged and stored in ClassMapStr and
+// GenClassMergeStr so that It returns the registered name.
+func RegisterClasses(classes map[string]string) {
+	merged := make(map[string]string, len(classes))
+	for original := range classes {
+		merged[original] = Merge(original)
+	}
 
-	// Add a package comment
-	f.PackageComment("Code generated by twerge. DO NOT EDIT.")
+	mapMutex.Lock()
+	defer mapMutex.Unlock()
+	registerClasses(ClassMapStr, GenClassMergeStr, classes, merged)
+	mapVersion.Add(1)
+	publishClassMap()
+}
 
-	// Create the ClassMapStr variable
-	f.Var().Id("ClassMapStr").Op("=").Map(jen.String()).String().Values(jen.DictFunc(func(d jen.Dict) {
-		// Sort keys for deterministic output
-		var keys []string
-		for k := range mapping {
-			keys = append(keys, k)
+// registerClasses adds classes to classMap and their merged classes to
+// generated. The names the class strings had before, generated or
+// registered, are dropped from generated unless other class strings use them.
+func registerClasses(classMap, generated, classes, merged map[string]string) {
+	var replaced []string
+	for original, className := range classes {
+		if previous, ok := classMap[original]; ok && previous != className {
+			replaced = append(replaced, previous)
 		}
-
-		// Add each key-value pair
-		for _, k := range keys {
-			d[jen.Lit(k)] = jen.Lit(mapping[k])
+		classMap[original] = className
+		generated[className] = merged[original]
+	}
+	if len(replaced) == 0 {
+		return
+	}
+	used := make(map[string]bool, len(classMap))
+	for _, className := range classMap {
+		used[className] = true
+	}
+	for _, className := range replaced {
+		if !used[className] {
+			delete(generated, className)
 		}
-	}))
+	}
+}
 
-	// Generate the code
-	buf := &strings.Builder{}
-	err := f.Render(buf)
-	if err != nil {
-		return "// Error generating code: " + err.Error()
+// RegisterCriticalClasses is like RegisterClasses but also marks every
+// registered class name as critical.
+func RegisterCriticalClasses(classes map[string]string) {
+	RegisterClasses(classes)
+	for _, className := range classes {
+		markCritical(className)
 	}
+}
+
+func getMapping() classMap {
+	mapMutex.RLock()
+	defer mapMutex.RUnlock()
 
-	return buf.String()
+	// Create a copy to avoid concurrent map access issues
+	mapping := make(classMap, len(ClassMapStr))
+	maps.Copy(mapping, ClassMapStr)
+
+	return mapping
 }
@@ -0,0 +1,28 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	// Reset the class map for testing
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+
+	// Test that Generate creates a consistent class name for the same input
+	class1 := It("text-red-500 bg-blue-500")
+	class2 := It("text-red-500 bg-blue-500")
+	assert.Equal(t, class1, class2, "Generate should return the same class name for the same input")
+
+	// Test that Generate handles class merging correctly
+	class3 := It("text-red-500 text-blue-700")
+	assert.NotEqual(t, class1, class3, "Generate should return different class names for different inputs")
+
+	// Test that the generated class name format is correct
+	assert.True(t, strings.HasPrefix(class1, "tw-"), "Generated class should start with 'tw-'")
+}
@@ -1,45 +1,20 @@
 package twerge
 
 import (
-	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGenerate(t *testing.T) {
-	// Reset the class map for testing
-	mapMutex.Lock()
-	ClassMapStr = make(map[string]string)
-	mapMutex.Unlock()
-
-	// Test that Generate creates a consistent class name for the same input
-	class1 := It("text-red-500 bg-blue-500")
-	class2 := It("text-red-500 bg-blue-500")
-	assert.Equal(t, class1, class2, "Generate should return the same class name for the same input")
-
-	// Test that Generate handles class merging correctly
-	class3 := It("text-red-500 text-blue-700")
-	assert.NotEqual(t, class1, class3, "Generate should return different class names for different inputs")
-
-	// Test that the generated class name format is correct
-	assert.True(t, strings.HasPrefix(class1, "tw-"), "Generated class should start with 'tw-'")
-}
-
 func TestGetMapping(t *testing.T) {
 	// Reset the class map for testing
-	mapMutex.Lock()
-	ClassMapStr = make(map[string]string)
-	mapMutex.Unlock()
-
-	// Generate some class names and store them directly in the map for testing
 	class1 := "tw-abcdefg"
 	class2 := "tw-hijklmn"
-
-	mapMutex.Lock()
-	ClassMapStr["text-red-500 bg-blue-500"] = class1
-	ClassMapStr["text-green-300 p-4"] = class2
-	mapMutex.Unlock()
+	SetMapping(map[string]string{
+		"text-red-500 bg-blue-500": class1,
+		"text-green-300 p-4":       class2,
+	})
+	t.Cleanup(func() { SetMapping(nil) })
 
 	// Get the mapping
 	mapping := getMapping()
@@ -50,22 +25,35 @@ func TestGetMapping(t *testing.T) {
 	assert.Equal(t, 2, len(mapping), "Mapping should contain 2 entries")
 }
 
-func TestGenerateClassMapCode(t *testing.T) {
-	// Reset the class map for testing
-	mapMutex.Lock()
-	ClassMapStr = make(map[string]string)
+func TestRegisterClassesReplacesGeneratedName(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
 
-	// Store directly in the map for testing
-	ClassMapStr["text-red-500 bg-blue-500"] = "tw-abcdefg"
-	ClassMapStr["text-green-300 p-4"] = "tw-hijklmn"
-	mapMutex.Unlock()
+	// an uncached class string merging to a different value
+	RegisterClasses(map[string]string{"pt-3 gap-x-1 pt-5": "tw-registered"})
 
-	// Generate the code
-	code := GenerateClassMapCode("twerge")
+	assert.Equal(t, map[string]string{"pt-3 gap-x-1 pt-5": "tw-registered"}, ClassMapStr)
+	assert.Len(t, GenClassMergeStr, 1)
+	assert.Equal(t, "gap-x-1 pt-5", GenClassMergeStr["tw-registered"])
+}
 
-	// Check that the code contains the expected content
-	assert.True(t, strings.Contains(code, "package twerge"), "Generated code should contain package declaration")
-	assert.True(t, strings.Contains(code, "ClassMapStr"), "Generated code should contain ClassMapStr variable")
-	assert.True(t, strings.Contains(code, `"text-red-500 bg-blue-500"`), "Generated code should contain the original class strings")
-	assert.True(t, strings.Contains(code, `"text-green-300 p-4"`), "Generated code should contain the original class strings")
+func TestRegisterClassesReplacesPreviousName(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+
+	// a class string named by It
+	generated := It("p-2 p-4 m-1")
+	RegisterClasses(map[string]string{"p-2 p-4 m-1": "tw-btn"})
+	snap := TakeSnapshot()
+	assert.Equal(t, map[string]string{"p-2 p-4 m-1": "tw-btn"}, snap.ClassMap)
+	assert.NotContains(t, snap.Rules, generated)
+	assert.Equal(t, "p-4 m-1", snap.Rules["tw-btn"])
+
+	// names still used by other class strings are kept
+	RegisterClasses(map[string]string{"flex": "tw-shared", "flex flex": "tw-shared"})
+	RegisterClasses(map[string]string{"flex": "tw-flex"})
+	snap = TakeSnapshot()
+	assert.Equal(t, "tw-shared", snap.ClassMap["flex flex"])
+	assert.Equal(t, "flex", snap.Rules["tw-shared"])
+	assert.Equal(t, "flex", snap.Rules["tw-flex"])
 }
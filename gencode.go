@@ -0,0 +1,299 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/dave/jennifer/jen"
+)
+
+// CodeOption configures the Go code of GenerateClassMapCode.
+type CodeOption func(*codeOptions)
+
+type codeOptions struct {
+	buildTag   string
+	classMap   string
+	mergedName string
+	// nextClass is the next sequential class name, recorded in the header
+	nextClass string
+}
+
+// WithBuildTag adds a //go:build line with constraint to the generated file,
+// like WithBuildTag("!dev").
+func WithBuildTag(constraint string) CodeOption {
+	return func(o *codeOptions) {
+		o.buildTag = constraint
+	}
+}
+
+// WithVarNames names the variables of the class map and the merged classes
+// of every class name, ClassMapStr and GenClassMergeStr by default.
+func WithVarNames(classMap, merged string) CodeOption {
+	return func(o *codeOptions) {
+		o.classMap = classMap
+		o.mergedName = merged
+	}
+}
+
+// withNextClass records className as the next sequential class name in the
+// header of the generated file, see readNextClassID.
+func withNextClass(className string) CodeOption {
+	return func(o *codeOptions) {
+		o.nextClass = className
+	}
+}
+
+// GenerateClassMapCode generates Go code for a variable containing the class
+// mapping, and one containing the merged classes of every class name.
+//
+// The generated file has a checksum header, see ClassMapCodeChecksum.
+func GenerateClassMapCode(packageName string, opts ...CodeOption) string {
+	return generateClassMapCode(takeSnapshot(), packageName, false, opts)
+}
+
+// GenerateRegisteredClassMapCode is like GenerateClassMapCode, but the
+// generated file also registers the class mapping with RegisterClasses on init,
+// so It returns the generated class names without computing them at runtime.
+func GenerateRegisteredClassMapCode(packageName string, opts ...CodeOption) string {
+	return generateClassMapCode(takeSnapshot(), packageName, true, opts)
+}
+
+const (
+	// checksumComment starts the checksum line of generated class maps
+	checksumComment = "twerge:checksum "
+	// emptyChecksum stands in for the checksum while it is computed
+	emptyChecksum = "0000000000000000"
+)
+
+// checksumRegex matches the checksum line of generated class maps
+var checksumRegex = regexp.MustCompile(`(?m)^// ` + checksumComment + `([0-9a-f]{16})$`)
+
+// nextClassComment starts the line of generated class maps holding the next
+// sequential class name
+const nextClassComment = "twerge:next-class "
+
+// nextClassRegex matches the next class line of generated class maps
+var nextClassRegex = regexp.MustCompile(`(?m)^// ` + nextClassComment + `(\S+)$`)
+
+// readNextClassID returns the number of the next sequential class name with
+// prefix recorded in code, or of the one after the highest class name of
+// classMap with prefix, whichever is larger, so names freed by pruning the
+// class map are not given to other classes.
+func readNextClassID(code []byte, classMap map[string]string, prefix string) int {
+	next := 0
+	id := func(className string) (int, bool) {
+		digits, ok := strings.CutPrefix(className, prefix)
+		if !ok {
+			return 0, false
+		}
+		n, err := strconv.Atoi(digits)
+		return n, err == nil && n >= 0
+	}
+	if m := nextClassRegex.FindSubmatch(code); m != nil {
+		if n, ok := id(string(m[1])); ok {
+			next = n
+		}
+	}
+	for _, className := range classMap {
+		if n, ok := id(className); ok {
+			next = max(next, n+1)
+		}
+	}
+	return next
+}
+
+// ClassMapCodeChecksum returns the checksum in the header of code generated
+// by GenerateClassMapCode, and whether code still matches it.
+//
+// Equal checksums mean equal files, so a generator can skip rewriting an up
+// to date file, and a mismatch means the file was edited after it was
+// generated. It returns an empty checksum for code without one.
+func ClassMapCodeChecksum(code []byte) (checksum string, ok bool) {
+	m := checksumRegex.FindSubmatchIndex(code)
+	if m == nil {
+		return "", false
+	}
+	checksum = string(code[m[2]:m[3]])
+	unsummed := slices.Concat(code[:m[2]], []byte(emptyChecksum), code[m[3]:])
+	return checksum, codeChecksum(unsummed) == checksum
+}
+
+// ReadClassMapCode returns the class map of code generated by
+// GenerateClassMapCode, the first map[string]string variable of the file.
+func ReadClassMapCode(code []byte) (map[string]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ValueSpec)
+			if len(spec.Values) != 1 {
+				continue
+			}
+			lit, ok := spec.Values[0].(*ast.CompositeLit)
+			if !ok || types.ExprString(lit.Type) != "map[string]string" {
+				continue
+			}
+			return readStringMap(lit)
+		}
+	}
+	return nil, errors.New("no class map found")
+}
+
+// readStringMap returns the entries of a map literal with string literal keys
+// and values.
+func readStringMap(lit *ast.CompositeLit) (map[string]string, error) {
+	m := make(map[string]string, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, fmt.Errorf("unexpected element %s", types.ExprString(elt))
+		}
+		key, ok := kv.Key.(*ast.BasicLit)
+		value, ok2 := kv.Value.(*ast.BasicLit)
+		if !ok || !ok2 || key.Kind != token.STRING || value.Kind != token.STRING {
+			return nil, fmt.Errorf("unexpected entry %s: %s", types.ExprString(kv.Key), types.ExprString(kv.Value))
+		}
+		k, err := strconv.Unquote(key.Value)
+		if err != nil {
+			return nil, err
+		}
+		v, err := strconv.Unquote(value.Value)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// codeChecksum returns the checksum of code with an empty checksum line.
+func codeChecksum(code []byte) string {
+	return fmt.Sprintf("%016x", xxhash.Sum64(code))
+}
+
+// generateClassMapCode generates the code of the class map and merged
+// classes of snap. If register is true, an init function registers the
+// mapping with RegisterClasses.
+func generateClassMapCode(snap Snapshot, packageName string, register bool, opts []CodeOption) string {
+	o := codeOptions{classMap: "ClassMapStr", mergedName: "GenClassMergeStr"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Create a new file
+	f := jen.NewFile(packageName)
+	if o.buildTag != "" {
+		f.HeaderComment("//go:build " + o.buildTag)
+	}
+
+	// Add a package comment
+	f.PackageComment("Code generated by twerge. DO NOT EDIT.")
+	f.PackageComment(checksumComment + emptyChecksum)
+	if o.nextClass != "" {
+		f.PackageComment(nextClassComment + o.nextClass)
+	}
+
+	// Create the class map variables, sorted for deterministic output
+	f.Var().Id(o.classMap).Op("=").Map(jen.String()).String().Values(jen.DictFunc(func(d jen.Dict) {
+		for _, k := range SortedKeys(snap.ClassMap) {
+			d[jen.Lit(k)] = jen.Lit(snap.ClassMap[k])
+		}
+	}))
+	f.Var().Id(o.mergedName).Op("=").Map(jen.String()).String().Values(jen.DictFunc(func(d jen.Dict) {
+		for _, k := range SortedKeys(snap.Rules) {
+			d[jen.Lit(k)] = jen.Lit(snap.Rules[k])
+		}
+	}))
+
+	if register {
+		f.Func().Id("init").Params().Block(
+			jen.Qual("github.com/conneroisu/twerge", "RegisterClasses").Call(jen.Id(o.classMap)),
+		)
+	}
+
+	// Generate the code
+	buf := &bytes.Buffer{}
+	err := f.Render(buf)
+	if err != nil {
+		return "// Error generating code: " + err.Error()
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "// Error generating code: " + err.Error()
+	}
+
+	placeholder := []byte("// " + checksumComment + emptyChecksum)
+	checksum := []byte("// " + checksumComment + codeChecksum(code))
+	return string(bytes.Replace(code, placeholder, checksum, 1))
+}
+
+// GenerateComponentStylesCode generates Go code declaring a styles struct per
+// registered component, with a field holding the class name of every part:
+//
+//	var CardStyles = struct {
+//		Header string
+//		Root   string
+//	}{
+//		Header: "card-header",
+//		Root:   "card-root",
+//	}
+//
+// templ components can then reference CardStyles.Header instead of loose
+// class name constants.
+func GenerateComponentStylesCode(packageName string) string {
+	mapMutex.RLock()
+	components := make(map[string]map[string]string, len(componentParts))
+	for component, parts := range componentParts {
+		components[component] = maps.Clone(parts)
+	}
+	mapMutex.RUnlock()
+
+	f := jen.NewFile(packageName)
+	f.PackageComment("Code generated by twerge. DO NOT EDIT.")
+
+	for _, component := range sortedComponentKeys(components) {
+		parts := components[component]
+		fields := make([]jen.Code, 0, len(parts))
+		values := jen.Dict{}
+		for _, part := range SortedKeys(parts) {
+			fields = append(fields, jen.Id(exportedIdentifier(part)).String())
+			values[jen.Id(exportedIdentifier(part))] = jen.Lit(parts[part])
+		}
+		f.Commentf("%sStyles holds the class names of the %s component", exportedIdentifier(component), component)
+		f.Var().Id(exportedIdentifier(component) + "Styles").Op("=").Struct(fields...).Values(values)
+	}
+
+	buf := &strings.Builder{}
+	err := f.Render(buf)
+	if err != nil {
+		return "// Error generating code: " + err.Error()
+	}
+	return buf.String()
+}
+
+// GenerateClassMapCode generates Go code for a ClassMapStr variable holding
+// the class map of m, registered with RegisterClasses on init, and a
+// GenClassMergeStr variable holding its merged classes.
+func (m *Merger) GenerateClassMapCode(packageName string, opts ...CodeOption) string {
+	return generateClassMapCode(m.Snapshot(), packageName, true, opts)
+}
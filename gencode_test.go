@@ -0,0 +1,89 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateComponentStylesCode(t *testing.T) {
+	resetComponents(t)
+
+	names, err := RegisterComponent("Card", map[string]string{
+		"Root":   "rounded-lg border p-4",
+		"Header": "text-lg font-bold",
+	})
+	assert.NoError(t, err)
+	_, err = RegisterComponent("nav-bar", map[string]string{"Link": "px-2"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"Root": "tw-card-root", "Header": "tw-card-header"}, names)
+	assert.Equal(t, "tw-card-root", It("rounded-lg border p-4"))
+
+	assert.Equal(t, `// Code generated by twerge. DO NOT EDIT.
+package components
+
+// CardStyles holds the class names of the Card component
+var CardStyles = struct {
+	Header string
+	Root   string
+}{
+	Header: "tw-card-header",
+	Root:   "tw-card-root",
+}
+
+// NavBarStyles holds the class names of the nav-bar component
+var NavBarStyles = struct {
+	Link string
+}{Link: "tw-nav-bar-link"}
+`, GenerateComponentStylesCode("components"))
+}
+
+func TestGenerateClassMapCode(t *testing.T) {
+	// Reset the class map for testing
+	SetMapping(map[string]string{
+		"text-red-500 bg-blue-500": "tw-abcdefg",
+		"text-green-300 p-4":       "tw-hijklmn",
+	})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	// Generate the code
+	code := GenerateClassMapCode("twerge")
+
+	// Check that the code contains the expected content
+	assert.True(t, strings.Contains(code, "package twerge"), "Generated code should contain package declaration")
+	assert.True(t, strings.Contains(code, "ClassMapStr"), "Generated code should contain ClassMapStr variable")
+	assert.True(t, strings.Contains(code, `"text-red-500 bg-blue-500"`), "Generated code should contain the original class strings")
+	assert.True(t, strings.Contains(code, `"text-green-300 p-4"`), "Generated code should contain the original class strings")
+}
+
+func TestGenerateClassMapCodeOptions(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-a"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	code := GenerateClassMapCode("classes", WithBuildTag("!dev"), WithVarNames("Classes", "Merged"))
+	assert.True(t, strings.HasPrefix(code, "//go:build !dev\n\n"))
+	assert.Regexp(t, `var Classes = map\[string\]string\{"p-2 p-4": "tw-a"\}`, code)
+	assert.Regexp(t, `var Merged = map\[string\]string\{"tw-a": "p-4"\}`, code)
+	assert.NotContains(t, code, "ClassMapStr")
+
+	// the checksum changes with the code and detects edits
+	checksum, ok := ClassMapCodeChecksum([]byte(code))
+	assert.True(t, ok)
+	assert.Len(t, checksum, 16)
+	assert.Equal(t, code, GenerateClassMapCode("classes", WithBuildTag("!dev"), WithVarNames("Classes", "Merged")))
+	other, _ := ClassMapCodeChecksum([]byte(GenerateClassMapCode("classes")))
+	assert.NotEqual(t, checksum, other)
+
+	edited := strings.Replace(code, `"tw-a": "p-4"`, `"tw-a": "p-2"`, 1)
+	checksum, ok = ClassMapCodeChecksum([]byte(edited))
+	assert.False(t, ok)
+	assert.Len(t, checksum, 16)
+
+	checksum, ok = ClassMapCodeChecksum([]byte("package classes\n"))
+	assert.Empty(t, checksum)
+	assert.False(t, ok)
+}
@@ -0,0 +1,355 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/conneroisu/twerge/scan"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// GenOptions configures GenerateAll.
+type GenOptions struct {
+	// Dirs are the directories scanned for class strings, the current
+	// directory if empty
+	Dirs []string
+	// GoFile is the Go file the class map is written to, registering it with
+	// RegisterClasses on init, none if empty and ShardFile is set
+	GoFile string
+	// ShardFile, if not empty, is the name of the Go files the class map is
+	// split into, like "twerge_gen.go". A shard is written to every directory
+	// holding class strings, in the package of its files, and registers the
+	// class strings of the directory on init. Class names are unique across
+	// shards, and a class string used in several directories is registered
+	// by each of their shards.
+	ShardFile string
+	// Package is the package of GoFile, by default the package of the files
+	// next to it or the name of its directory
+	Package string
+	// CSSFile is the Tailwind input CSS whose twerge section is regenerated,
+	// skipped if empty
+	CSSFile string
+	// TemplFile is the .templ stub written with a TwergeStyles component
+	// rendering StyleTag, skipped if empty
+	TemplFile string
+	// Config configures merging and naming, DefaultConfig if nil
+	Config *Config
+	// CodeOptions configure the code of GoFile and the shards
+	CodeOptions []CodeOption
+	// Prune drops the class strings of the existing GoFile and shards that
+	// are no longer found in Dirs. Otherwise they are kept, with the class
+	// names of all class strings of the existing files, so names stay stable
+	// between generations and pages rendered with older sources keep their
+	// styles. Sequential names of pruned class strings are not reused.
+	Prune bool
+	// OnWarning, if not nil, is called with the warnings of Validate for
+	// every class string found. An error returned by it stops GenerateAll
+	// before any file is written.
+	OnWarning func(classes string, warning Warning) error
+}
+
+// GenerateAll scans the sources of opts.Dirs, merges the class strings found
+// and writes the Go class map, the input CSS and the .templ stub of opts, so
+// a project needs a single line to keep them in sync:
+//
+//	//go:generate go run github.com/conneroisu/twerge/cmd/twerge gen
+//
+// The class strings of an existing GoFile keep their class names, and new
+// class strings are named in their sorted order, so the same sources always
+// produce the same files. A Go file that is already up to date is not
+// rewritten, see ClassMapCodeChecksum.
+func GenerateAll(opts GenOptions) error {
+	files, err := generateFiles(opts)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		dir := filepath.Dir(f.path)
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %w", dir, err)
+		}
+		if f.checksummed {
+			err = writeGenerated(f.path, string(f.content))
+		} else {
+			err = writeTargets(Snapshot{}, []Target{{
+				Path: f.path,
+				Render: func(Snapshot) ([]byte, error) {
+					return f.content, nil
+				},
+			}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAll generates the files of GenerateAll in memory, without writing
+// them, and returns the unified diff of the files on disk to the generated
+// ones, empty if every file is up to date. CI can fail on a non-empty diff
+// to enforce that committed generated files match their sources:
+//
+//	go run github.com/conneroisu/twerge/cmd/twerge gen -check
+func CheckAll(opts GenOptions) (string, error) {
+	files, err := generateFiles(opts)
+	if err != nil {
+		return "", err
+	}
+	var diff strings.Builder
+	for _, f := range files {
+		current, err := os.ReadFile(f.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("error reading %s: %w", f.path, err)
+		}
+		if bytes.Equal(current, f.content) {
+			continue
+		}
+		udiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        diffLines(current),
+			B:        diffLines(f.content),
+			FromFile: f.path,
+			ToFile:   f.path + " (generated)",
+			Context:  3,
+		})
+		if err != nil {
+			return "", err
+		}
+		diff.WriteString(udiff)
+	}
+	return diff.String(), nil
+}
+
+// diffLines splits content into lines ending in "\n", the last one unless
+// content lacks a final newline.
+func diffLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// genFile is a file generated by GenerateAll
+type genFile struct {
+	path    string
+	content []byte
+	// checksummed files are only rewritten if their checksum changed
+	checksummed bool
+}
+
+// generateFiles returns the files GenerateAll writes for opts.
+func generateFiles(opts GenOptions) ([]genFile, error) {
+	if opts.GoFile == "" && opts.ShardFile == "" {
+		return nil, errors.New("twerge: GenerateAll needs a GoFile or a ShardFile")
+	}
+	dirs := opts.Dirs
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var occurrences []scan.Occurrence
+	for _, dir := range dirs {
+		found, err := scan.Dir(dir)
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, found...)
+	}
+	classes := scan.Classes(occurrences)
+	if opts.OnWarning != nil {
+		for _, c := range classes {
+			for _, warning := range Validate(c) {
+				if err := opts.OnWarning(c, warning); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	conf := opts.Config
+	if conf == nil {
+		conf = DefaultConfig()
+	}
+	// the class strings of every shard, by the path of the shard
+	shards := make(map[string]map[string]bool)
+	if opts.ShardFile != "" {
+		for _, o := range occurrences {
+			path := filepath.Join(filepath.Dir(o.File), opts.ShardFile)
+			if shards[path] == nil {
+				shards[path] = make(map[string]bool)
+			}
+			shards[path][o.Classes] = true
+		}
+	}
+	previous := make(map[string]string)
+	nextID := 0
+	for _, path := range append(slices.Sorted(maps.Keys(shards)), opts.GoFile) {
+		if path == "" {
+			continue
+		}
+		classMap, next, err := readPreviousClassMap(path, conf)
+		if err != nil {
+			return nil, err
+		}
+		nextID = max(nextID, next)
+		for c := range classMap {
+			if _, found := slices.BinarySearch(classes, c); opts.Prune && !found {
+				delete(classMap, c)
+			} else if shards[path] != nil && !opts.Prune {
+				shards[path][c] = true
+			}
+		}
+		maps.Copy(previous, classMap)
+	}
+	m := New(conf)
+	m.RegisterClasses(previous)
+	// pruned sequential names are not given to other classes, as stylesheets
+	// and pages cached before the prune still use them
+	m.classID = max(m.classID, nextID)
+	for _, c := range classes {
+		m.Generate(c)
+	}
+	snap := m.Snapshot()
+	codeOptions := opts.CodeOptions
+	if naming := conf.naming(); naming.sequential() {
+		codeOptions = append(slices.Clip(codeOptions), withNextClass(naming.prefix+strconv.Itoa(m.classID)))
+	}
+
+	var files []genFile
+	pkgName := opts.Package
+	if pkgName == "" && opts.GoFile != "" {
+		pkgName = goPackageName(filepath.Dir(opts.GoFile))
+	}
+	if opts.GoFile != "" {
+		files = append(files, genFile{
+			path:        opts.GoFile,
+			content:     []byte(generateClassMapCode(snap, pkgName, true, codeOptions)),
+			checksummed: true,
+		})
+	}
+	for _, path := range slices.Sorted(maps.Keys(shards)) {
+		shard := Snapshot{ClassMap: make(map[string]string), Rules: make(map[string]string)}
+		for c := range shards[path] {
+			className := snap.ClassMap[c]
+			shard.ClassMap[c] = className
+			shard.Rules[className] = snap.Rules[className]
+		}
+		pkgName := goPackageName(filepath.Dir(path))
+		files = append(files, genFile{
+			path:        path,
+			content:     []byte(generateClassMapCode(shard, pkgName, true, codeOptions)),
+			checksummed: true,
+		})
+	}
+
+	if opts.CSSFile != "" {
+		target, err := tailwindTarget(opts.CSSFile, renderRules)
+		if err != nil {
+			return nil, err
+		}
+		var css bytes.Buffer
+		err = target.Write(&css, snap)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, genFile{path: opts.CSSFile, content: css.Bytes()})
+	}
+
+	if opts.TemplFile != "" {
+		templDir := filepath.Dir(opts.TemplFile)
+		templPkg, err := dirPackageName(templDir)
+		if err != nil {
+			templPkg = cmp.Or(pkgName, goPackageName(templDir))
+		}
+		files = append(files, genFile{path: opts.TemplFile, content: []byte(templStub(templPkg))})
+	}
+	return files, nil
+}
+
+// readPreviousClassMap returns the class map of the Go file at path written
+// by GenerateAll, without the class names lacking the class prefix of conf,
+// and the number of the next sequential class name, or nil if there is no
+// file.
+func readPreviousClassMap(path string, conf *Config) (map[string]string, int, error) {
+	code, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	previous, err := ReadClassMapCode(code)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading the class map of %s: %w", path, err)
+	}
+	prefix := conf.naming().prefix
+	maps.DeleteFunc(previous, func(_, className string) bool {
+		return !strings.HasPrefix(className, prefix)
+	})
+	return previous, readNextClassID(code, previous, prefix), nil
+}
+
+// writeGenerated writes the generated Go code to path unless the file there
+// has the same checksum, as rewriting it would only retrigger builds.
+func writeGenerated(path, code string) error {
+	if existing, err := os.ReadFile(path); err == nil {
+		checksum, ok := ClassMapCodeChecksum(existing)
+		generated, _ := ClassMapCodeChecksum([]byte(code))
+		if ok && checksum == generated {
+			return nil
+		}
+	}
+	err := os.WriteFile(path, []byte(code), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// goPackageName returns the package of the files in dir, or a package name
+// derived from the name of dir.
+func goPackageName(dir string) string {
+	if name, err := dirPackageName(dir); err == nil {
+		return name
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, filepath.Base(abs))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "classes"
+	}
+	return name
+}
+
+// templStub returns the .templ stub of GenerateAll in the package pkgName.
+func templStub(pkgName string) string {
+	return "// Code generated by twerge. DO NOT EDIT.\n\n" +
+		"package " + pkgName + "\n\n" +
+		"import \"github.com/conneroisu/twerge\"\n\n" +
+		"// TwergeStyles renders the <style> element of the generated classes.\n" +
+		"templ TwergeStyles() {\n" +
+		"\t@templ.Raw(string(twerge.StyleTag()))\n" +
+		"}\n"
+}
@@ -0,0 +1,199 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAll(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"views/page.templ": "package views\n\ntempl Page() {\n" +
+			"\t<div class={ twerge.It(\"p-2 p-4\") }></div>\n" +
+			"\t<span class=\"flex items-center\"></span>\n}\n",
+		"static/input.css": "@tailwind base;\n/* twerge:begin */\n/* twerge:end */\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	opts := GenOptions{
+		Dirs:      []string{filepath.Join(dir, "views")},
+		GoFile:    filepath.Join(dir, "classes", "classes_gen.go"),
+		CSSFile:   filepath.Join(dir, "static", "input.css"),
+		TemplFile: filepath.Join(dir, "views", "styles.templ"),
+	}
+	assert.NoError(t, GenerateAll(opts))
+
+	code, err := os.ReadFile(opts.GoFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), "package classes")
+	assert.Regexp(t, `"flex items-center":\s+"tw-0"`, string(code))
+	assert.Regexp(t, `"p-2 p-4":\s+"tw-1"`, string(code))
+
+	rules, err := ReadTailwindSection(opts.CSSFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "p-4", rules["tw-1"])
+
+	stub, err := os.ReadFile(opts.TemplFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(stub), "package views")
+	assert.Contains(t, string(stub), "templ TwergeStyles() {")
+
+	// an up to date Go file is not rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(opts.GoFile, past, past))
+	assert.NoError(t, GenerateAll(opts))
+	info, err := os.Stat(opts.GoFile)
+	assert.NoError(t, err)
+	assert.Equal(t, past, info.ModTime())
+
+	assert.ErrorContains(t, GenerateAll(GenOptions{}), "needs a GoFile")
+}
+
+func TestGenerateAllPrune(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "views", "page.templ")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(page), 0755))
+	writePage := func(classes ...string) {
+		content := "package views\n\ntempl Page() {\n"
+		for _, c := range classes {
+			content += "\t<div class=\"" + c + "\"></div>\n"
+		}
+		assert.NoError(t, os.WriteFile(page, []byte(content+"}\n"), 0644))
+	}
+	opts := GenOptions{
+		Dirs:   []string{filepath.Join(dir, "views")},
+		GoFile: filepath.Join(dir, "classes", "classes_gen.go"),
+	}
+	classMap := func() map[string]string {
+		code, err := os.ReadFile(opts.GoFile)
+		assert.NoError(t, err)
+		m, err := ReadClassMapCode(code)
+		assert.NoError(t, err)
+		return m
+	}
+
+	writePage("flex", "p-4", "text-sm")
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"flex": "tw-0", "p-4": "tw-1", "text-sm": "tw-2"}, classMap())
+
+	// removed class strings are kept and added ones get new names
+	writePage("block", "p-4", "text-sm")
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"block": "tw-3", "flex": "tw-0", "p-4": "tw-1", "text-sm": "tw-2"}, classMap())
+
+	opts.Prune = true
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"block": "tw-3", "p-4": "tw-1", "text-sm": "tw-2"}, classMap())
+
+	// pruned names are not given to other classes
+	writePage("block", "p-4", "text-sm", "m-2")
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"block": "tw-3", "m-2": "tw-4", "p-4": "tw-1", "text-sm": "tw-2"}, classMap())
+	writePage("p-4", "text-sm", "m-2", "underline")
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"m-2": "tw-4", "p-4": "tw-1", "text-sm": "tw-2", "underline": "tw-5"}, classMap())
+	writePage("p-4", "text-sm", "m-2")
+	assert.NoError(t, GenerateAll(opts))
+	writePage("p-4", "text-sm", "m-2", "italic")
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"italic": "tw-6", "m-2": "tw-4", "p-4": "tw-1", "text-sm": "tw-2"}, classMap())
+
+	// names of another prefix are dropped
+	writePage("block", "p-4", "text-sm")
+	opts.Config = DefaultConfig()
+	opts.Config.ClassPrefix = "x-"
+	assert.NoError(t, GenerateAll(opts))
+	assert.Equal(t, map[string]string{"block": "x-0", "p-4": "x-1", "text-sm": "x-2"}, classMap())
+
+	assert.NoError(t, os.WriteFile(opts.GoFile, []byte("package classes\n"), 0644))
+	assert.ErrorContains(t, GenerateAll(opts), "no class map found")
+}
+
+func TestCheckAll(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "views", "page.templ")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(page), 0755))
+	assert.NoError(t, os.WriteFile(page, []byte("package views\n\ntempl Page() {\n\t<div class=\"flex\"></div>\n}\n"), 0644))
+	opts := GenOptions{
+		Dirs:    []string{filepath.Join(dir, "views")},
+		GoFile:  filepath.Join(dir, "classes", "classes_gen.go"),
+		CSSFile: filepath.Join(dir, "input.css"),
+	}
+
+	// missing files differ
+	diff, err := CheckAll(opts)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "--- "+opts.GoFile+"\n+++ "+opts.GoFile+" (generated)\n")
+	assert.Contains(t, diff, "@@ -0,0 +1,13 @@\n+// Code generated by twerge. DO NOT EDIT.\n")
+	assert.Contains(t, diff, "+var ClassMapStr = map[string]string{\"flex\": \"tw-0\"}\n")
+	_, err = os.Stat(opts.GoFile)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.NoError(t, GenerateAll(opts))
+	diff, err = CheckAll(opts)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	assert.NoError(t, os.WriteFile(page, []byte("package views\n\ntempl Page() {\n\t<div class=\"flex\"></div>\n\t<p class=\"p-4\"></p>\n}\n"), 0644))
+	diff, err = CheckAll(opts)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "+\t\"p-4\":  \"tw-1\",\n")
+	assert.Contains(t, diff, "--- "+opts.CSSFile+"\n+++ "+opts.CSSFile+" (generated)\n")
+	assert.Contains(t, diff, "+.tw-1 { \n+\t@apply p-4; \n+}\n")
+}
+
+func TestGenerateAllShards(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"views/users/list.templ": "package users\n\ntempl List() {\n" +
+			"\t<ul class=\"flex flex-col\"></ul>\n\t<div class=\"p-2 p-4\"></div>\n}\n",
+		"views/orders/table.templ": "package orders\n\ntempl Table() {\n" +
+			"\t<table class=\"w-full\"></table>\n\t<div class=\"p-2 p-4\"></div>\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	opts := GenOptions{
+		Dirs:      []string{filepath.Join(dir, "views")},
+		ShardFile: "twerge_gen.go",
+		Prune:     true,
+	}
+	assert.NoError(t, GenerateAll(opts))
+
+	shard := func(pkg string) (string, map[string]string) {
+		code, err := os.ReadFile(filepath.Join(dir, "views", pkg, "twerge_gen.go"))
+		assert.NoError(t, err)
+		classMap, err := ReadClassMapCode(code)
+		assert.NoError(t, err)
+		return string(code), classMap
+	}
+	code, classMap := shard("users")
+	assert.Contains(t, code, "package users")
+	assert.Contains(t, code, "twerge.RegisterClasses(ClassMapStr)")
+	assert.Equal(t, map[string]string{"flex flex-col": "tw-0", "p-2 p-4": "tw-1"}, classMap)
+	code, classMap = shard("orders")
+	assert.Contains(t, code, "package orders")
+	assert.Equal(t, map[string]string{"p-2 p-4": "tw-1", "w-full": "tw-2"}, classMap)
+	assert.Contains(t, code, `"tw-2": "w-full"`)
+	assert.NotContains(t, code, "flex-col")
+
+	// names stay stable across shards, and pruned names are not reused
+	files["views/users/list.templ"] = "package users\n\ntempl List() {\n\t<ul class=\"block\"></ul>\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "views/users/list.templ"), []byte(files["views/users/list.templ"]), 0644))
+	assert.NoError(t, GenerateAll(opts))
+	_, classMap = shard("users")
+	assert.Equal(t, map[string]string{"block": "tw-3"}, classMap)
+	_, classMap = shard("orders")
+	assert.Equal(t, map[string]string{"p-2 p-4": "tw-1", "w-full": "tw-2"}, classMap)
+}
@@ -0,0 +1,21 @@
+module github.com/conneroisu/twerge/gomponents
+
+go 1.24.1
+
+require (
+	github.com/conneroisu/twerge v0.0.0
+	github.com/stretchr/testify v1.10.0
+	maragu.dev/gomponents v1.1.0
+)
+
+require (
+	github.com/a-h/parse v0.0.0-20250122154542-74294addb73e // indirect
+	github.com/a-h/templ v0.3.857 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dave/jennifer v1.7.1 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/conneroisu/twerge => ../
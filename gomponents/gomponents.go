@@ -0,0 +1,40 @@
+// Package gomponents adapts twerge to gomponents.
+//
+// The helpers return class attribute nodes whose values went through the
+// twerge merger and registry, the same way twerge.It does for templ:
+//
+//	import (
+//		. "maragu.dev/gomponents/html"
+//		tw "github.com/conneroisu/twerge/gomponents"
+//	)
+//
+//	Div(tw.Classes("flex items-center", "p-2 p-4"), Text("Hello"))
+package gomponents
+
+import (
+	"strings"
+
+	"github.com/conneroisu/twerge"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+// Classes returns a class attribute holding the short generated class name
+// for the merged classes.
+//
+// Multiple arguments are joined with spaces before merging.
+func Classes(classes ...string) g.Node {
+	return h.Class(twerge.It(strings.Join(classes, " ")))
+}
+
+// ClassIf returns a class attribute for trueClasses if cond is true and for
+// falseClasses otherwise.
+func ClassIf(cond bool, trueClasses, falseClasses string) g.Node {
+	return h.Class(twerge.If(cond, trueClasses, falseClasses))
+}
+
+// MergedClasses returns a class attribute holding the merged Tailwind classes
+// instead of a generated class name.
+func MergedClasses(classes ...string) g.Node {
+	return h.Class(twerge.Merge(strings.Join(classes, " ")))
+}
@@ -0,0 +1,31 @@
+package gomponents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/twerge"
+	"github.com/stretchr/testify/assert"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+func render(t *testing.T, node g.Node) string {
+	t.Helper()
+	var b strings.Builder
+	assert.NoError(t, node.Render(&b))
+	return b.String()
+}
+
+func TestClasses(t *testing.T) {
+	twerge.RegisterClasses(map[string]string{
+		"flex items-center p-2 p-4": "tw-row",
+		"text-red-500":              "tw-error",
+		"text-green-500":            "tw-ok",
+	})
+
+	assert.Equal(t, `<div class="tw-row"></div>`, render(t, h.Div(Classes("flex items-center", "p-2 p-4"))))
+	assert.Equal(t, `<p class="tw-error"></p>`, render(t, h.P(ClassIf(false, "text-green-500", "text-red-500"))))
+	assert.Equal(t, `<p class="tw-ok"></p>`, render(t, h.P(ClassIf(true, "text-green-500", "text-red-500"))))
+	assert.Equal(t, `<div class="flex p-4"></div>`, render(t, h.Div(MergedClasses("flex p-2", "p-4"))))
+}
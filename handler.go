@@ -0,0 +1,268 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// CSSEncoding is a content encoding the CSSHandler precompresses the
+// stylesheet with.
+//
+// Brotli is not part of the standard library; it can be plugged in with e.g.
+// github.com/andybalholm/brotli:
+//
+//	twerge.CSSEncoding{
+//		Name: "br",
+//		NewWriter: func(w io.Writer) io.WriteCloser {
+//			return brotli.NewWriterLevel(w, brotli.BestCompression)
+//		},
+//	}
+type CSSEncoding struct {
+	// Name is the Content-Encoding token, e.g. "br" or "gzip"
+	Name string
+	// NewWriter returns a writer compressing into w
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+// GzipEncoding is the gzip CSSEncoding, always supported by CSSHandler.
+var GzipEncoding = CSSEncoding{
+	Name: "gzip",
+	NewWriter: func(w io.Writer) io.WriteCloser {
+		zw, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		return zw
+	},
+}
+
+// CSSHandler serves a generated stylesheet.
+//
+// The stylesheet and its compressed variants are regenerated once after every
+// change to the registered classes instead of on every request, and the
+// variant is picked by Accept-Encoding negotiation.
+//
+// Responses carry an ETag derived from the content of the stylesheet, so
+// browsers revalidating it get a 304 Not Modified until the classes change.
+type CSSHandler struct {
+	// CacheControl is the Cache-Control header of the responses, "no-cache"
+	// if empty, so browsers revalidate the stylesheet with its ETag. Use a
+	// max-age for stylesheets that rarely change.
+	CacheControl string
+
+	render    func(io.Writer) error
+	encodings []CSSEncoding
+
+	mu       sync.RWMutex
+	version  uint64
+	built    bool
+	identity []byte
+	variants map[string][]byte
+	// etag is the ETag of identity, the ETag of a variant has its encoding
+	// appended
+	etag string
+	err  error
+}
+
+// NewCSSHandler creates a CSSHandler serving the output of render.
+//
+// If render is nil, WriteGeneratedCSS is used. Encodings are preferred in the
+// given order, followed by gzip if it is not listed.
+func NewCSSHandler(render func(io.Writer) error, encodings ...CSSEncoding) *CSSHandler {
+	if render == nil {
+		render = func(w io.Writer) error { return WriteGeneratedCSS(w) }
+	}
+	hasGzip := false
+	for _, enc := range encodings {
+		if enc.Name == GzipEncoding.Name {
+			hasGzip = true
+		}
+	}
+	if !hasGzip {
+		encodings = append(encodings, GzipEncoding)
+	}
+	return &CSSHandler{
+		render:    render,
+		encodings: encodings,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *CSSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, encoding, etag, err := h.negotiate(r.Header.Get("Accept-Encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cacheControl := h.CacheControl
+	if cacheControl == "" {
+		cacheControl = "no-cache"
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// negotiate returns the best variant of the stylesheet for the given
+// Accept-Encoding header and its ETag, rebuilding the variants if the classes
+// changed.
+func (h *CSSHandler) negotiate(acceptEncoding string) ([]byte, string, string, error) {
+	err := h.refresh()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	accepted := parseAcceptEncoding(acceptEncoding)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, enc := range h.encodings {
+		if accepted[enc.Name] || (accepted["*"] && !rejected(accepted, enc.Name)) {
+			// variants are different representations, with ETags of
+			// their own
+			return h.variants[enc.Name], enc.Name, strings.TrimSuffix(h.etag, `"`) + "-" + enc.Name + `"`, nil
+		}
+	}
+	return h.identity, "", h.etag, nil
+}
+
+// refresh regenerates the stylesheet and its variants if the registered
+// classes changed since the last build.
+func (h *CSSHandler) refresh() error {
+	version := mapVersion.Load()
+
+	h.mu.RLock()
+	upToDate := h.built && h.version == version
+	err := h.err
+	h.mu.RUnlock()
+	if upToDate {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.built && h.version == version {
+		return h.err
+	}
+
+	h.version = version
+	h.built = true
+	h.err = nil
+
+	var buf bytes.Buffer
+	h.err = h.render(&buf)
+	if h.err != nil {
+		return h.err
+	}
+	h.identity = buf.Bytes()
+	h.etag = fmt.Sprintf(`"%016x"`, xxhash.Sum64(h.identity))
+	h.variants = make(map[string][]byte, len(h.encodings))
+	for _, enc := range h.encodings {
+		var compressed bytes.Buffer
+		zw := enc.NewWriter(&compressed)
+		_, h.err = zw.Write(h.identity)
+		if h.err == nil {
+			h.err = zw.Close()
+		}
+		if h.err != nil {
+			return h.err
+		}
+		h.variants[enc.Name] = compressed.Bytes()
+	}
+	return nil
+}
+
+// parseAcceptEncoding returns the accepted encodings of an Accept-Encoding
+// header. Encodings with q=0 are mapped to false.
+func parseAcceptEncoding(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		ok := true
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			v, err := strconv.ParseFloat(q, 64)
+			ok = err == nil && v > 0
+		}
+		accepted[name] = ok
+	}
+	return accepted
+}
+
+// rejected reports whether the encoding was explicitly refused with q=0.
+func rejected(accepted map[string]bool, name string) bool {
+	ok, listed := accepted[name]
+	return listed && !ok
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, comparing
+// weakly as required for GET and HEAD requests.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// DeferredCSS returns a CSSHandler serving the rules of the classes not
+// marked as critical, see WriteDeferredCSS, which pages load after the rules
+// of CriticalCSS:
+//
+//	http.Handle("/deferred.css", twerge.DeferredCSS())
+func DeferredCSS(encodings ...CSSEncoding) *CSSHandler {
+	return NewCSSHandler(func(w io.Writer) error {
+		return WriteDeferredCSS(w)
+	}, encodings...)
+}
+
+// UsageHandler returns an http.Handler serving UsageReport as JSON, which
+// twerge stats -url reads.
+func UsageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		report := UsageReport()
+		if report == nil {
+			report = []Usage{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// PublishExpvar publishes Stats as the expvar variable name, served as JSON
+// at /debug/vars by the expvar handler.
+//
+// Like expvar.Publish, it panics if name is already published.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return Stats()
+	}))
+}
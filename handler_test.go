@@ -0,0 +1,130 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSSHandlerEncoding(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	renders := 0
+	h := NewCSSHandler(func(w io.Writer) error {
+		renders++
+		return WriteGeneratedCSS(w)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/styles.css", nil)
+	req.Header.Set("Accept-Encoding", "br;q=0, gzip")
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	zr, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, ".tw-pad { \n\t@apply p-4; \n}\n", string(body))
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/styles.css", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	h.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, ".tw-pad { \n\t@apply p-4; \n}\n", rec.Body.String())
+	assert.Equal(t, 1, renders)
+
+	RegisterClasses(map[string]string{"m-2": "tw-margin"})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/styles.css", nil))
+	assert.Contains(t, rec.Body.String(), ".tw-margin")
+	assert.Equal(t, 2, renders)
+}
+
+func TestCSSHandlerETag(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	h := NewCSSHandler(nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/styles.css", nil))
+	etag := rec.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+	// compressed variants have ETags of their own
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/styles.css", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	h.ServeHTTP(rec, req)
+	gzipETag := rec.Header().Get("ETag")
+	assert.Equal(t, etag[:len(etag)-1]+`-gzip"`, gzipETag)
+
+	for _, ifNoneMatch := range []string{etag, `"other", W/` + etag, "*"} {
+		rec = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/styles.css", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotModified, rec.Code, ifNoneMatch)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+	}
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/styles.css", nil)
+	req.Header.Set("If-None-Match", gzipETag)
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// the ETag changes with the classes
+	RegisterClasses(map[string]string{"m-2": "tw-margin"})
+	h.CacheControl = "public, max-age=60"
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/styles.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+}
+
+func TestParseAcceptEncoding(t *testing.T) {
+	accepted := parseAcceptEncoding("gzip;q=0.8, br, identity;q=0")
+	assert.True(t, accepted["gzip"])
+	assert.True(t, accepted["br"])
+	assert.False(t, accepted["identity"])
+	assert.True(t, rejected(accepted, "identity"))
+	assert.False(t, rejected(accepted, "deflate"))
+}
+
+func TestUsageHandler(t *testing.T) {
+	SetMapping(map[string]string{"flex items-center justify-between": "tw-1"})
+	t.Cleanup(func() { SetMapping(nil) })
+	ResetUsage()
+	t.Cleanup(ResetUsage)
+	RuntimeGenerate("flex items-center justify-between")
+
+	rec := httptest.NewRecorder()
+	UsageHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/usage", nil))
+	var decoded []Usage
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.ElementsMatch(t, UsageReport(), decoded)
+}
+
+func TestPublishExpvar(t *testing.T) {
+	if expvar.Get("twerge-test") == nil {
+		PublishExpvar("twerge-test")
+	}
+	var published Statistics
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("twerge-test").String()), &published))
+	assert.Equal(t, Stats(), published)
+}
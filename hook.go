@@ -0,0 +1,73 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TemplGenFileName is the file written by RunBeforeTemplGenerate
+const TemplGenFileName = "twerge_gen.go"
+
+// RunBeforeTemplGenerate scans the .templ files below dir for class strings,
+// registers them and writes the class map to dir/twerge_gen.go.
+//
+// The generated file registers its class map on init, so the generated
+// _templ.go files and the class map stay in sync when this runs right before
+// templ generate:
+//
+//	//go:generate go run github.com/conneroisu/twerge/cmd/twerge templ
+func RunBeforeTemplGenerate(dir string) error {
+	classes, err := ScanTemplFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, c := range classes {
+		It(c)
+	}
+
+	pkgName, err := dirPackageName(dir)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, TemplGenFileName)
+	err = os.WriteFile(path, []byte(generateClassMapCode(takeSnapshot(), pkgName, true, nil)), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// RunAfterTemplGenerate regenerates the twerge section of the Tailwind input
+// CSS at cssPath, see GenerateTailwind.
+func RunAfterTemplGenerate(cssPath string) error {
+	return GenerateTailwind(cssPath)
+}
+
+// dirPackageName returns the package name declared by the .templ or .go
+// files directly inside dir.
+func dirPackageName(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".templ" && ext != ".go") || entry.Name() == TemplGenFileName ||
+			strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", fmt.Errorf("error reading %s: %w", entry.Name(), err)
+		}
+		if m := packageRegex.FindSubmatch(content); m != nil {
+			return string(m[1]), nil
+		}
+	}
+	return "", fmt.Errorf("no package clause found in %s", dir)
+}
@@ -0,0 +1,25 @@
+//go:build !twerge_prod && !tinygo
+
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBeforeTemplGenerate(t *testing.T) {
+	dir := t.TempDir()
+	templ := "package views\n\ntempl Card() {\n\t<div class={ twerge.It(\"flex items-center gap-2\") }></div>\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "card.templ"), []byte(templ), 0644))
+
+	assert.NoError(t, RunBeforeTemplGenerate(dir))
+
+	code, err := os.ReadFile(filepath.Join(dir, TemplGenFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), "package views")
+	assert.Contains(t, string(code), `"flex items-center gap-2"`)
+	assert.Contains(t, string(code), "twerge.RegisterClasses(ClassMapStr)")
+}
@@ -0,0 +1,24 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanClassLiterals(t *testing.T) {
+	content := []byte("package views\n\n" +
+		"templ Card(active bool) {\n" +
+		"\t<div class={ twerge.It(\"flex items-center\") }>\n" +
+		"\t\t<p class={ twerge.If(active, \"text-blue-500\", `text-gray-500`) }></p>\n" +
+		"\t\t<span class={ twerge.Merge(\"p-2 p-4\") }></span>\n" +
+		"\t\t<span class={ twerge.It(dynamic) }></span>\n" +
+		"\t</div>\n}\n")
+
+	assert.ElementsMatch(t,
+		[]string{"flex items-center", "text-blue-500", "text-gray-500", "p-2 p-4"},
+		scanClassLiterals(content),
+	)
+}
@@ -0,0 +1,132 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// DefaultHotReloadInterval is how often a HotReloadHandler checks the class
+// map for changes by default
+const DefaultHotReloadInterval = 250 * time.Millisecond
+
+// hotReloadStyleID is the id of the <style> element updated by the script of
+// HotReloadScript
+const hotReloadStyleID = "twerge-hot-reload"
+
+// HotReloadHandler pushes the stylesheet of the generated classes to browsers
+// as server-sent events whenever the class map changes, so pages pick up the
+// rules of classes added while developing without reloading:
+//
+//	mux.Handle("/twerge/hot-reload", twerge.NewHotReloadHandler(nil))
+//
+// Pages subscribe with the script of HotReloadScript. Every connection gets
+// the current stylesheet as a "css" event right away, and another one after
+// every change. Failing renders are sent as "error" events.
+type HotReloadHandler struct {
+	// Interval is how often the class map is checked for changes,
+	// DefaultHotReloadInterval if zero
+	Interval time.Duration
+
+	render func(io.Writer) error
+}
+
+// NewHotReloadHandler creates a HotReloadHandler pushing the output of
+// render. If render is nil, WriteGeneratedCSS with WithStandaloneCSS is used,
+// as browsers apply the pushed stylesheet without a Tailwind build.
+func NewHotReloadHandler(render func(io.Writer) error) *HotReloadHandler {
+	if render == nil {
+		render = func(w io.Writer) error { return WriteGeneratedCSS(w, WithStandaloneCSS()) }
+	}
+	return &HotReloadHandler{render: render}
+}
+
+// ServeHTTP implements http.Handler, streaming events until the client
+// disconnects.
+func (h *HotReloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	interval := h.Interval
+	if interval <= 0 {
+		interval = DefaultHotReloadInterval
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	sent := false
+	var version uint64
+	for {
+		if v := mapVersion.Load(); !sent || v != version {
+			sent, version = true, v
+			var buf bytes.Buffer
+			if err := h.render(&buf); err != nil {
+				writeEvent(w, "error", err.Error())
+			} else {
+				writeEvent(w, "css", buf.String())
+			}
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeEvent writes a server-sent event, splitting data into a data field
+// per line.
+func writeEvent(w io.Writer, event, data string) {
+	fmt.Fprintf(w, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// HotReloadScript returns a templ component rendering a <style> element and a
+// script subscribing to the HotReloadHandler at endpoint, which replaces the
+// content of the element with every stylesheet pushed. Place it last in the
+// <head>, so its rules win over those of the stylesheet served at startup:
+//
+//	<head>
+//		<link rel="stylesheet" href="/twerge.css"/>
+//		@twerge.HotReloadScript("/twerge/hot-reload")
+//	</head>
+//
+// It renders nothing in production mode, see ProductionMode.
+func HotReloadScript(endpoint string) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		if ProductionMode {
+			return nil
+		}
+		// json escapes <, > and & so the URL cannot close the script
+		url, err := json.Marshal(endpoint)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, `<style id="%s"></style><script>(function(){`+
+			`var style=document.getElementById("%s");`+
+			`var source=new EventSource(%s);`+
+			`source.addEventListener("css",function(e){style.textContent=e.data;});`+
+			`source.addEventListener("error",function(e){if(e.data)console.error("twerge:",e.data);});`+
+			`})();</script>`, hotReloadStyleID, hotReloadStyleID, url)
+		return err
+	})
+}
@@ -0,0 +1,19 @@
+//go:build !twerge_prod && !tinygo
+
+package twerge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotReloadScript(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, HotReloadScript("/twerge/hot-reload?x=</script>").Render(context.Background(), &b))
+	assert.Contains(t, b.String(), `<style id="twerge-hot-reload"></style><script>`)
+	assert.Contains(t, b.String(), `new EventSource("/twerge/hot-reload?x=\u003c/script\u003e")`)
+	assert.Equal(t, 1, strings.Count(b.String(), "</script>"))
+}
@@ -0,0 +1,81 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readEvent reads the next server-sent event, returning its name and data.
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var event string
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return "", ""
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return event, strings.Join(data, "\n")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestHotReloadHandler(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	h := NewHotReloadHandler(nil)
+	h.Interval = 5 * time.Millisecond
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	event, data := readEvent(t, r)
+	assert.Equal(t, "css", event)
+	assert.Equal(t, ".tw-pad { \n\tpadding: 1rem; \n}\n", data)
+
+	RegisterClasses(map[string]string{"m-2": "tw-margin"})
+	event, data = readEvent(t, r)
+	assert.Equal(t, "css", event)
+	assert.Contains(t, data, ".tw-margin")
+}
+
+func TestHotReloadHandlerError(t *testing.T) {
+	h := NewHotReloadHandler(func(io.Writer) error { return errors.New("broken") })
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	event, data := readEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "error", event)
+	assert.Equal(t, "broken", data)
+}
@@ -0,0 +1,128 @@
+package twerge
+
+import (
+	"cmp"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ClassGroup returns the ID of the class group a class belongs to, as used by
+// Merge to detect conflicts. Variants and the important modifier are ignored,
+// so "hover:!px-4" belongs to the "px" group.
+//
+// ok is false for classes Merge does not know, which it passes through.
+func ClassGroup(class string) (groupID string, ok bool) {
+	conf, trie := currentTrie()
+	baseClass, _, _, postFixMod := makeSplitModifiers(conf)(class)
+	if baseClass == "" {
+		return "", false
+	}
+	return resolveClassGroup(conf, makeGetClassGroupIDFromTrie(conf, trie), baseClass, postFixMod)
+}
+
+// ConflictsOf returns the IDs of the class groups a class of the given group
+// removes from the classes before it, besides its own group.
+//
+// For example, a "p" class removes "px", "py" and the other padding groups.
+func ConflictsOf(groupID string) []string {
+	return slices.Clone(currentConfig().ConflictingClassGroups[groupID])
+}
+
+// ClassPattern is a class, or a family of classes taking a value, known to
+// Merge.
+type ClassPattern struct {
+	// Class is the class, or the prefix of the classes taking a value if
+	// Validator is set, e.g. "rounded-t-"
+	Class string
+	// GroupID is the class group of the matching classes
+	GroupID string
+	// Validator names the function validating the value following Class,
+	// e.g. "isLength". It is empty for complete classes.
+	Validator string
+}
+
+// KnownClasses returns every class and class prefix known to Merge, sorted by
+// class.
+//
+// It can be used to build editor autocompletion from the same data Merge uses.
+func KnownClasses() []ClassPattern {
+	conf := currentConfig()
+	var patterns []ClassPattern
+	walkClassParts(&conf.ClassGroups, nil, string(conf.ClassSeparator), func(p ClassPattern) {
+		patterns = append(patterns, p)
+	})
+	sortClassPatterns(patterns)
+	return patterns
+}
+
+// CompleteClass returns the known classes and class prefixes completing the
+// partial class, sorted by class. Variants of the partial class are kept, so
+// "hover:rounded-t" completes to "hover:rounded-t-lg" among others.
+//
+// Prefixes taking a value are returned both while the prefix is being typed
+// and while the value is, e.g. "rounded-t-" for "rounded-t-x".
+func CompleteClass(partial string) []ClassPattern {
+	conf := currentConfig()
+	variants := ""
+	if i := strings.LastIndexByte(partial, byte(conf.ModifierSeparator)); i != -1 {
+		variants, partial = partial[:i+1], partial[i+1:]
+	}
+
+	var completions []ClassPattern
+	for _, p := range KnownClasses() {
+		typingValue := p.Validator != "" && strings.HasPrefix(partial, p.Class)
+		if !strings.HasPrefix(p.Class, partial) && !typingValue {
+			continue
+		}
+		p.Class = variants + p.Class
+		completions = append(completions, p)
+	}
+	return completions
+}
+
+// walkClassParts calls fn with the pattern of every class group found below
+// part, whose class parts so far are parts.
+func walkClassParts(part *classPart, parts []string, separator string, fn func(ClassPattern)) {
+	class := strings.Join(parts, separator)
+	if part.ClassGroupID != "" && class != "" {
+		fn(ClassPattern{Class: class, GroupID: part.ClassGroupID})
+	}
+	prefix := class
+	if prefix != "" {
+		prefix += separator
+	}
+	for _, validator := range part.Validators {
+		fn(ClassPattern{
+			Class:     prefix,
+			GroupID:   validator.ClassGroupID,
+			Validator: validatorName(validator.Fn),
+		})
+	}
+	for name, next := range part.NextPart {
+		walkClassParts(&next, append(slices.Clip(parts), name), separator, fn)
+	}
+}
+
+// validatorName returns the name of a validator function, or "custom" for
+// function literals.
+func validatorName(fn func(string) bool) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = name[strings.LastIndexByte(name, '.')+1:]
+	if strings.HasPrefix(name, "func") || name == "" {
+		return "custom"
+	}
+	return name
+}
+
+// sortClassPatterns sorts patterns by class, then group and validator.
+func sortClassPatterns(patterns []ClassPattern) {
+	slices.SortFunc(patterns, func(a, b ClassPattern) int {
+		return cmp.Or(
+			strings.Compare(a.Class, b.Class),
+			strings.Compare(a.GroupID, b.GroupID),
+			strings.Compare(a.Validator, b.Validator),
+		)
+	})
+}
@@ -0,0 +1,90 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassGroup(t *testing.T) {
+	for _, tc := range []struct {
+		class string
+		group string
+		ok    bool
+	}{
+		{class: "px-4", group: "px", ok: true},
+		{class: "hover:!px-4", group: "px", ok: true},
+		{class: "text-lg/8", group: "font-size", ok: true},
+		{class: "aspect-3/2", group: "aspect", ok: true},
+		{class: "[mask-type:luminance]", group: "arbitrary..mask-type", ok: true},
+		{class: "btn-primary", ok: false},
+		{class: "", ok: false},
+	} {
+		group, ok := ClassGroup(tc.class)
+		assert.Equal(t, tc.ok, ok, tc.class)
+		assert.Equal(t, tc.group, group, tc.class)
+	}
+
+	defer SetPluginGroups()
+	SetPluginGroups(PluginGroup{ID: "btn", Patterns: []string{"btn-*"}})
+	group, ok := ClassGroup("md:btn-primary")
+	assert.True(t, ok)
+	assert.Equal(t, "plugin:btn", group)
+}
+
+func TestConflictsOf(t *testing.T) {
+	assert.Contains(t, ConflictsOf("p"), "px")
+	assert.Contains(t, ConflictsOf("line-clamp"), "display")
+	assert.ElementsMatch(t, []string{"pr", "pl"}, ConflictsOf("px"))
+	assert.Empty(t, ConflictsOf("unknown"))
+
+	// the result is a copy
+	conflicts := ConflictsOf("p")
+	conflicts[0] = "changed"
+	assert.NotContains(t, ConflictsOf("p"), "changed")
+
+	defer SetConflictConfig(DefaultConflictConfig())
+	c := DefaultConflictConfig()
+	c.LineClampDisplay = false
+	SetConflictConfig(c)
+	assert.NotContains(t, ConflictsOf("line-clamp"), "display")
+}
+
+func TestKnownClasses(t *testing.T) {
+	patterns := KnownClasses()
+	assert.Contains(t, patterns, ClassPattern{Class: "flex", GroupID: "display"})
+	assert.Contains(t, patterns, ClassPattern{Class: "p-", GroupID: "p", Validator: "isLength"})
+	assert.Contains(t, patterns, ClassPattern{Class: "p-", GroupID: "p", Validator: "isArbitraryLength"})
+	assert.IsNonDecreasing(t, func() []string {
+		classes := make([]string, len(patterns))
+		for i, p := range patterns {
+			classes[i] = p.Class
+		}
+		return classes
+	}())
+}
+
+func TestCompleteClass(t *testing.T) {
+	completions := CompleteClass("hover:rounded-t")
+	assert.Contains(t, completions, ClassPattern{Class: "hover:rounded-t-none", GroupID: "rounded-t"})
+	assert.Contains(t, completions, ClassPattern{Class: "hover:rounded-tl-full", GroupID: "rounded-tl"})
+	assert.Contains(t, completions, ClassPattern{Class: "hover:rounded-t-", GroupID: "rounded-t", Validator: "isTshirtSize"})
+	for _, c := range completions {
+		assert.NotContains(t, c.GroupID, "rounded-b")
+	}
+
+	// prefixes stay completions while typing the value
+	assert.Contains(t, CompleteClass("rounded-t-x"), ClassPattern{Class: "rounded-t-", GroupID: "rounded-t", Validator: "isTshirtSize"})
+	assert.Empty(t, CompleteClass("unknown-class"))
+}
+
+func TestClassGroupReusesTrie(t *testing.T) {
+	ClassGroup("p-4")
+	_, before := currentTrie()
+	ClassGroup("m-2")
+	_, after := currentTrie()
+	assert.Same(t, before, after)
+
+	allocs := testing.AllocsPerRun(100, func() { ClassGroup("hover:p-4") })
+	assert.Less(t, allocs, float64(20))
+}
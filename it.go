@@ -0,0 +1,48 @@
+//go:build !twerge_prod
+
+package twerge
+
+// ProductionMode reports whether twerge was built with the twerge_prod build tag.
+const ProductionMode = false
+
+var (
+	// cache for generated classes
+	genCache = newCache(1000, nil)
+)
+
+// It returns a short unique CSS class name from the merged classes.
+//
+// If the class name already exists, it will return the existing class name.
+//
+// If the class name does not exist, it will generate a new class name and return it.
+// See SetAssertMode to report such class strings during development.
+func It(classes string) string {
+	// First check if a class name exists in ClassMapStr
+	mapMutex.RLock()
+	if className, exists := ClassMapStr[classes]; exists {
+		mapMutex.RUnlock()
+		return className
+	}
+	mapMutex.RUnlock()
+
+	assertRegistered(classes)
+
+	// First, merge the classes
+	merged := Merge(classes)
+
+	// Store the mapping
+	mapMutex.Lock()
+	classname := classNaming.className(&classID, merged, GenClassMergeStr)
+	ClassMapStr[classes] = classname
+	GenClassMergeStr[classname] = merged
+	genCache.Set(merged, classname)
+	mapVersion.Add(1)
+	mapMutex.Unlock()
+	mergeStats.generated.Add(1)
+
+	return classname
+}
+
+// publishClassMap is a no-op outside of production mode, where It reads
+// ClassMapStr directly.
+func publishClassMap() {}
@@ -0,0 +1,38 @@
+//go:build twerge_prod
+
+package twerge
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+// ProductionMode reports whether twerge was built with the twerge_prod build tag.
+const ProductionMode = true
+
+// prodClassMap is an immutable snapshot of ClassMapStr read by It
+// It is replaced, never modified, by publishClassMap
+var prodClassMap atomic.Pointer[map[string]string]
+
+// It returns the class name registered for classes.
+//
+// In production mode It is a pure lookup in the class map registered with
+// RegisterClasses: it never merges, hashes, locks or grows the map. Class
+// strings that were not registered are returned unchanged.
+func It(classes string) string {
+	m := prodClassMap.Load()
+	if m == nil {
+		return classes
+	}
+	if className, exists := (*m)[classes]; exists {
+		return className
+	}
+	return classes
+}
+
+// publishClassMap replaces the snapshot read by It with a copy of ClassMapStr.
+// mapMutex must be held.
+func publishClassMap() {
+	snapshot := maps.Clone(ClassMapStr)
+	prodClassMap.Store(&snapshot)
+}
@@ -0,0 +1,41 @@
+//go:build twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItProduction(t *testing.T) {
+	RegisterClasses(map[string]string{"flex items-center": "tw-row"})
+
+	assert.Equal(t, "tw-row", It("flex items-center"))
+	assert.Equal(t, "tw-row", RuntimeGenerate("flex items-center"))
+	// unregistered classes are passed through without growing the map
+	assert.Equal(t, "p-4 m-2", It("p-4 m-2"))
+	assert.NotContains(t, *prodClassMap.Load(), "p-4 m-2")
+}
+
+func TestCanonicalKeysProduction(t *testing.T) {
+	conf := DefaultConfig()
+	conf.CanonicalKeys = true
+	SetConfig(conf)
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	// class strings are looked up as they are written in production mode
+	RegisterClasses(map[string]string{"p-4 flex": "tw-canonical"})
+	assert.Equal(t, "tw-canonical", RuntimeGenerate("p-4 flex"))
+	assert.Equal(t, "flex p-4", RuntimeGenerate("flex p-4"))
+}
+
+func TestUsageProduction(t *testing.T) {
+	RegisterClasses(map[string]string{"flex items-center": "tw-row"})
+	ResetUsage()
+	t.Cleanup(ResetUsage)
+
+	RuntimeGenerate("flex items-center")
+	RuntimeGenerate("p-4 dynamic-" + t.Name())
+	assert.Empty(t, UsageReport())
+}
@@ -1,28 +1,42 @@
 package twerge
 
 import (
+	"hash/maphash"
 	"sync"
 )
 
-// newCache creates a new LRU cache
-func newCache(maxCapacity int) icache {
-	head := &node{}
-	tail := &node{}
-	tail.next = head
-	head.prev = tail
-	return &lru{
-		maxCapacity: maxCapacity,
-		capacity:    0,
-		cache:       make(map[string]*node),
-		head:        head,
-		tail:        tail,
-	}
-}
+// cacheShards is the number of shards of the LRU cache, so that concurrent
+// merges of different class lists rarely wait for each other
+const cacheShards = 16
 
-// icache is the interface for a LRU cache
-type icache interface {
-	Get(string) string
-	Set(string, string)
+// newCache creates a new LRU cache holding up to maxCapacity entries.
+//
+// Large caches are sharded, each shard evicting its own least recently used
+// entries.
+//
+// metrics, if not nil, is notified of the evicted entries.
+func newCache(maxCapacity int, metrics CacheMetrics) Cache {
+	c := &lru{seed: maphash.MakeSeed()}
+	// small caches are not sharded, so they hold exactly maxCapacity entries
+	shards := cacheShards
+	if maxCapacity < cacheShards*cacheShards {
+		shards = 1
+	}
+	c.shards = make([]lruShard, shards)
+	for i := range c.shards {
+		capacity := maxCapacity / shards
+		if i < maxCapacity%shards {
+			capacity++
+		}
+		c.shards[i] = lruShard{
+			maxCapacity: capacity,
+			cache:       make(map[string]*node),
+			metrics:     metrics,
+		}
+		c.shards[i].head.prev = &c.shards[i].head
+		c.shards[i].head.next = &c.shards[i].head
+	}
+	return c
 }
 
 type node struct {
@@ -32,69 +46,93 @@ type node struct {
 	next *node
 }
 
+// lru is a least recently used cache split into shards by the hash of the
+// keys, each with its own lock
 type lru struct {
+	seed   maphash.Seed
+	shards []lruShard
+}
+
+// lruShard is a part of an lru cache
+type lruShard struct {
+	mu          sync.Mutex
 	maxCapacity int
-	capacity    int
 	cache       map[string]*node
-	head        *node
-	tail        *node
-	cacheMutex  sync.RWMutex
-	listMutex   sync.Mutex
+	// head is the sentinel of the list of nodes, from the most recently used
+	// at head.next to the least recently used at head.prev
+	head    node
+	metrics CacheMetrics
+}
+
+func (l *lru) shard(key string) *lruShard {
+	if len(l.shards) == 1 {
+		return &l.shards[0]
+	}
+	return &l.shards[maphash.String(l.seed, key)%uint64(len(l.shards))]
 }
 
 func (l *lru) Get(key string) string {
-	l.cacheMutex.RLock()
-	n := l.cache[key]
+	s := l.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.cache[key]
 	if n == nil {
-		l.cacheMutex.RUnlock()
 		return ""
 	}
-	l.cacheMutex.RUnlock()
-
-	l.listMutex.Lock()
-	l.remove(n)
-	l.insertRight(n)
-	l.listMutex.Unlock()
-
+	s.remove(n)
+	s.insertFront(n)
 	return n.val
 }
 
 func (l *lru) Set(key, value string) {
-	l.cacheMutex.Lock()
-	if n := l.cache[key]; n != nil {
-		l.remove(n)
+	s := l.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxCapacity <= 0 {
+		return
+	}
+	if n := s.cache[key]; n != nil {
+		n.val = value
+		s.remove(n)
+		s.insertFront(n)
+		return
 	}
 	n := &node{key: key, val: value}
-	l.cache[key] = n
-	l.cacheMutex.Unlock()
-	l.listMutex.Lock()
-	l.insertRight(n)
-	l.listMutex.Unlock()
-	// evict
+	s.cache[key] = n
+	s.insertFront(n)
 
-	l.listMutex.Lock()
-	if l.capacity > l.maxCapacity {
-		delete(l.cache, l.tail.next.key)
-		l.remove(l.tail.next)
+	if len(s.cache) > s.maxCapacity {
+		oldest := s.head.prev
+		s.remove(oldest)
+		delete(s.cache, oldest.key)
+		if s.metrics != nil {
+			s.metrics.Evict()
+		}
 	}
-	l.listMutex.Unlock()
+}
 
+// Len returns the number of cached entries.
+func (l *lru) Len() int {
+	total := 0
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		total += len(s.cache)
+		s.mu.Unlock()
+	}
+	return total
 }
 
-func (l *lru) insertRight(n *node) {
-	prev := l.head.prev
-	prev.next = n
-	n.prev = prev
-	n.next = l.head
-	l.head.prev = n
+func (s *lruShard) insertFront(n *node) {
+	n.prev = &s.head
+	n.next = s.head.next
+	s.head.next.prev = n
+	s.head.next = n
 }
 
-func (l *lru) remove(n *node) {
-	prev := n.prev
-	nxt := n.next
-	prev.next = nxt
-	nxt.prev = prev
+func (s *lruShard) remove(n *node) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
 	n.prev = nil
 	n.next = nil
-	l.capacity--
 }
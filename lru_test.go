@@ -0,0 +1,77 @@
+package twerge
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingMetrics counts the notifications of CacheMetrics
+type countingMetrics struct {
+	hits, misses, evictions atomic.Int64
+}
+
+func (m *countingMetrics) Hit()   { m.hits.Add(1) }
+func (m *countingMetrics) Miss()  { m.misses.Add(1) }
+func (m *countingMetrics) Evict() { m.evictions.Add(1) }
+
+func TestLRUEviction(t *testing.T) {
+	metrics := &countingMetrics{}
+	cache := newCache(2, metrics)
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	// a is now more recently used than b
+	assert.Equal(t, "1", cache.Get("a"))
+	cache.Set("c", "3")
+
+	assert.Equal(t, "1", cache.Get("a"))
+	assert.Equal(t, "", cache.Get("b"))
+	assert.Equal(t, "3", cache.Get("c"))
+	assert.Equal(t, int64(1), metrics.evictions.Load())
+
+	// updating an entry does not evict
+	cache.Set("c", "4")
+	assert.Equal(t, "4", cache.Get("c"))
+	assert.Equal(t, 2, cache.(*lru).Len())
+}
+
+func TestLRUShardedCapacity(t *testing.T) {
+	metrics := &countingMetrics{}
+	cache := newCache(1000, metrics)
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := strconv.Itoa(w*1000 + i)
+				cache.Set(key, key)
+				cache.Get(key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, cache.(*lru).Len())
+	assert.Equal(t, int64(7000), metrics.evictions.Load())
+}
+
+func TestCacheMetrics(t *testing.T) {
+	metrics := &countingMetrics{}
+	merge := NewMerge(&Config{
+		Conflicts:    DefaultConflictConfig(),
+		CacheSize:    1,
+		CacheMetrics: metrics,
+	})
+	assert.Equal(t, "p-4", merge("p-2 p-4"))
+	assert.Equal(t, "p-4", merge("p-2 p-4"))
+	assert.Equal(t, "m-4", merge("m-2 m-4"))
+	assert.Equal(t, "p-4", merge("p-2 p-4"))
+
+	assert.Equal(t, int64(1), metrics.hits.Load())
+	assert.Equal(t, int64(3), metrics.misses.Load())
+	assert.Equal(t, int64(2), metrics.evictions.Load())
+}
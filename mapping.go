@@ -0,0 +1,123 @@
+package twerge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetMapping replaces the class map with classes, mapping original class
+// strings to class names.
+//
+// Unlike RegisterClasses, which adds to the class map, class strings not in
+// classes are forgotten. Readers never see a partially replaced map.
+func SetMapping(classes map[string]string) {
+	merged := make(map[string]string, len(classes))
+	for original := range classes {
+		merged[original] = Merge(original)
+	}
+
+	classMap := make(map[string]string, len(classes))
+	generated := make(map[string]string, len(classes))
+	for original, className := range classes {
+		classMap[original] = className
+		generated[className] = merged[original]
+	}
+
+	mapMutex.Lock()
+	defer mapMutex.Unlock()
+	ClassMapStr = classMap
+	GenClassMergeStr = generated
+	mapVersion.Add(1)
+	publishClassMap()
+}
+
+// TakeSnapshot returns a copy of the class map and the generated rules,
+// safe to use while It adds to them.
+func TakeSnapshot() Snapshot {
+	return takeSnapshot()
+}
+
+// Range calls f for every original class string of the class map, with its
+// class name and merged classes, in the order of the class strings. It stops
+// when f returns false.
+//
+// f is called on a copy of the class map, so it may call It or
+// RegisterClasses.
+func Range(f func(classes, className, merged string) bool) {
+	snap := takeSnapshot()
+	for _, classes := range slices.Sorted(maps.Keys(snap.ClassMap)) {
+		className := snap.ClassMap[classes]
+		if !f(classes, className, snap.Rules[className]) {
+			return
+		}
+	}
+}
+
+// mappingDocument is the document of ExportMapping and ImportMapping
+type mappingDocument struct {
+	// Classes maps original class strings to class names
+	Classes map[string]string `json:"classes" yaml:"classes"`
+	// Rules maps class names to their merged classes, sorted
+	Rules map[string]string `json:"rules" yaml:"rules"`
+}
+
+// ExportMapping writes the class map and the merged classes of its class
+// names to w as a JSON or YAML document, format being "json" or "yaml":
+//
+//	{
+//	  "classes": {
+//	    "p-2 p-4": "tw-0"
+//	  },
+//	  "rules": {
+//	    "tw-0": "p-4"
+//	  }
+//	}
+//
+// Keys are sorted and merged classes are sorted too, so the same class map
+// is always written the same way, e.g. to share it with a Node based Tailwind
+// pipeline or to compare it across CI runs.
+func ExportMapping(w io.Writer, format string) error {
+	snap := takeSnapshot()
+	doc := mappingDocument{
+		Classes: snap.ClassMap,
+		Rules:   snap.Rules,
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		// arbitrary variants like [&>*]:p-4 are written as is
+		enc.SetEscapeHTML(false)
+		return enc.Encode(doc)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown mapping format %q, want json or yaml", format)
+	}
+}
+
+// ImportMapping reads a document written by ExportMapping, in either format,
+// and adds its class strings to the class map like RegisterClasses.
+//
+// The merged classes are merged again with the current settings rather than
+// read, so an edited document cannot map a class name to other classes.
+func ImportMapping(r io.Reader) error {
+	var doc mappingDocument
+	// YAML is a superset of JSON
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return fmt.Errorf("error reading mapping: %w", err)
+	}
+	RegisterClasses(doc.Classes)
+	return nil
+}
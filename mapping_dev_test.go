@@ -0,0 +1,46 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMapping(t *testing.T) {
+	RegisterClasses(map[string]string{"grid": "tw-forgotten"})
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad", "flex": "tw-flex"})
+
+	snap := TakeSnapshot()
+	assert.Equal(t, map[string]string{"p-2 p-4": "tw-pad", "flex": "tw-flex"}, snap.ClassMap)
+	assert.Equal(t, map[string]string{"tw-pad": "p-4", "tw-flex": "flex"}, snap.Rules)
+	assert.Equal(t, "tw-pad", It("p-2 p-4"))
+
+	var visited []string
+	Range(func(classes, className, merged string) bool {
+		visited = append(visited, classes+"="+className+"="+merged)
+		// Range works on a copy, so It may add to the map
+		It("block")
+		return false
+	})
+	assert.Equal(t, []string{"flex=tw-flex=flex"}, visited)
+
+	// accessors are safe to use while It adds classes
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			It([]string{"m-1", "m-2", "m-3", "m-4"}[i])
+		}()
+		go func() {
+			defer wg.Done()
+			Range(func(string, string, string) bool { return true })
+			_ = TakeSnapshot()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, TakeSnapshot().ClassMap, 7)
+}
@@ -0,0 +1,52 @@
+package twerge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImportMapping(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad", "[&>*]:p-4 flex": "tw-flex"})
+	t.Cleanup(func() { SetMapping(nil) })
+	want := TakeSnapshot()
+
+	var jsonDoc bytes.Buffer
+	assert.NoError(t, ExportMapping(&jsonDoc, "json"))
+	assert.Equal(t, `{
+  "classes": {
+    "[&>*]:p-4 flex": "tw-flex",
+    "p-2 p-4": "tw-pad"
+  },
+  "rules": {
+    "tw-flex": "[&>*]:p-4 flex",
+    "tw-pad": "p-4"
+  }
+}
+`, jsonDoc.String())
+
+	var yamlDoc bytes.Buffer
+	assert.NoError(t, ExportMapping(&yamlDoc, "yaml"))
+	assert.Equal(t, `classes:
+  '[&>*]:p-4 flex': tw-flex
+  p-2 p-4: tw-pad
+rules:
+  tw-flex: '[&>*]:p-4 flex'
+  tw-pad: p-4
+`, yamlDoc.String())
+
+	assert.Error(t, ExportMapping(&bytes.Buffer{}, "toml"))
+
+	for _, doc := range []string{jsonDoc.String(), yamlDoc.String()} {
+		SetMapping(nil)
+		assert.NoError(t, ImportMapping(strings.NewReader(doc)))
+		snap := TakeSnapshot()
+		assert.Equal(t, want.ClassMap, snap.ClassMap)
+		assert.Equal(t, "p-4", snap.Rules["tw-pad"])
+	}
+
+	assert.NoError(t, ImportMapping(strings.NewReader("")))
+	assert.Error(t, ImportMapping(strings.NewReader("classes: [")))
+}
@@ -0,0 +1,366 @@
+package twerge
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MapOption configures how a class map is rendered to CSS.
+type MapOption func(*mapOptions)
+
+// mapOptions holds the settings applied by MapOption values.
+type mapOptions struct {
+	// prefix is prepended to every emitted class selector
+	prefix string
+	// compressionOrder groups rules with similar bodies together
+	compressionOrder bool
+	// standalone writes plain CSS declarations instead of @apply rules
+	standalone bool
+	// minify removes whitespace and groups rules with identical bodies
+	minify bool
+	// layer is the cascade layer the rules are wrapped in, none if empty
+	layer string
+	// unknown is how @apply rules treat classes that are not utilities
+	unknown UnknownClasses
+}
+
+// WithPrefix prepends prefix to every class selector emitted from a class map.
+//
+// For example, with the prefix "app-" the class name "tw-1" is emitted as
+// ".app-tw-1".
+func WithPrefix(prefix string) MapOption {
+	return func(o *mapOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithCompressionOrder orders rules so that rules with similar bodies are
+// adjacent instead of ordering them by class name.
+//
+// Rules are sorted by their classes, compared token by token in sorted order,
+// which gives gzip and brotli more repeated sequences within their window and
+// typically shrinks the compressed stylesheet. The output is still
+// deterministic: the classes of a rule keep the order Merge gives them, and
+// rules with equal classes are ordered by class name.
+func WithCompressionOrder() MapOption {
+	return func(o *mapOptions) {
+		o.compressionOrder = true
+	}
+}
+
+// DefaultLayer is the cascade layer of WithLayer if no name is given
+const DefaultLayer = "components"
+
+// WithLayer wraps the rules in an @layer block with the given name,
+// DefaultLayer if empty:
+//
+//	@layer components {
+//	.tw-0 {
+//		@apply p-4;
+//	}
+//	}
+//
+// Tailwind v4 orders its styles with cascade layers, and unlayered rules win
+// over every layer, so rules written at the top level override the utilities
+// used next to a generated class, like "tw-0 p-8". In the components layer
+// they are overridden by utilities instead, as in Tailwind's own components.
+func WithLayer(name string) MapOption {
+	return func(o *mapOptions) {
+		if name == "" {
+			name = DefaultLayer
+		}
+		o.layer = name
+	}
+}
+
+// inLayer calls write with w, wrapped in the @layer block of o, if any.
+func (o mapOptions) inLayer(w io.Writer, write func(w io.Writer) error) error {
+	if o.layer == "" {
+		return write(w)
+	}
+	open, end := "@layer "+o.layer+" {\n", "}\n"
+	if o.minify {
+		open, end = "@layer "+o.layer+"{", "}"
+	}
+	if _, err := io.WriteString(w, open); err != nil {
+		return err
+	}
+	if err := write(w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
+
+func newMapOptions(opts []MapOption) mapOptions {
+	var o mapOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// MergeCSSMaps combines multiple class maps into a single new map.
+//
+// Maps are applied in order, so when the same original class string appears
+// in more than one map the value from the last map wins.
+// The input maps are not modified.
+func MergeCSSMaps(classMaps ...map[string]string) map[string]string {
+	size := 0
+	for _, m := range classMaps {
+		size += len(m)
+	}
+	merged := make(map[string]string, size)
+	for _, m := range classMaps {
+		maps.Copy(merged, m)
+	}
+	return merged
+}
+
+// SortedKeys returns the keys of the given map in ascending order.
+//
+// It is used wherever twerge emits a map so that output is deterministic.
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteCSS writes an @apply rule for every entry of the class map to w.
+//
+// The class map maps original class strings to generated class names, the
+// same shape as ClassMapStr. Each original class string is merged as its rule
+// is written, so large class maps are streamed to w without building the
+// stylesheet in memory. Rules are ordered by generated class name.
+func WriteCSS(w io.Writer, classMap map[string]string, opts ...MapOption) error {
+	o := newMapOptions(opts)
+
+	byName := make(map[string]string, len(classMap))
+	for original, name := range classMap {
+		byName[name] = original
+	}
+
+	if o.compressionOrder || o.minify {
+		// the order and the groups depend on the merged classes
+		merged := make(map[string]string, len(byName))
+		for name, original := range byName {
+			merged[name] = Merge(original)
+		}
+		return o.writeRules(w, merged)
+	}
+
+	raw := registeredRawCSS()
+	return o.inLayer(w, func(w io.Writer) error {
+		for _, name := range SortedKeys(byName) {
+			err := o.writeRule(w, name, Merge(byName[name]), raw[name])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AppendClasses writes the header followed by the CSS rules for the class map to w.
+//
+// An empty header is omitted.
+func AppendClasses(
+	w io.Writer,
+	classMap map[string]string,
+	header string,
+	opts ...MapOption,
+) error {
+	if header != "" {
+		_, err := io.WriteString(w, "\n"+header+"\n")
+		if err != nil {
+			return fmt.Errorf("error writing header: %w", err)
+		}
+	}
+	return WriteCSS(w, classMap, opts...)
+}
+
+// AppendClassesToFile appends the CSS rules for the class map to the file at
+// cssPath, preceded by the given header comment.
+//
+// The file is created if it does not exist.
+func AppendClassesToFile(
+	cssPath string,
+	classMap map[string]string,
+	header string,
+	opts ...MapOption,
+) error {
+	f, err := os.OpenFile(cssPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening css file: %w", err)
+	}
+
+	err = AppendClasses(f, classMap, header, opts...)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("error closing css file: %w", err)
+	}
+	return nil
+}
+
+// ExportCSSWithMap writes the CSS rules for the class map between the twerge
+// markers of the file at cssPath.
+//
+// Content outside of the markers is preserved. If the markers do not exist
+// they are appended to the file.
+func ExportCSSWithMap(
+	cssPath string,
+	classMap map[string]string,
+	opts ...MapOption,
+) error {
+	return ExportCSSSections(cssPath, map[string]map[string]string{"": classMap}, opts...)
+}
+
+// ExportCSSSections writes subsets of the class map into named sections of the
+// file at cssPath, keyed by section name.
+//
+// A named section is delimited by markers carrying its name:
+//
+//	/* twerge:begin buttons */
+//	...
+//	/* twerge:end buttons */
+//
+// The empty name refers to the unnamed twerge markers. Content outside of the
+// sections is preserved and missing sections are appended to the file.
+func ExportCSSSections(
+	cssPath string,
+	sections map[string]map[string]string,
+	opts ...MapOption,
+) error {
+	content, err := os.ReadFile(cssPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading css file: %w", err)
+	}
+
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	layout, err := sectionLayout(content, names)
+	if err != nil {
+		return fmt.Errorf("error adding twerge content: %w", err)
+	}
+	return writeTargets(Snapshot{}, []Target{{
+		Path: cssPath,
+		Write: func(w io.Writer, _ Snapshot) error {
+			return writeLayout(w, layout, func(w io.Writer, section string) error {
+				return WriteCSS(w, sections[section], opts...)
+			})
+		},
+	}})
+}
+
+// ExportCSS writes the CSS rules for all classes in ClassMapStr between the
+// twerge markers of the file at cssPath.
+func ExportCSS(cssPath string, opts ...MapOption) error {
+	return ExportCSSWithMap(cssPath, getMapping(), opts...)
+}
+
+// WriteGeneratedCSS writes an @apply rule for every class in GenClassMergeStr to w.
+//
+// Rules are ordered by generated class name.
+func WriteGeneratedCSS(w io.Writer, opts ...MapOption) error {
+	o := newMapOptions(opts)
+
+	mapMutex.RLock()
+	generated := maps.Clone(GenClassMergeStr)
+	mapMutex.RUnlock()
+
+	return o.writeRules(w, generated)
+}
+
+// order returns the class names of rules, mapping class names to classes, in
+// the order they are written.
+func (o mapOptions) order(rules map[string]string) []string {
+	names := SortedKeys(rules)
+	if !o.compressionOrder {
+		return names
+	}
+
+	keys := make(map[string]string, len(rules))
+	for name, classes := range rules {
+		tokens := strings.Fields(classes)
+		sort.Strings(tokens)
+		keys[name] = strings.Join(tokens, " ")
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return keys[names[i]] < keys[names[j]]
+	})
+	return names
+}
+
+// writeRules writes the @apply rules, mapping class names to classes, in
+// order, each followed by the raw CSS registered for its class name.
+func (o mapOptions) writeRules(w io.Writer, rules map[string]string) error {
+	return o.writeRulesWithRaw(w, rules, registeredRawCSS())
+}
+
+// writeRulesWithRaw is like writeRules with the raw CSS, mapping class names
+// to CSS, given.
+func (o mapOptions) writeRulesWithRaw(w io.Writer, rules, raw map[string]string) error {
+	return o.inLayer(w, func(w io.Writer) error {
+		if o.minify {
+			return o.writeMinified(w, o.order(rules), rules, raw)
+		}
+		for _, className := range o.order(rules) {
+			err := o.writeRule(w, className, rules[className], raw[className])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeRule writes the rule of the class name followed by its raw CSS.
+func (o mapOptions) writeRule(w io.Writer, className, classes, raw string) error {
+	write := writeRawRule
+	switch {
+	case o.standalone:
+		write = writeStandaloneRule
+	case o.unknown != UnknownApply:
+		write = func(w io.Writer, className, classes, raw string) error {
+			return writeUnknownRule(w, className, classes, raw, o.unknown)
+		}
+	}
+	err := write(w, o.prefix+className, classes, raw)
+	if err != nil {
+		return fmt.Errorf("error writing rule for %s: %w", className, err)
+	}
+	return nil
+}
+
+// writeRawRule writes a single @apply rule for the class name followed by
+// raw CSS, if any.
+func writeRawRule(w io.Writer, className, classes, raw string) error {
+	err := writeRule(w, className, classes)
+	if err != nil || raw == "" {
+		return err
+	}
+	_, err = io.WriteString(w, raw+"\n")
+	return err
+}
+
+// writeRule writes a single @apply rule for the class name.
+func writeRule(w io.Writer, className, classes string) error {
+	_, err := io.WriteString(w, "."+className+" { \n\t@apply "+classes+"; \n}\n")
+	return err
+}
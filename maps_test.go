@@ -0,0 +1,208 @@
+package twerge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeCSSMaps(t *testing.T) {
+	map1 := map[string]string{"p-4": "tw-a", "m-2": "tw-b"}
+	map2 := map[string]string{"m-2": "tw-c", "flex": "tw-d"}
+
+	merged := MergeCSSMaps(map1, map2)
+	assert.Equal(t, map[string]string{
+		"p-4":  "tw-a",
+		"m-2":  "tw-c",
+		"flex": "tw-d",
+	}, merged)
+	// inputs are untouched
+	assert.Equal(t, "tw-b", map1["m-2"])
+}
+
+func TestWriteCSS(t *testing.T) {
+	classMap := map[string]string{
+		"text-red-500 text-blue-500": "tw-b",
+		"p-2 p-4":                    "tw-a",
+	}
+
+	var builder strings.Builder
+	err := WriteCSS(&builder, classMap, WithPrefix("app-"))
+	assert.NoError(t, err)
+
+	out := builder.String()
+	assert.Equal(t,
+		".app-tw-a { \n\t@apply p-4; \n}\n"+
+			".app-tw-b { \n\t@apply text-blue-500; \n}\n",
+		out,
+	)
+}
+
+func TestAppendClassesToFile(t *testing.T) {
+	cssPath := filepath.Join(t.TempDir(), "styles.css")
+	err := os.WriteFile(cssPath, []byte(".existing { color: red; }\n"), 0644)
+	assert.NoError(t, err)
+
+	err = AppendClassesToFile(cssPath, map[string]string{"grid gap-4": "grid-layout"}, "/* Grid Components */")
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	out := string(content)
+	assert.True(t, strings.HasPrefix(out, ".existing { color: red; }\n"))
+	assert.Contains(t, out, "/* Grid Components */")
+	assert.Contains(t, out, ".grid-layout { \n\t@apply grid gap-4; \n}")
+}
+
+func TestExportCSSWithMap(t *testing.T) {
+	cssPath := filepath.Join(t.TempDir(), "styles.css")
+	err := os.WriteFile(cssPath, []byte("/* before */\n"+twergeBeginMarker+"\nold\n"+twergeEndMarker+"\n/* after */\n"), 0644)
+	assert.NoError(t, err)
+
+	err = ExportCSSWithMap(cssPath, map[string]string{"flex items-center": "component-base"})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	out := string(content)
+	assert.Contains(t, out, "/* before */")
+	assert.Contains(t, out, "/* after */")
+	assert.Contains(t, out, ".component-base")
+	assert.NotContains(t, out, "old")
+}
+
+func TestExportCSSSections(t *testing.T) {
+	cssPath := filepath.Join(t.TempDir(), "styles.css")
+	original := "/* twerge:begin buttons */\nold\n/* twerge:end buttons */\n" +
+		".hand-written {}\n" +
+		"/* twerge:begin cards */\n/* twerge:end cards */\n"
+	assert.NoError(t, os.WriteFile(cssPath, []byte(original), 0644))
+
+	err := ExportCSSSections(cssPath, map[string]map[string]string{
+		"buttons": {"p-2 p-4": "btn"},
+		"cards":   {"rounded-lg": "card"},
+		"forms":   {"border": "input"},
+	})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"/* twerge:begin buttons */\n.btn { \n\t@apply p-4; \n}\n\n/* twerge:end buttons */\n"+
+			".hand-written {}\n"+
+			"/* twerge:begin cards */\n.card { \n\t@apply rounded-lg; \n}\n\n/* twerge:end cards */\n"+
+			"\n\n/* twerge:begin forms */\n.input { \n\t@apply border; \n}\n\n/* twerge:end forms */",
+		string(content),
+	)
+}
+
+func TestWithCompressionOrder(t *testing.T) {
+	classMap := map[string]string{
+		"p-4":   "tw-a",
+		"block": "tw-b",
+		"flex":  "tw-c",
+	}
+
+	var builder strings.Builder
+	err := WriteCSS(&builder, classMap, WithCompressionOrder())
+	assert.NoError(t, err)
+	assert.Equal(t,
+		".tw-b { \n\t@apply block; \n}\n"+
+			".tw-c { \n\t@apply flex; \n}\n"+
+			".tw-a { \n\t@apply p-4; \n}\n",
+		builder.String(),
+	)
+
+	// rules are sorted by their sorted classes, their bodies keep the merge
+	// order, and ties are ordered by class name
+	builder.Reset()
+	err = WriteCSS(&builder, map[string]string{"px-2 flex px-4": "tw-b", "px-4 flex": "tw-a", "block": "tw-c"}, WithCompressionOrder())
+	assert.NoError(t, err)
+	assert.Equal(t,
+		".tw-c { \n\t@apply block; \n}\n"+
+			".tw-a { \n\t@apply px-4 flex; \n}\n"+
+			".tw-b { \n\t@apply flex px-4; \n}\n",
+		builder.String(),
+	)
+
+	classMap = componentClassMap()
+	assert.Less(t, gzippedCSSSize(t, classMap, WithCompressionOrder()), gzippedCSSSize(t, classMap))
+}
+
+func TestWithLayer(t *testing.T) {
+	classMap := map[string]string{"p-2 p-4": "tw-a", "flex": "tw-b"}
+
+	var builder strings.Builder
+	err := WriteCSS(&builder, classMap, WithLayer(""))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"@layer components {\n"+
+			".tw-a { \n\t@apply p-4; \n}\n"+
+			".tw-b { \n\t@apply flex; \n}\n"+
+			"}\n",
+		builder.String(),
+	)
+
+	builder.Reset()
+	err = WriteCSS(&builder, classMap, WithLayer("twerge"), WithMinify())
+	assert.NoError(t, err)
+	assert.Equal(t, "@layer twerge{.tw-a{@apply p-4}.tw-b{@apply flex}}", builder.String())
+}
+
+func BenchmarkCompressionOrder(b *testing.B) {
+	classMap := componentClassMap()
+	for _, bc := range []struct {
+		name string
+		opts []MapOption
+	}{
+		{name: "name"},
+		{name: "compression", opts: []MapOption{WithCompressionOrder()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			size := 0
+			for b.Loop() {
+				size = gzippedCSSSize(b, classMap, bc.opts...)
+			}
+			b.ReportMetric(float64(size), "gzip-bytes")
+		})
+	}
+}
+
+// componentClassMap returns a class map shaped like the classes of a UI
+// component library, with generated names unrelated to the classes.
+func componentClassMap() map[string]string {
+	layouts := []string{"flex items-center", "grid grid-cols-2 gap-4", "block", "inline-flex items-center justify-center"}
+	spacings := []string{"p-2", "p-4", "px-4 py-2", "px-6 py-3"}
+	colors := []string{"bg-white text-gray-900", "bg-blue-500 text-white hover:bg-blue-600", "bg-gray-100 text-gray-700"}
+	borders := []string{"rounded", "rounded-lg border border-gray-200", "rounded-md shadow-sm"}
+
+	var classes []string
+	for _, layout := range layouts {
+		for _, spacing := range spacings {
+			for _, color := range colors {
+				for _, border := range borders {
+					classes = append(classes, layout+" "+spacing+" "+color+" "+border)
+				}
+			}
+		}
+	}
+	classMap := make(map[string]string, len(classes))
+	for i, c := range classes {
+		classMap[c] = "tw-" + strconv.Itoa(i*37%len(classes))
+	}
+	return classMap
+}
+
+func gzippedCSSSize(tb testing.TB, classMap map[string]string, opts ...MapOption) int {
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	assert.NoError(tb, WriteCSS(zw, classMap, opts...))
+	assert.NoError(tb, zw.Close())
+	return buf.Len()
+}
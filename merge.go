@@ -5,29 +5,41 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
-// ClassMapStr is a map of class strings to their generated class names
-// This variable can be populated by code generation or manually
-// It is protected by mapMutex for concurrent access
 var (
 	// Merge is the default template merger
 	// It takes a space-delimited string of TailwindCSS classes and returns a merged string
 	// It also adds the merged class to the ClassMapStr when used
 	// It will quickly return the generated class name from ClassMapStr if available
-	Merge = createTwMerge(nil, nil)
+	// The kept classes stay in the order they were passed in, so a class
+	// string always merges to the same result
+	Merge = createTwMerge(withStats(defaultConfig), nil, recordMerged)
 
+	// ClassMapStr is a map of class strings to their generated class names
+	// It is protected by mapMutex for concurrent access
+	//
+	// Deprecated: reading or writing ClassMapStr races with It. Use
+	// RegisterClasses or SetMapping to change it and TakeSnapshot or Range
+	// to read it.
 	ClassMapStr = make(map[string]string)
 
-	// GenClassMergeStr is a map of merged class strings to their generated class names
-	// This variable can be populated by code generation or manually
+	// GenClassMergeStr is a map of generated class names to their merged classes
 	// It is protected by mapMutex for concurrent access
+	//
+	// Deprecated: reading or writing GenClassMergeStr races with It. Use
+	// RegisterClasses or SetMapping to change it and TakeSnapshot or Range
+	// to read it.
 	GenClassMergeStr = make(map[string]string)
 
 	// mapMutex protects ClassMapStr for concurrent access
 	mapMutex sync.RWMutex
 
 	classID int
+
+	// mapVersion is incremented whenever ClassMapStr or GenClassMergeStr change
+	mapVersion atomic.Uint64
 )
 
 // twMergeFn is the type of the template merger.
@@ -42,12 +54,15 @@ type splitModifiersFn = func(string) (
 )
 
 // createTwMerge creates a new template merger
+//
+// record, if not nil, is called with every class list the merger changed.
 func createTwMerge(
 	config *config,
-	cache icache,
+	cache Cache,
+	record func(classList, merged string),
 ) twMergeFn {
 	var (
-		fnToCall        twMergeFn
+		once            sync.Once
 		splitModifiers  splitModifiersFn
 		getClassGroupID getClassGroupIDFn
 		mergeClassList  func(classList string) string
@@ -62,32 +77,34 @@ func createTwMerge(
 		// Check if we've seen this class list before in the cache
 		cached := cache.Get(classList)
 		if cached != "" {
+			if config.CacheMetrics != nil {
+				config.CacheMetrics.Hit()
+			}
 			return cached
 		}
+		if config.CacheMetrics != nil {
+			config.CacheMetrics.Miss()
+		}
 
 		// Merge the classes
-		merged := mergeClassList(classList)
+		merged := stripDevOnlyClasses(mergeClassList(classList), splitModifiers)
 		cache.Set(classList, merged)
 
-		// Add to ClassMapStr for lookup by other functions
-		if classList != merged {
-			mapMutex.Lock()
-			className := fmt.Sprintf("tw-%d", classID)
-			ClassMapStr[classList] = className
-			GenClassMergeStr[className] = merged
-			classID++
-			mapMutex.Unlock()
+		if record != nil && classList != merged {
+			record(classList, merged)
 		}
 
 		return merged
 	}
 
-	init := func(classes string) string {
+	// the merger is built on the first call, which may come from several
+	// goroutines at once
+	init := func() {
 		if config == nil {
 			config = defaultConfig
 		}
 		if cache == nil {
-			cache = newCache(config.MaxCacheSize)
+			cache = newCache(config.MaxCacheSize, config.CacheMetrics)
 		}
 
 		splitModifiers = makeSplitModifiers(config)
@@ -95,17 +112,26 @@ func createTwMerge(
 		getClassGroupID = makeGetClassGroupID(config)
 
 		mergeClassList = makeMergeClassList(config, splitModifiers, getClassGroupID)
-
-		fnToCall = merger
-		return fnToCall(classes)
 	}
 
-	fnToCall = init
 	return func(classes string) string {
-		return fnToCall(classes)
+		once.Do(init)
+		return merger(classes)
 	}
 }
 
+// recordMerged adds a class list changed by Merge to ClassMapStr for lookup by
+// other functions.
+func recordMerged(classList, merged string) {
+	mapMutex.Lock()
+	className := classNaming.className(&classID, merged, GenClassMergeStr)
+	ClassMapStr[classList] = className
+	GenClassMergeStr[className] = merged
+	mapVersion.Add(1)
+	mapMutex.Unlock()
+	mergeStats.generated.Add(1)
+}
+
 // makeMergeClassList creates a function that merges a class list
 func makeMergeClassList(
 	conf *config,
@@ -113,20 +139,44 @@ func makeMergeClassList(
 	getClassGroupID getClassGroupIDFn,
 ) func(classList string) string {
 	return func(classList string) string {
-		classes := strings.Split(strings.TrimSpace(classList), " ")
-		unqClasses := make(map[string]string, len(classes))
-		resultClassList := ""
+		// class lists of formatted templates span lines and are indented
+		classes := strings.Fields(classList)
+		// kept holds the kept classes at their position in the class list,
+		// so the merged classes keep the order they were passed in
+		kept := make([]string, len(classes))
+		// unqClasses holds the position of the kept class of every class
+		// group and modifiers
+		unqClasses := make(map[string]int, len(classes))
+		// passed classes are kept once, Tailwind classes are deduplicated
+		// by their groups
+		passed := make(map[string]bool)
+		keep := func(key string, i int, class string) {
+			if j, ok := unqClasses[key]; ok {
+				kept[j] = ""
+			}
+			unqClasses[key] = i
+			kept[i] = class
+		}
 
-		for _, class := range classes {
+		for i, class := range classes {
+			if conf.Blocklist.match(class) {
+				continue
+			}
+			if conf.Safelist.match(class) {
+				if !passed[class] {
+					passed[class] = true
+					kept[i] = class
+				}
+				continue
+			}
 			baseClass, modifiers, hasImportant, postFixMod := splitModifiers(class)
 
-			// there is a postfix modifier -> text-lg/8
-			if postFixMod != -1 {
-				baseClass = baseClass[:postFixMod]
-			}
-			isTwClass, groupID := getClassGroupID(baseClass)
+			groupID, isTwClass := resolveClassGroup(conf, getClassGroupID, baseClass, postFixMod)
 			if !isTwClass {
-				resultClassList += class + " "
+				if !passed[class] {
+					passed[class] = true
+					kept[i] = class
+				}
 				continue
 			}
 			// we have to sort the modifiers bc hover:focus:bg-red-500 == focus:hover:bg-red-500
@@ -134,7 +184,8 @@ func makeMergeClassList(
 			if hasImportant {
 				modifiers = append(modifiers, "!")
 			}
-			unqClasses[groupID+strings.Join(modifiers, string(conf.ModifierSeparator))] = class
+			modifierKey := strings.Join(modifiers, string(conf.ModifierSeparator))
+			keep(groupID+modifierKey, i, class)
 
 			conflicts := conf.ConflictingClassGroups[groupID]
 			if conflicts == nil {
@@ -142,35 +193,79 @@ func makeMergeClassList(
 			}
 			for _, conflict := range conflicts {
 				// erase the conflicts with the same modifiers
-				unqClasses[conflict+strings.Join(modifiers, string(conf.ModifierSeparator))] = ""
+				if j, ok := unqClasses[conflict+modifierKey]; ok && j != i {
+					kept[j] = ""
+					delete(unqClasses, conflict+modifierKey)
+				}
 			}
 		}
 
-		for _, class := range unqClasses {
+		var result strings.Builder
+		result.Grow(len(classList))
+		for _, class := range kept {
 			if class == "" {
 				continue
 			}
-			resultClassList += class + " "
+			result.WriteString(class)
+			result.WriteByte(' ')
 		}
-		return strings.TrimSpace(resultClassList)
+		return strings.TrimSpace(result.String())
+	}
+
+}
+
+// resolveClassGroup returns the class group of a class without its modifiers,
+// falling back to the plugin groups for classes unknown to Tailwind.
+func resolveClassGroup(
+	conf *config,
+	getClassGroupID getClassGroupIDFn,
+	baseClass string,
+	postFixMod int,
+) (string, bool) {
+	// there is a postfix modifier -> text-lg/8
+	isTwClass, groupID := false, ""
+	if postFixMod != -1 {
+		isTwClass, groupID = getClassGroupID(baseClass[:postFixMod])
+	}
+	// the slash may be part of the value -> aspect-3/2
+	if !isTwClass {
+		isTwClass, groupID = getClassGroupID(baseClass)
+	}
+	if isTwClass {
+		return groupID, true
 	}
 
+	group, ok := matchPluginGroup(conf.PluginGroups, baseClass)
+	if !ok {
+		return "", false
+	}
+	// preserved classes only conflict with themselves
+	groupID = pluginGroupPrefix + group.ID
+	if group.Preserve {
+		groupID += ":" + baseClass
+	}
+	return groupID, true
 }
 
 // sortModifiers Sorts modifiers according to following schema:
 // - Predefined modifiers are sorted alphabetically
 // - When an arbitrary variant appears, it must be preserved which modifiers are before and after it
+// - Negated arbitrary variants (not-[...]) are treated like arbitrary variants
+// - Modifiers are compared in the form of variantKey, e.g. data-open == data-[open]
 func sortModifiers(modifiers []string) []string {
 	if len(modifiers) < 2 {
+		for i, modifier := range modifiers {
+			modifiers[i] = variantKey(modifier)
+		}
 		return modifiers
 	}
 
 	unsortedModifiers := []string{}
-	sorted := make([]string, len(modifiers))
+	sorted := make([]string, 0, len(modifiers))
 
 	for _, modifier := range modifiers {
-		isArbitraryVariant := modifier[0] == '['
-		if isArbitraryVariant {
+		modifier = variantKey(modifier)
+		if isPositionSensitiveModifier(modifier) {
 			slices.Sort(unsortedModifiers)
 			sorted = append(sorted, unsortedModifiers...)
 			sorted = append(sorted, modifier)
@@ -186,6 +281,12 @@ func sortModifiers(modifiers []string) []string {
 	return sorted
 }
 
+// isPositionSensitiveModifier returns true if the order of the modifier
+// relative to the other modifiers changes the generated selector
+func isPositionSensitiveModifier(modifier string) bool {
+	return strings.HasPrefix(modifier, "[") || strings.HasPrefix(modifier, "not-[")
+}
+
 // makeSplitModifiers creates a function that splits modifiers
 func makeSplitModifiers(conf *config) splitModifiersFn {
 	separator := conf.ModifierSeparator
@@ -194,6 +295,8 @@ func makeSplitModifiers(conf *config) splitModifiersFn {
 		modifiers := []string{}
 		modifierStart := 0
 		bracketDepth := 0
+		// used for v4 values in parentheses, e.g. not-supports-(display:grid)
+		parenDepth := 0
 		// used for bg-red-500/50 (50% opacity)
 		maybePostfixModPosition := -1
 
@@ -208,8 +311,16 @@ func makeSplitModifiers(conf *config) splitModifiersFn {
 				bracketDepth--
 				continue
 			}
+			if char == '(' {
+				parenDepth++
+				continue
+			}
+			if char == ')' {
+				parenDepth--
+				continue
+			}
 
-			if bracketDepth == 0 {
+			if bracketDepth == 0 && parenDepth == 0 {
 				if char == separator {
 					modifiers = append(modifiers, className[modifierStart:i])
 					modifierStart = i + 1
@@ -222,14 +333,18 @@ func makeSplitModifiers(conf *config) splitModifiersFn {
 			}
 		}
 
-		baseClassWithImportant := className[modifierStart:]
-		hasImportant := baseClassWithImportant[0] == byte(conf.ImportantModifier)
-
-		var baseClass string
-		if hasImportant {
-			baseClass = baseClassWithImportant[1:]
-		} else {
-			baseClass = baseClassWithImportant
+		// the important modifier leads the base class in v3 (!bg-red-500)
+		// and may trail it since v4 (bg-red-500!)
+		baseClass := className[modifierStart:]
+		important := byte(conf.ImportantModifier)
+		hasImportant := false
+		if len(baseClass) > 0 && baseClass[0] == important {
+			hasImportant = true
+			baseClass = baseClass[1:]
+			modifierStart++
+		} else if len(baseClass) > 1 && baseClass[len(baseClass)-1] == important {
+			hasImportant = true
+			baseClass = baseClass[:len(baseClass)-1]
 		}
 
 		// fix case where there is modifier & maybePostfix which causes maybePostfix to be beyond size of baseClass!
@@ -298,6 +298,23 @@ func TestTailwindMerge(t *testing.T) {
 			in:  "focus:!inline focus:!block",
 			out: "focus:!block",
 		},
+		// the important modifier may trail the class since v4
+		{
+			in:  "font-medium! font-bold!",
+			out: "font-bold!",
+		}, {
+			in:  "!font-medium font-bold!",
+			out: "font-bold!",
+		}, {
+			in:  "font-medium! !font-bold",
+			out: "!font-bold",
+		}, {
+			in:  "focus:inline! focus:!block",
+			out: "focus:!block",
+		}, {
+			in:  "bg-red-500/50! !bg-blue-500/[.25] bg-green-500!",
+			out: "bg-green-500!",
+		},
 		// conflicts across prefix modifiers
 		{
 			in:  "hover:block hover:inline",
@@ -342,6 +359,51 @@ func TestTailwindMerge(t *testing.T) {
 			in:  "top-12 -top-69",
 			out: "-top-69",
 		},
+		// handles CSS variable shorthands
+		{
+			in:  "bg-red-500 bg-(--brand)",
+			out: "bg-(--brand)",
+		}, {
+			in:  "bg-(--brand) bg-red-500",
+			out: "bg-red-500",
+		}, {
+			in:  "text-(length:--size) text-lg",
+			out: "text-lg",
+		}, {
+			in:  "text-(--color) text-lg",
+			out: "text-(--color) text-lg",
+		}, {
+			in:  "bg-(image:--hero) bg-none bg-(position:--pos) bg-center",
+			out: "bg-none bg-center",
+		}, {
+			in:  "shadow-(--elevation) shadow-lg",
+			out: "shadow-lg",
+		}, {
+			in:  "-mt-(--gap) mt-2",
+			out: "mt-2",
+		},
+		// handles negative values of every class group accepting them
+		{
+			in:  "-mt-4 mt-2",
+			out: "mt-2",
+		}, {
+			in:  "translate-x-2 -translate-x-1/2",
+			out: "-translate-x-1/2",
+		}, {
+			in:  "-z-10 z-20",
+			out: "z-20",
+		}, {
+			in:  "-scroll-mx-2 scroll-mx-[3px]",
+			out: "scroll-mx-[3px]",
+		},
+		// passes through negative values of class groups not accepting them
+		{
+			in:  "-p-4 p-2",
+			out: "-p-4 p-2",
+		}, {
+			in:  "p-inf p-2",
+			out: "p-inf p-2",
+		},
 		// handles conflicts across groups with negative values correctly
 		{
 			in:  "-right-1 inset-x-1",
@@ -411,6 +473,55 @@ func TestTailwindMerge(t *testing.T) {
 			in:  "group-read-only:p-2 group-read-only:p-3",
 			out: "group-read-only:p-3",
 		},
+		// keeps named group and peer variants apart from unnamed ones
+		{
+			in:  "group-hover:bg-red-500 group-hover:bg-blue-500",
+			out: "group-hover:bg-blue-500",
+		}, {
+			in:  "group-hover/edit:bg-red-500 group-hover/edit:bg-blue-500",
+			out: "group-hover/edit:bg-blue-500",
+		}, {
+			in:  "group-hover/edit:bg-red-500 group-hover:bg-blue-500",
+			out: "group-hover/edit:bg-red-500 group-hover:bg-blue-500",
+		}, {
+			in:  "group-hover/edit:bg-red-500 group-hover/view:bg-blue-500",
+			out: "group-hover/edit:bg-red-500 group-hover/view:bg-blue-500",
+		}, {
+			in:  "peer-checked/a:p-2 focus:peer-checked/a:p-3 peer-checked/a:focus:p-4",
+			out: "peer-checked/a:p-2 peer-checked/a:focus:p-4",
+		}, {
+			in:  "group-hover/edit:bg-red-500/50 group-hover/edit:bg-blue-500",
+			out: "group-hover/edit:bg-blue-500",
+		},
+		// merges data and aria variants per attribute
+		{
+			in:  "data-[state=open]:flex data-[state=open]:hidden aria-checked:bg-blue-500",
+			out: "data-[state=open]:hidden aria-checked:bg-blue-500",
+		}, {
+			in:  "data-[state=open]:flex data-[state=closed]:hidden",
+			out: "data-[state=open]:flex data-[state=closed]:hidden",
+		}, {
+			in:  "data-open:p-2 data-[open]:p-4",
+			out: "data-[open]:p-4",
+		}, {
+			in:  "aria-checked:p-2 aria-[checked=true]:p-4",
+			out: "aria-[checked=true]:p-4",
+		}, {
+			in:  `data-[state="open"]:p-2 data-[state=open]:p-4`,
+			out: "data-[state=open]:p-4",
+		}, {
+			in:  "aria-checked:p-2 aria-[checked=false]:p-4",
+			out: "aria-checked:p-2 aria-[checked=false]:p-4",
+		}, {
+			in:  "group-data-open/menu:p-2 group-data-[open]/menu:p-4 group-data-[open]:p-6",
+			out: "group-data-[open]/menu:p-4 group-data-[open]:p-6",
+		}, {
+			in:  "data-[side=left]:data-[state=open]:p-2 data-[state=open]:data-[side=left]:p-4",
+			out: "data-[state=open]:data-[side=left]:p-4",
+		}, {
+			in:  "data-[state=open]:[&>*]:p-2 [&>*]:data-[state=open]:p-4",
+			out: "data-[state=open]:[&>*]:p-2 [&>*]:data-[state=open]:p-4",
+		},
 		// merges standalone classes from same group correctly
 		{
 			in:  "inline block",
@@ -526,6 +637,172 @@ func TestTailwindMerge(t *testing.T) {
 			in:  "group-has-[[data-sidebar=menu-action]]/menu-item:pr-8 group-has-[[data-sidebar=menu-action]]/menu-item:pr-6",
 			out: "group-has-[[data-sidebar=menu-action]]/menu-item:pr-6",
 		},
+		// handles negated variants within their own scope
+		{
+			in:  "not-hover:bg-red-500 hover:bg-blue-500 not-hover:bg-green-500",
+			out: "hover:bg-blue-500 not-hover:bg-green-500",
+		}, {
+			in:  "not-supports-[display:grid]:grid not-supports-[display:grid]:flex supports-[display:grid]:grid",
+			out: "not-supports-[display:grid]:flex supports-[display:grid]:grid",
+		}, {
+			in:  "not-supports-(display:grid):p-2 not-supports-(display:grid):p-4 p-1",
+			out: "not-supports-(display:grid):p-4 p-1",
+		}, {
+			in:  "not-[.foo]:p-4 not-[.foo]:p-2 [.foo]:p-1",
+			out: "not-[.foo]:p-2 [.foo]:p-1",
+		}, {
+			in:  "hover:not-focus:p-4 not-focus:hover:p-2",
+			out: "not-focus:hover:p-2",
+		}, {
+			in:  "hover:not-[.foo]:p-4 not-[.foo]:hover:p-2",
+			out: "hover:not-[.foo]:p-4 not-[.foo]:hover:p-2",
+		},
+		// keeps starting: utilities apart from their base group
+		{
+			in:  "opacity-100 starting:opacity-0",
+			out: "opacity-100 starting:opacity-0",
+		}, {
+			in:  "starting:opacity-0 starting:opacity-50 opacity-100",
+			out: "starting:opacity-50 opacity-100",
+		}, {
+			in:  "open:starting:scale-95 starting:open:scale-90 open:scale-100",
+			out: "starting:open:scale-90 open:scale-100",
+		},
+		// handles transition behavior
+		{
+			in:  "transition-opacity transition-discrete starting:opacity-0",
+			out: "transition-opacity transition-discrete starting:opacity-0",
+		}, {
+			in:  "transition-discrete transition-normal",
+			out: "transition-normal",
+		},
+		// handles newer state variants
+		{
+			in:  "inert:opacity-50 inert:opacity-25 opacity-100",
+			out: "inert:opacity-25 opacity-100",
+		}, {
+			in:  "popover-open:opacity-0 popover-open:opacity-100 open:opacity-50",
+			out: "popover-open:opacity-100 open:opacity-50",
+		}, {
+			in:  "user-valid:border-green-500 user-invalid:border-red-500 valid:border-gray-500 user-valid:border-green-600",
+			out: "user-invalid:border-red-500 valid:border-gray-500 user-valid:border-green-600",
+		}, {
+			in:  "in-focus:p-2 focus:p-3 in-focus:p-4",
+			out: "focus:p-3 in-focus:p-4",
+		}, {
+			in:  "in-[.dark]:bg-black in-[.dark]:bg-gray-900 in-data-open:bg-white",
+			out: "in-[.dark]:bg-gray-900 in-data-open:bg-white",
+		}, {
+			in:  "hover:inert:p-2 inert:hover:p-4",
+			out: "inert:hover:p-4",
+		},
+		// handles grid template values
+		{
+			in:  "grid-cols-2 grid-cols-subgrid",
+			out: "grid-cols-subgrid",
+		}, {
+			in:  "grid-rows-subgrid grid-rows-none",
+			out: "grid-rows-none",
+		}, {
+			in:  "grid-cols-[1fr_2fr] grid-cols-3",
+			out: "grid-cols-3",
+		}, {
+			in:  "grid-cols-subgrid grid-rows-subgrid",
+			out: "grid-cols-subgrid grid-rows-subgrid",
+		}, {
+			in:  "grid-cols-foo grid-cols-2",
+			out: "grid-cols-foo grid-cols-2",
+		},
+		// handles font stretch
+		{
+			in:  "font-stretch-condensed font-stretch-ultra-expanded",
+			out: "font-stretch-ultra-expanded",
+		}, {
+			in:  "font-stretch-50% font-stretch-[66.66%]",
+			out: "font-stretch-[66.66%]",
+		}, {
+			in:  "font-bold font-stretch-expanded font-sans",
+			out: "font-bold font-stretch-expanded font-sans",
+		},
+		// handles modern color functions
+		{
+			in:  "text-[color-mix(in_oklch,red_50%,blue)] text-[12px]",
+			out: "text-[color-mix(in_oklch,red_50%,blue)] text-[12px]",
+		}, {
+			in:  "text-red-500 text-[color-mix(in_oklch,red_50%,blue)]",
+			out: "text-[color-mix(in_oklch,red_50%,blue)]",
+		}, {
+			in:  "border-[2px] border-[color-mix(in_srgb,red_10%,blue)]",
+			out: "border-[2px] border-[color-mix(in_srgb,red_10%,blue)]",
+		}, {
+			in:  "bg-red-500 bg-[oklch(70%_0.1_200)]",
+			out: "bg-[oklch(70%_0.1_200)]",
+		}, {
+			in:  "text-[14px] text-[color(display-p3_1_0_0_/_50%)]",
+			out: "text-[14px] text-[color(display-p3_1_0_0_/_50%)]",
+		},
+		// handles aspect ratios
+		{
+			in:  "aspect-video aspect-[4/3]",
+			out: "aspect-[4/3]",
+		}, {
+			in:  "aspect-square aspect-3/2",
+			out: "aspect-3/2",
+		}, {
+			in:  "aspect-3/2 aspect-video",
+			out: "aspect-video",
+		}, {
+			in:  "aspect-[1.5] aspect-16/9",
+			out: "aspect-16/9",
+		}, {
+			in:  "aspect-foo aspect-video",
+			out: "aspect-foo aspect-video",
+		},
+		// handles 3d transforms
+		{
+			in:  "rotate-x-45 rotate-y-30 rotate-z-15 rotate-45",
+			out: "rotate-x-45 rotate-y-30 rotate-z-15 rotate-45",
+		}, {
+			in:  "rotate-x-45 rotate-x-90 -rotate-y-12 rotate-y-[17deg]",
+			out: "rotate-x-90 rotate-y-[17deg]",
+		}, {
+			in:  "translate-z-4 translate-x-2 translate-z-8",
+			out: "translate-x-2 translate-z-8",
+		}, {
+			in:  "scale-z-50 scale-x-75 scale-z-100 scale-3d",
+			out: "scale-x-75 scale-z-100 scale-3d",
+		}, {
+			in:  "perspective-near perspective-[750px] perspective-origin-top perspective-origin-bottom-left",
+			out: "perspective-[750px] perspective-origin-bottom-left",
+		}, {
+			in:  "transform-gpu transform-3d transform-flat backface-hidden backface-visible",
+			out: "transform-gpu transform-flat backface-visible",
+		},
+		// handles pointer and any-pointer variants
+		{
+			in:  "p-2 pointer-coarse:p-4 pointer-coarse:p-6",
+			out: "p-2 pointer-coarse:p-6",
+		}, {
+			in:  "pointer-fine:p-2 pointer-coarse:p-4 pointer-none:p-6",
+			out: "pointer-fine:p-2 pointer-coarse:p-4 pointer-none:p-6",
+		}, {
+			in:  "any-pointer-coarse:h-12 any-pointer-fine:h-8 any-pointer-coarse:h-14 pointer-coarse:h-10",
+			out: "any-pointer-fine:h-8 any-pointer-coarse:h-14 pointer-coarse:h-10",
+		}, {
+			in:  "hover:pointer-fine:underline pointer-fine:hover:no-underline",
+			out: "pointer-fine:hover:no-underline",
+		}, {
+			in:  "pointer-events-none pointer-coarse:pointer-events-auto pointer-events-auto",
+			out: "pointer-coarse:pointer-events-auto pointer-events-auto",
+		},
+		// does not panic on empty variants or base classes
+		{
+			in:  "inert: p-4",
+			out: "inert: p-4",
+		}, {
+			in:  "p-4 hover::p-2",
+			out: "p-4 hover::p-2",
+		},
 	}
 
 	for _, tc := range tt {
@@ -538,6 +815,73 @@ func TestTailwindMerge(t *testing.T) {
 	}
 }
 
+func TestMergeWhitespace(t *testing.T) {
+	tt := []struct {
+		in  string
+		out string
+	}{
+		// formatted multi-line class attributes
+		{
+			in: `
+				flex items-center
+				p-2 p-4
+				hover:bg-red-500
+			`,
+			out: "flex items-center p-4 hover:bg-red-500",
+		}, {
+			in:  "p-2\tp-4\r\nm-1",
+			out: "p-4 m-1",
+		},
+		// duplicates are dropped
+		{
+			in:  "card card p-2 card",
+			out: "card p-2",
+		}, {
+			in:  "flex\nflex\tp-2",
+			out: "flex p-2",
+		},
+	}
+	for _, tc := range tt {
+		got := Merge(tc.in)
+		if got != tc.out {
+			t.Errorf("Merge(%q) = %q, want %q", tc.in, got, tc.out)
+		}
+	}
+}
+
+func TestMergeOrder(t *testing.T) {
+	tt := []struct {
+		in  string
+		out string
+	}{
+		// kept classes stay at their position
+		{
+			in:  "text-red-500 bg-blue-300 text-xl",
+			out: "text-red-500 bg-blue-300 text-xl",
+		}, {
+			in:  "size-4 w-2",
+			out: "size-4 w-2",
+		},
+		// an overriding class takes the position of the last one
+		{
+			in:  "p-2 m-1 p-4 flex",
+			out: "m-1 p-4 flex",
+		}, {
+			in:  "px-2 custom py-4 p-1 grid",
+			out: "custom p-1 grid",
+		},
+	}
+	for _, tc := range tt {
+		// new mergers merge again instead of reading their cache
+		for range 20 {
+			merge := createTwMerge(defaultConfig, nil, nil)
+			if got := merge(tc.in); got != tc.out {
+				t.Fatalf("merge(%q) = %q, want %q", tc.in, got, tc.out)
+			}
+		}
+	}
+}
+
 func areStringsEqual(s1, s2 string) bool {
 	// Split each string into individual parts
 	parts1 := strings.Split(s1, " ")
@@ -553,9 +897,9 @@ func areStringsEqual(s1, s2 string) bool {
 
 func TestLint(t *testing.T) {
 	// Clear maps before testing
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
 	mapMutex.Lock()
-	ClassMapStr = make(map[string]string)
-	GenClassMergeStr = make(map[string]string)
 	classID = 0
 	mapMutex.Unlock()
 
@@ -564,17 +908,17 @@ func TestLint(t *testing.T) {
 		original string
 		merged   string
 	}{
-		{"bg-red-500 bg-blue-500", "bg-blue-500"},                          // Different colors, second wins
-		{"p-4 p-8", "p-8"},                                                 // Different padding sizes, second wins
-		{"m-2 mx-4", "m-2 mx-4"},                                           // No conflict, both remain
-		{"text-xl font-bold", "text-xl font-bold"},                         // No conflict, both remain
-		{"w-full w-1/2", "w-1/2"},                                          // Width conflict, second wins
-		{"bg-red-500 bg-red-600", "bg-red-600"},                            // Color conflict, second wins
-		{"border-2 border-red-500", "border-2 border-red-500"},             // No conflict, both remain
-		{"flex flex-col", "flex flex-col"},                                 // No conflict, both remain
-		{"hover:bg-blue-500 hover:bg-green-500", "hover:bg-green-500"},     // Hover variant conflict, second wins
-		{"bg-red-500 p-4 bg-blue-500", "bg-blue-500 p-4"},                  // Background color conflict, second wins
-		{"p-4 m-2 w-full w-1/2", "p-4 m-2 w-1/2"},                         // Width conflict, second wins
+		{"bg-red-500 bg-blue-500", "bg-blue-500"},                      // Different colors, second wins
+		{"p-4 p-8", "p-8"},                                             // Different padding sizes, second wins
+		{"m-2 mx-4", "m-2 mx-4"},                                       // No conflict, both remain
+		{"text-xl font-bold", "text-xl font-bold"},                     // No conflict, both remain
+		{"w-full w-1/2", "w-1/2"},                                      // Width conflict, second wins
+		{"bg-red-500 bg-red-600", "bg-red-600"},                        // Color conflict, second wins
+		{"border-2 border-red-500", "border-2 border-red-500"},         // No conflict, both remain
+		{"flex flex-col", "flex flex-col"},                             // No conflict, both remain
+		{"hover:bg-blue-500 hover:bg-green-500", "hover:bg-green-500"}, // Hover variant conflict, second wins
+		{"bg-red-500 p-4 bg-blue-500", "bg-blue-500 p-4"},              // Background color conflict, second wins
+		{"p-4 m-2 w-full w-1/2", "p-4 m-2 w-1/2"},                      // Width conflict, second wins
 	}
 
 	// Run Merge on each test case
@@ -590,14 +934,14 @@ func TestLint(t *testing.T) {
 		original string
 		expected string
 	}{
-		{"bg-red-500 bg-blue-500", "bg-blue-500"},          // Same as first case above
-		{"bg-yellow-500 bg-blue-500", "bg-blue-500"},       // Different but merges to same value
-		{"p-2 p-8", "p-8"},                                 // Same merged value as second case
-		{"m-2 p-8", "m-2 p-8"},                             // Unique merged value
-		{"text-sm text-lg text-xl", "text-xl"},             // Merges to text-xl
-		{"text-base text-lg text-xl", "text-xl"},           // Also merges to text-xl
-		{"inline block", "block"},                           // Merges to block
-		{"hidden block", "block"},                           // Also merges to block
+		{"bg-red-500 bg-blue-500", "bg-blue-500"},    // Same as first case above
+		{"bg-yellow-500 bg-blue-500", "bg-blue-500"}, // Different but merges to same value
+		{"p-2 p-8", "p-8"},                           // Same merged value as second case
+		{"m-2 p-8", "m-2 p-8"},                       // Unique merged value
+		{"text-sm text-lg text-xl", "text-xl"},       // Merges to text-xl
+		{"text-base text-lg text-xl", "text-xl"},     // Also merges to text-xl
+		{"inline block", "block"},                    // Merges to block
+		{"hidden block", "block"},                    // Also merges to block
 	}
 
 	// Run merge on the duplicates
@@ -619,35 +963,35 @@ func TestLint(t *testing.T) {
 	// Check if expected duplicates are reported
 	expectedDuplicates := map[string]bool{
 		"bg-blue-500": false,
-		"p-8": false,
-		"text-xl": false,
+		"p-8":         false,
+		"text-xl":     false,
 	}
-	
+
 	// Debug: Print all lint reports to help diagnose the issue
 	t.Logf("Number of lint reports: %d", len(lintResults))
 	for i, report := range lintResults {
 		t.Logf("Report %d: MergedValue=%s, OriginalClasses=%v", i, report.MergedValue, report.OriginalClasses)
-		
+
 		// Mark expected values as found
 		if _, exists := expectedDuplicates[report.MergedValue]; exists && len(report.OriginalClasses) >= 2 {
 			expectedDuplicates[report.MergedValue] = true
 		}
 	}
-	
+
 	// Check if all expected duplicates were found
 	for merged, found := range expectedDuplicates {
 		if !found {
 			t.Errorf("Lint did not report %s as having duplicate sources", merged)
 		}
 	}
-	
+
 	// Test LintString function
 	lintStr := LintString()
 	// Check if the string contains expected content
 	if !strings.Contains(lintStr, "Found") || !strings.Contains(lintStr, "cases where multiple class combinations merge") {
 		t.Error("LintString output was not formatted correctly")
 	}
-	
+
 	// Check that all expected duplicates are mentioned
 	for merged := range expectedDuplicates {
 		if !strings.Contains(lintStr, merged) {
@@ -655,3 +999,17 @@ func TestLint(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkMergeClassList(b *testing.B) {
+	conf := defaultConfig
+	mergeClassList := makeMergeClassList(conf, makeSplitModifiers(conf), makeGetClassGroupID(conf))
+	classLists := []string{
+		"flex items-center justify-between p-4 hover:bg-blue-600 md:px-6 p-2",
+		"text-sm font-medium text-gray-700 dark:text-gray-200 text-lg",
+		"grid grid-cols-3 gap-4 -translate-x-1/2 mt-[10px] shadow-[0_1px_2px_black]",
+	}
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		mergeClassList(classLists[i%len(classLists)])
+	}
+}
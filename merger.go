@@ -0,0 +1,184 @@
+package twerge
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Merger merges classes and generates class names independently of the
+// package-level state, so that several projects, or tests, can use twerge in
+// the same process.
+//
+// The package-level functions, like Merge, It and ExportCSS, make up the
+// default instance, backed by ClassMapStr and GenClassMergeStr.
+type Merger struct {
+	// merge merges classes without recording them
+	merge twMergeFn
+	// mu protects classMap, generated and classID
+	mu sync.RWMutex
+	// classMap maps original class strings to generated class names
+	classMap map[string]string
+	// generated maps generated class names to merged classes
+	generated map[string]string
+	// classID is the number of the next generated class name
+	classID int
+	// naming generates the class names
+	naming naming
+}
+
+// New returns a Merger using the given Config, or DefaultConfig if config is
+// nil.
+//
+//	m := twerge.New(nil)
+//	m.Generate("p-2 p-4") // "tw-0"
+//	m.CSS()               // ".tw-0 { \n\t@apply p-4; \n}\n"
+func New(config *Config) *Merger {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &Merger{
+		merge:     createTwMerge(config.build(), config.Cache, nil),
+		classMap:  make(map[string]string),
+		generated: make(map[string]string),
+		naming:    config.naming(),
+	}
+}
+
+// Merge merges the classes, removing the classes overridden by later ones.
+//
+// Unlike the package-level Merge, it does not record the class strings it
+// merges, see Generate.
+func (m *Merger) Merge(classes string) string {
+	return m.merge(classes)
+}
+
+// Generate returns a short unique class name for the merged classes, like It.
+func (m *Merger) Generate(classes string) string {
+	m.mu.RLock()
+	className, exists := m.classMap[classes]
+	m.mu.RUnlock()
+	if exists {
+		return className
+	}
+
+	merged := m.merge(classes)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if className, exists := m.classMap[classes]; exists {
+		return className
+	}
+	className = m.naming.className(&m.classID, merged, m.generated)
+	m.classMap[classes] = className
+	m.generated[className] = merged
+	return className
+}
+
+// If returns the class name of trueClass if cond is true, otherwise the class
+// name of falseClass.
+func (m *Merger) If(cond bool, trueClass, falseClass string) string {
+	if cond {
+		return m.Generate(trueClass)
+	}
+	return m.Generate(falseClass)
+}
+
+// RegisterClasses registers a mapping of original class strings to class
+// names, like RegisterClasses, so that Generate returns the registered names.
+func (m *Merger) RegisterClasses(classes map[string]string) {
+	merged := make(map[string]string, len(classes))
+	for original := range classes {
+		merged[original] = m.merge(original)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	registerClasses(m.classMap, m.generated, classes, merged)
+}
+
+// VerifyNoCollisions checks that no class name of m is used for class
+// strings merging to different classes, see VerifyNoCollisions.
+func (m *Merger) VerifyNoCollisions() error {
+	return findCollisions(m.ClassMap(), m.merge)
+}
+
+// ClassMap returns a copy of the mapping of original class strings to
+// generated class names, the counterpart of ClassMapStr.
+func (m *Merger) ClassMap() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.classMap)
+}
+
+// Rules returns a copy of the mapping of generated class names to merged
+// classes, the counterpart of GenClassMergeStr.
+func (m *Merger) Rules() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.generated)
+}
+
+// WriteCSS writes an @apply rule for every generated class name to w,
+// ordered by class name unless configured otherwise.
+func (m *Merger) WriteCSS(w io.Writer, opts ...MapOption) error {
+	o := newMapOptions(opts)
+	return o.writeRulesWithRaw(w, m.Rules(), nil)
+}
+
+// CSS returns the @apply rules written by WriteCSS.
+func (m *Merger) CSS(opts ...MapOption) string {
+	var builder strings.Builder
+	_ = m.WriteCSS(&builder, opts...)
+	return builder.String()
+}
+
+// ExportCSS writes the @apply rules of the generated class names between the
+// twerge markers of the file at cssPath, like ExportCSS.
+func (m *Merger) ExportCSS(cssPath string, opts ...MapOption) error {
+	content, err := os.ReadFile(cssPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading css file: %w", err)
+	}
+
+	layout, err := sectionLayout(content, []string{""})
+	if err != nil {
+		return fmt.Errorf("error adding twerge content: %w", err)
+	}
+	return writeTargets(Snapshot{}, []Target{{
+		Path: cssPath,
+		Write: func(w io.Writer, _ Snapshot) error {
+			return writeLayout(w, layout, func(w io.Writer, _ string) error {
+				return m.WriteCSS(w, opts...)
+			})
+		},
+	}})
+}
+
+// Snapshot returns a copy of the class maps of m for rendering targets.
+func (m *Merger) Snapshot() Snapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return Snapshot{
+		ClassMap: maps.Clone(m.classMap),
+		Rules:    maps.Clone(m.generated),
+	}
+}
+
+// GenerateTailwind writes the @apply rules of the generated class names into
+// the Tailwind input CSS at cssPath, and the targets, like GenerateTailwind.
+func (m *Merger) GenerateTailwind(cssPath string, targets ...Target) error {
+	return generateTailwind(cssPath, m.Snapshot(), renderRules, targets)
+}
+
+// Reset removes every generated and registered class name.
+func (m *Merger) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.classMap = make(map[string]string)
+	m.generated = make(map[string]string)
+	m.classID = 0
+}
@@ -0,0 +1,67 @@
+package twerge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerger(t *testing.T) {
+	before := getMapping()
+
+	a := New(nil)
+	b := New(&Config{TailwindVersion: TailwindV4, Conflicts: DefaultConflictConfig()})
+
+	assert.Equal(t, "p-4", a.Merge("p-2 p-4"))
+	assert.Equal(t, "tw-0", a.Generate("p-2 p-4"))
+	assert.Equal(t, "tw-0", a.Generate("p-2 p-4"))
+	assert.Equal(t, "tw-1", a.If(false, "flex", "block"))
+
+	// instances are independent of each other
+	assert.Equal(t, "tw-0", b.Generate("text-red-500 text-shadow-lg"))
+	assert.Equal(t, map[string]string{"tw-0": "p-4", "tw-1": "block"}, a.Rules())
+	assert.Equal(t, map[string]string{"text-red-500 text-shadow-lg": "tw-0"}, b.ClassMap())
+	assert.Equal(t, "text-red-500 text-shadow-lg", b.Rules()["tw-0"])
+
+	a.RegisterClasses(map[string]string{"m-1 m-2": "tw-margin"})
+	assert.Equal(t, "tw-margin", a.Generate("m-1 m-2"))
+	assert.Equal(t, ".tw-0 { \n\t@apply p-4; \n}\n.tw-1 { \n\t@apply block; \n}\n.tw-margin { \n\t@apply m-2; \n}\n", a.CSS())
+
+	// and of the package-level state
+	assert.Equal(t, before, getMapping())
+
+	cssPath := filepath.Join(t.TempDir(), "input.css")
+	assert.NoError(t, os.WriteFile(cssPath, []byte("@tailwind base;\n"+twergeBeginMarker+"\n"+twergeEndMarker+"\n"), 0644))
+	assert.NoError(t, a.ExportCSS(cssPath))
+	rules, err := ReadTailwindSection(cssPath)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Rules(), rules)
+
+	a.Reset()
+	assert.Empty(t, a.ClassMap())
+	assert.Equal(t, "tw-0", a.Generate("flex"))
+}
+
+func TestMergerConcurrentFirstCall(t *testing.T) {
+	SetConfig(DefaultConfig())
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	// the mergers are built by the first of the concurrent calls, which
+	// go test -race checks
+	m := New(nil)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "p-4", m.Merge("p-2 p-4"))
+			_, scope := NewScope(context.Background())
+			assert.Equal(t, "m-4", scope.Merge("m-2 m-4"))
+		}()
+	}
+	wg.Wait()
+}
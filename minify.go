@@ -0,0 +1,125 @@
+package twerge
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// WithMinify writes the stylesheet without comments and optional whitespace,
+// and groups rules with identical bodies under a single selector list, like
+// .tw-a,.tw-b{@apply p-4}.
+//
+// It shrinks stylesheets inlined into pages, like those of StyleTag, where
+// many class strings merge to the same classes. Grouping needs every rule, so
+// the stylesheet is built in memory before it is written.
+func WithMinify() MapOption {
+	return func(o *mapOptions) {
+		o.minify = true
+	}
+}
+
+// writeMinified writes the rules, mapping class names to classes, in the
+// order of names, minified and grouped by their bodies.
+func (o mapOptions) writeMinified(w io.Writer, names []string, rules, raw map[string]string) error {
+	var groups []string
+	selectors := make(map[string][]string)
+	var blocks []string
+	for _, className := range names {
+		var builder strings.Builder
+		err := o.writeRule(&builder, className, rules[className], raw[className])
+		if err != nil {
+			return err
+		}
+		css := minifyCSS(builder.String())
+
+		// only single blocks of the class alone are grouped, not media
+		// queries, variants or raw CSS
+		selector := "." + o.prefix + className
+		body, ok := strings.CutPrefix(css, selector)
+		if !ok || !strings.HasPrefix(body, "{") || strings.Count(body, "{") != 1 || !strings.HasSuffix(body, "}") {
+			groups = append(groups, "")
+			blocks = append(blocks, css)
+			continue
+		}
+		if _, seen := selectors[body]; !seen {
+			groups = append(groups, body)
+			blocks = append(blocks, "")
+		}
+		selectors[body] = append(selectors[body], selector)
+	}
+
+	for i, body := range groups {
+		css := blocks[i]
+		if body != "" {
+			css = strings.Join(selectors[body], ",") + body
+		}
+		_, err := io.WriteString(w, css)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minifyCSS removes the comments and empty blocks of css and the whitespace
+// not needed to separate tokens, keeping quoted strings as they are.
+func minifyCSS(css string) string {
+	out := make([]byte, 0, len(css))
+	// separate reports whether a space is needed between out and c, as
+	// a colon followed by whitespace is never a pseudo-class
+	separate := func(c byte) bool {
+		return len(out) > 0 && !isCSSDelimiter(out[len(out)-1]) && out[len(out)-1] != ':' && !isCSSDelimiter(c)
+	}
+	space := false
+	for i := 0; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				end = len(css)
+			}
+			i += end + 3
+			space = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			continue
+		}
+
+		if space && separate(c) {
+			out = append(out, ' ')
+		}
+		space = false
+		if c == '"' || c == '\'' {
+			end := i + 1
+			for end < len(css) && css[end] != c {
+				if css[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(css))
+			out = append(out, css[i:end]...)
+			i = end - 1
+			continue
+		}
+		// the last declaration of a block needs no semicolon
+		if c == '}' && len(out) > 0 && out[len(out)-1] == ';' {
+			out = out[:len(out)-1]
+		}
+		// empty blocks are removed with their selector
+		if c == '}' && len(out) > 0 && out[len(out)-1] == '{' {
+			out = out[:bytes.LastIndexAny(out[:len(out)-1], "{};")+1]
+			continue
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// isCSSDelimiter reports whether whitespace around c can be removed.
+func isCSSDelimiter(c byte) bool {
+	return c == '{' || c == '}' || c == ';' || c == ','
+}
@@ -0,0 +1,46 @@
+package twerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMinify(t *testing.T) {
+	classMap := map[string]string{
+		"p-2 p-4": "tw-a",
+		"flex":    "tw-b",
+		"p-4":     "tw-c",
+	}
+
+	var builder strings.Builder
+	err := WriteCSS(&builder, classMap, WithMinify(), WithPrefix("app-"))
+	assert.NoError(t, err)
+	assert.Equal(t, ".app-tw-a,.app-tw-c{@apply p-4}.app-tw-b{@apply flex}", builder.String())
+
+	// rules with variants are minified but not grouped
+	builder.Reset()
+	err = WriteCSS(&builder, map[string]string{"p-4": "tw-a", "md:p-4": "tw-b", "p-2 p-4": "tw-c"},
+		WithMinify(), WithStandaloneCSS())
+	assert.NoError(t, err)
+	assert.Equal(t,
+		".tw-a,.tw-c{padding:1rem}"+
+			"@media (min-width:768px){.tw-b{padding:1rem}}",
+		builder.String(),
+	)
+}
+
+func TestMinifyCSS(t *testing.T) {
+	for _, tc := range []struct {
+		css, want string
+	}{
+		{css: ".a { \n\t@apply p-4 hover:bg-red-500; \n}\n", want: ".a{@apply p-4 hover:bg-red-500}"},
+		{css: "/* comment */ .a ,\n.b > .c { color: red; }", want: ".a,.b > .c{color:red}"},
+		{css: ".a::after { content: \"a  ;  b\"; }", want: `.a::after{content:"a  ;  b"}`},
+		{css: ":where(.dark, .dark *) .a { color: red }", want: ":where(.dark,.dark *) .a{color:red}"},
+		{css: ".a { }\n@media print { .b { } }\n.c { top: 0 }", want: ".c{top:0}"},
+	} {
+		assert.Equal(t, tc.want, minifyCSS(tc.css), tc.css)
+	}
+}
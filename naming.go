@@ -0,0 +1,272 @@
+package twerge
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// NamingStrategy selects how generated class names are derived.
+type NamingStrategy int
+
+const (
+	// NamingSequential numbers class names in the order they are generated,
+	// like tw-0, tw-1 and so on.
+	NamingSequential NamingStrategy = iota
+	// NamingSHA1 names classes after the SHA-1 hash of their merged classes.
+	NamingSHA1
+	// NamingSHA256 names classes after the SHA-256 hash of their merged
+	// classes.
+	NamingSHA256
+	// NamingXXHash names classes after the 64-bit xxHash of their merged
+	// classes.
+	NamingXXHash
+	// NamingReadable names classes after their dominant utility, the first
+	// of their sorted classes without variants, like tw-flex for "p-4 flex".
+	// Names are numbered on conflict, like tw-flex-2.
+	NamingReadable
+)
+
+// Namer derives class names from merged classes, for strategies not covered
+// by NamingStrategy, see Config.Namer.
+type Namer interface {
+	// ClassName returns a candidate name, without the class prefix, of the
+	// merged classes. It is called with attempt 0, 1 and so on until the
+	// candidate is not taken by different merged classes, and must return
+	// distinct candidates for distinct attempts.
+	ClassName(merged string, attempt int) string
+}
+
+const (
+	// DefaultClassPrefix is the prefix of generated class names
+	DefaultClassPrefix = "tw-"
+	// DefaultHashLength is the number of hash characters of hashed class
+	// names
+	DefaultHashLength = 8
+)
+
+// String returns the name of the strategy as used in configuration files.
+func (s NamingStrategy) String() string {
+	switch s {
+	case NamingSHA1:
+		return "sha1"
+	case NamingSHA256:
+		return "sha256"
+	case NamingXXHash:
+		return "xxhash"
+	case NamingReadable:
+		return "readable"
+	default:
+		return "sequential"
+	}
+}
+
+// ParseNamingStrategy returns the strategy named s, one of "sequential",
+// "sha1", "sha256", "xxhash" or "readable".
+func ParseNamingStrategy(s string) (NamingStrategy, error) {
+	for _, strategy := range []NamingStrategy{NamingSequential, NamingSHA1, NamingSHA256, NamingXXHash, NamingReadable} {
+		if strategy.String() == s {
+			return strategy, nil
+		}
+	}
+	return NamingSequential, fmt.Errorf("unknown naming strategy %q", s)
+}
+
+// naming generates class names
+type naming struct {
+	prefix   string
+	strategy NamingStrategy
+	length   int
+	// namer, if not nil, replaces the strategy
+	namer Namer
+	// dedupe reuses the class name of equal merged classes
+	dedupe bool
+}
+
+// identifierRegex matches the runs of characters other than letters, digits
+// and underscores, joined by a single hyphen in readable class names
+var identifierRegex = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// defaultNaming is the naming of DefaultConfig
+var defaultNaming = naming{prefix: DefaultClassPrefix, length: DefaultHashLength}
+
+// classNaming is the naming of It and Merge, protected by mapMutex
+var classNaming = defaultNaming
+
+// naming returns the naming of class names generated with c.
+func (c *Config) naming() naming {
+	n := naming{prefix: c.ClassPrefix, strategy: c.Naming, length: c.HashLength, namer: c.Namer, dedupe: c.Dedupe}
+	if n.namer == nil && n.strategy == NamingReadable {
+		n.namer = readableNamer{}
+	}
+	if n.prefix == "" {
+		n.prefix = DefaultClassPrefix
+	}
+	if n.length <= 0 {
+		n.length = DefaultHashLength
+	}
+	return n
+}
+
+// className returns a class name for merged not used by another merged class
+// string in generated.
+//
+// Sequential names advance id past the names taken. Hashed names are
+// lengthened on conflict, up to the full hash, and then get a counter
+// appended. A Namer is asked for candidates until one is free.
+//
+// With dedupe, the smallest class name generated for equal merged classes is
+// returned instead, if any.
+func (n naming) className(id *int, merged string, generated map[string]string) string {
+	if n.dedupe {
+		if className, ok := n.existing(merged, generated); ok {
+			return className
+		}
+	}
+	if n.sequential() {
+		for {
+			className := n.prefix + strconv.Itoa(*id)
+			*id++
+			if _, taken := generated[className]; !taken {
+				return className
+			}
+		}
+	}
+
+	normalized := normalizeMerged(merged)
+	free := func(className string) bool {
+		value, taken := generated[className]
+		return !taken || normalizeMerged(value) == normalized
+	}
+	if n.namer != nil {
+		for attempt := 0; ; attempt++ {
+			if className := n.prefix + n.namer.ClassName(normalized, attempt); free(className) {
+				return className
+			}
+		}
+	}
+	sum := n.hash(normalized)
+	for length := min(n.length, len(sum)); length <= len(sum); length++ {
+		if className := n.prefix + sum[:length]; free(className) {
+			return className
+		}
+	}
+	for counter := 1; ; counter++ {
+		if className := n.prefix + sum + "-" + strconv.Itoa(counter); free(className) {
+			return className
+		}
+	}
+}
+
+// ClassName returns the class name the merged classes get with the naming
+// of SetConfig when no other class string takes it, without generating or
+// recording anything, so tools and templating helpers can predict generated
+// names:
+//
+//	twerge.SetConfig(&twerge.Config{Naming: twerge.NamingXXHash, Conflicts: twerge.DefaultConflictConfig()})
+//	twerge.ClassName(twerge.Merge("p-2 p-4 flex")) // "tw-" + twerge.Hash("flex p-4")[:8], true
+//
+// The order of merged does not matter. Sequential names depend on the order
+// class strings are generated in, so with NamingSequential, the default, ok
+// is false. A name taken by other classes is lengthened or numbered by It,
+// see Config.Naming.
+func ClassName(merged string) (className string, ok bool) {
+	mapMutex.RLock()
+	n := classNaming
+	mapMutex.RUnlock()
+	if n.sequential() {
+		return "", false
+	}
+	id := 0
+	return n.className(&id, merged, nil), true
+}
+
+// Hash returns the hex encoded hash of s that hashed class names are cut
+// from: the SHA-1 or SHA-256 hash with NamingSHA1 or NamingSHA256 set by
+// SetConfig, and the 64-bit xxHash otherwise. Class names hash the merged
+// classes sorted and joined by single spaces.
+func Hash(s string) string {
+	mapMutex.RLock()
+	n := classNaming
+	mapMutex.RUnlock()
+	return n.hash(s)
+}
+
+// existing returns the smallest class name with the prefix of n in generated
+// whose merged classes equal merged.
+func (n naming) existing(merged string, generated map[string]string) (string, bool) {
+	normalized := normalizeMerged(merged)
+	found := ""
+	for className, value := range generated {
+		// merged classes are joined by single spaces, so equal classes
+		// have equal lengths, which skips most normalizations
+		if len(value) != len(merged) || (found != "" && className > found) || !strings.HasPrefix(className, n.prefix) {
+			continue
+		}
+		if normalizeMerged(value) == normalized {
+			found = className
+		}
+	}
+	return found, found != ""
+}
+
+// sequential reports whether class names are numbered in the order they are
+// generated.
+func (n naming) sequential() bool {
+	return n.namer == nil && n.strategy == NamingSequential
+}
+
+// hash returns the hex encoded hash of s.
+func (n naming) hash(s string) string {
+	switch n.strategy {
+	case NamingSHA1:
+		sum := sha1.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	case NamingSHA256:
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	default:
+		return fmt.Sprintf("%016x", xxhash.Sum64String(s))
+	}
+}
+
+// normalizeMerged returns merged with its classes sorted, so merged classes
+// differing only in their order, which apply the same styles, compare equal.
+func normalizeMerged(merged string) string {
+	fields := strings.Fields(merged)
+	slices.Sort(fields)
+	return strings.Join(fields, " ")
+}
+
+// readableNamer is the Namer of NamingReadable
+type readableNamer struct{}
+
+// ClassName implements Namer.
+func (readableNamer) ClassName(merged string, attempt int) string {
+	classes := strings.Fields(merged)
+	dominant := "x"
+	if len(classes) > 0 {
+		dominant = classes[0]
+	}
+	for _, class := range classes {
+		if !strings.Contains(class, ":") {
+			dominant = class
+			break
+		}
+	}
+	name := strings.Trim(identifierRegex.ReplaceAllString(dominant, "-"), "-")
+	if name == "" {
+		name = "x"
+	}
+	if attempt > 0 {
+		name += "-" + strconv.Itoa(attempt+1)
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+package twerge
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaming(t *testing.T) {
+	m := New(&Config{Conflicts: DefaultConflictConfig(), ClassPrefix: "app-"})
+	assert.Equal(t, "app-0", m.Generate("p-2 p-4"))
+
+	for _, strategy := range []NamingStrategy{NamingSHA1, NamingSHA256, NamingXXHash} {
+		m := New(&Config{Conflicts: DefaultConflictConfig(), Naming: strategy, HashLength: 6})
+		className := m.Generate("p-2 p-4")
+		assert.Regexp(t, `^tw-[0-9a-f]{6}$`, className, strategy.String())
+		// the name depends on the merged classes only
+		assert.Equal(t, className, m.Generate("p-4"), strategy.String())
+		assert.Equal(t, className, New(&Config{Naming: strategy, HashLength: 6}).Generate("p-4"), strategy.String())
+
+		parsed, err := ParseNamingStrategy(strategy.String())
+		assert.NoError(t, err)
+		assert.Equal(t, strategy, parsed)
+	}
+	_, err := ParseNamingStrategy("md5")
+	assert.Error(t, err)
+
+	// colliding hashes are lengthened, then numbered
+	n := naming{prefix: "tw-", strategy: NamingXXHash, length: 4}
+	sum := n.hash("p-4")
+	id := 0
+	assert.Equal(t, "tw-"+sum[:5], n.className(&id, "p-4", map[string]string{"tw-" + sum[:4]: "m-4"}))
+	taken := map[string]string{}
+	for length := 4; length <= len(sum); length++ {
+		taken["tw-"+sum[:length]] = "m-4"
+	}
+	assert.Equal(t, "tw-"+sum+"-1", n.className(&id, "p-4", taken))
+}
+
+// lengthNamer names classes after the number of their classes
+type lengthNamer struct{}
+
+func (lengthNamer) ClassName(merged string, attempt int) string {
+	return fmt.Sprintf("n%d-%d", len(strings.Fields(merged)), attempt)
+}
+
+func TestNamingReadable(t *testing.T) {
+	m := New(&Config{Conflicts: DefaultConflictConfig(), Naming: NamingReadable})
+	assert.Equal(t, "tw-flex", m.Generate("p-4 flex"))
+	assert.Equal(t, "tw-flex", m.Generate("flex p-2 p-4"))
+	assert.Equal(t, "tw-flex-2", m.Generate("flex m-2"))
+	assert.Equal(t, "tw-bg-red-500-50", m.Generate("hover:underline bg-red-500/50"))
+	assert.Equal(t, "tw-mt-4", m.Generate("-mt-4"))
+	assert.Equal(t, "tw-hover-underline", m.Generate("hover:underline"))
+	assert.Equal(t, "tw-w-1-2", m.Generate("w-[1/2]"))
+
+	parsed, err := ParseNamingStrategy("readable")
+	assert.NoError(t, err)
+	assert.Equal(t, NamingReadable, parsed)
+
+	m = New(&Config{Conflicts: DefaultConflictConfig(), ClassPrefix: "x-", Namer: lengthNamer{}})
+	assert.Equal(t, "x-n2-0", m.Generate("p-4 flex"))
+	assert.Equal(t, "x-n2-1", m.Generate("m-4 flex"))
+	assert.Equal(t, "x-n2-0", m.Generate("flex p-4"))
+}
+
+func TestNamingDedupe(t *testing.T) {
+	m := New(&Config{Conflicts: DefaultConflictConfig(), Dedupe: true})
+	assert.Equal(t, "tw-0", m.Generate("p-2 p-4"))
+	assert.Equal(t, "tw-0", m.Generate("p-4"))
+	assert.Equal(t, "tw-1", m.Generate("flex m-2"))
+	assert.Equal(t, "tw-1", m.Generate("m-2 flex"))
+	assert.Equal(t, "tw-1", m.Generate("m-4 m-2 flex"))
+	assert.Len(t, m.ClassMap(), 5)
+	assert.Len(t, m.Rules(), 2)
+
+	// without Dedupe every class string gets its own sequential name
+	m = New(&Config{Conflicts: DefaultConflictConfig()})
+	assert.Equal(t, "tw-0", m.Generate("p-2 p-4"))
+	assert.Equal(t, "tw-1", m.Generate("p-4"))
+}
+
+func TestClassName(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	// sequential names can not be predicted
+	_, ok := ClassName("p-4 flex")
+	assert.False(t, ok)
+	assert.Regexp(t, `^[0-9a-f]{16}$`, Hash("p-4"))
+
+	SetConfig(&Config{Conflicts: DefaultConflictConfig(), Naming: NamingXXHash})
+	className, ok := ClassName("p-4 flex")
+	assert.True(t, ok)
+	assert.Equal(t, "tw-"+Hash("flex p-4")[:DefaultHashLength], className)
+
+	SetConfig(&Config{Conflicts: DefaultConflictConfig(), Naming: NamingSHA256, HashLength: 6, ClassPrefix: "x-"})
+	assert.Regexp(t, `^[0-9a-f]{64}$`, Hash("p-4"))
+	className, _ = ClassName(Merge("p-2 p-4 flex"))
+	assert.Equal(t, "x-"+Hash("flex p-4")[:6], className)
+	assert.Equal(t, className, New(&Config{Conflicts: DefaultConflictConfig(), Naming: NamingSHA256, HashLength: 6, ClassPrefix: "x-"}).Generate("flex p-4"))
+
+	SetConfig(&Config{Conflicts: DefaultConflictConfig(), Naming: NamingReadable})
+	className, _ = ClassName("p-4 flex")
+	assert.Equal(t, "tw-flex", className)
+
+	// nothing is generated
+	mapMutex.RLock()
+	assert.NotContains(t, GenClassMergeStr, "tw-flex")
+	mapMutex.RUnlock()
+}
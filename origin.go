@@ -0,0 +1,114 @@
+package twerge
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Origin tells where a registered class comes from.
+type Origin int
+
+const (
+	// OriginApp marks classes of the application. It is the origin of every
+	// class not registered otherwise.
+	OriginApp Origin = iota
+	// OriginVendor marks classes of library packages, such as component
+	// libraries, which change less often than the application.
+	OriginVendor
+)
+
+// String returns the bundle name of the origin.
+func (o Origin) String() string {
+	switch o {
+	case OriginApp:
+		return "app"
+	case OriginVendor:
+		return "vendor"
+	default:
+		return fmt.Sprintf("Origin(%d)", int(o))
+	}
+}
+
+// vendorClasses is the set of generated class names registered by vendors
+// It is protected by mapMutex for concurrent access
+var vendorClasses = make(map[string]bool)
+
+// markOrigin records the origin of the generated class name.
+//
+// A class once registered by a vendor stays in the vendor bundle, as that
+// bundle is loaded alongside the application bundle.
+func markOrigin(origin Origin, className string) {
+	if origin != OriginVendor {
+		return
+	}
+	mapMutex.Lock()
+	vendorClasses[className] = true
+	mapMutex.Unlock()
+}
+
+// ItFrom is like It but records the origin of the generated class.
+//
+// Library packages use it to keep their classes out of the application
+// bundle:
+//
+//	<button class={ twerge.ItFrom(twerge.OriginVendor, "px-4 py-2 rounded") }></button>
+func ItFrom(origin Origin, classes string) string {
+	className := It(classes)
+	markOrigin(origin, className)
+	return className
+}
+
+// RegisterClassesFrom is like RegisterClasses but also records the origin of
+// every registered class name.
+func RegisterClassesFrom(origin Origin, classes map[string]string) {
+	RegisterClasses(classes)
+	for _, className := range classes {
+		markOrigin(origin, className)
+	}
+}
+
+// OriginOf returns the origin of the generated class name.
+func OriginOf(className string) Origin {
+	mapMutex.RLock()
+	defer mapMutex.RUnlock()
+	if vendorClasses[className] {
+		return OriginVendor
+	}
+	return OriginApp
+}
+
+// WriteOriginCSS writes the @apply rules for every generated class of the
+// given origin to w.
+func WriteOriginCSS(w io.Writer, origin Origin, opts ...MapOption) error {
+	return writeGenClasses(w, func(className string) bool {
+		return vendorClasses[className] == (origin == OriginVendor)
+	}, opts)
+}
+
+// GenerateOriginBundles writes the generated classes into dir as vendor.css
+// and app.css, so the rarely changing vendor stylesheet can be cached for long
+// while the application stylesheet changes with every release.
+func GenerateOriginBundles(dir string, opts ...MapOption) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating bundle directory: %w", err)
+	}
+
+	for _, origin := range []Origin{OriginVendor, OriginApp} {
+		var builder strings.Builder
+		err = WriteOriginCSS(&builder, origin, opts...)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, origin.String()+".css")
+		err = os.WriteFile(path, []byte(builder.String()), 0644)
+		if err != nil {
+			return fmt.Errorf("error writing bundle %s: %w", path, err)
+		}
+	}
+	return nil
+}
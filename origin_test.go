@@ -0,0 +1,44 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginBundles(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	vendorClasses = make(map[string]bool)
+	mapMutex.Unlock()
+
+	RegisterClassesFrom(OriginVendor, map[string]string{"px-4 py-2 rounded": "tw-button"})
+	RegisterClasses(map[string]string{"p-2 p-4": "tw-page"})
+	card := ItFrom(OriginVendor, "shadow-sm rounded-lg")
+
+	assert.Equal(t, OriginVendor, OriginOf("tw-button"))
+	assert.Equal(t, OriginVendor, OriginOf(card))
+	assert.Equal(t, OriginApp, OriginOf("tw-page"))
+
+	// the application using a vendor class keeps it in the vendor bundle
+	ItFrom(OriginApp, "px-4 py-2 rounded")
+	assert.Equal(t, OriginVendor, OriginOf("tw-button"))
+
+	dir := t.TempDir()
+	assert.NoError(t, GenerateOriginBundles(dir))
+
+	vendor, err := os.ReadFile(filepath.Join(dir, "vendor.css"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(vendor), ".tw-button {")
+	assert.Contains(t, string(vendor), "."+card+" {")
+	assert.NotContains(t, string(vendor), ".tw-page")
+
+	app, err := os.ReadFile(filepath.Join(dir, "app.css"))
+	assert.NoError(t, err)
+	assert.Equal(t, ".tw-page { \n\t@apply p-4; \n}\n", string(app))
+}
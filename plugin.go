@@ -0,0 +1,74 @@
+package twerge
+
+import (
+	"slices"
+	"strings"
+)
+
+// pluginGroupPrefix keeps plugin group IDs apart from Tailwind class groups
+const pluginGroupPrefix = "plugin:"
+
+// PluginGroup groups classes of a Tailwind plugin twerge does not know, like
+// the prose classes of @tailwindcss/typography or the btn classes of a
+// component library.
+//
+// Unknown classes are otherwise passed through as they are, so conflicting
+// plugin classes are all kept.
+type PluginGroup struct {
+	// ID names the group. Classes of the same group and with the same
+	// variants conflict, so only the last one is kept.
+	ID string
+	// Patterns match the classes of the group. A pattern ending in "*"
+	// matches a class prefix, so "btn-*" matches "btn-primary". Any other
+	// pattern must match the class exactly.
+	Patterns []string
+	// Preserve keeps every class of the group, only removing exact
+	// duplicates, instead of keeping the last one.
+	Preserve bool
+}
+
+// DefaultPluginGroups returns plugin groups for @tailwindcss/typography,
+// where the prose size modifiers conflict with each other and every other
+// prose class is preserved.
+func DefaultPluginGroups() []PluginGroup {
+	return []PluginGroup{
+		{
+			ID:       "prose-size",
+			Patterns: []string{"prose-sm", "prose-base", "prose-lg", "prose-xl", "prose-2xl"},
+		},
+		{
+			ID:       "prose",
+			Patterns: []string{"prose", "prose-*"},
+			Preserve: true,
+		},
+	}
+}
+
+// SetPluginGroups replaces Merge with a merger grouping unknown classes by
+// the given plugin groups. A class belongs to the first group matching it.
+//
+//	twerge.SetPluginGroups(append(twerge.DefaultPluginGroups(),
+//		twerge.PluginGroup{ID: "btn", Patterns: []string{"btn-*"}},
+//	)...)
+//
+// Like SetConflictConfig, it resets the merge cache and is not safe to call
+// concurrently with Merge, so it should be called once at program start.
+func SetPluginGroups(groups ...PluginGroup) {
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	settings.PluginGroups = slices.Clone(groups)
+	rebuildMerge()
+}
+
+// matchPluginGroup returns the first plugin group matching the class.
+func matchPluginGroup(groups []PluginGroup, class string) (PluginGroup, bool) {
+	for _, group := range groups {
+		for _, pattern := range group.Patterns {
+			prefix, isPrefix := strings.CutSuffix(pattern, "*")
+			if class == pattern || (isPrefix && strings.HasPrefix(class, prefix)) {
+				return group, true
+			}
+		}
+	}
+	return PluginGroup{}, false
+}
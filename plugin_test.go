@@ -0,0 +1,32 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginGroups(t *testing.T) {
+	defer SetPluginGroups()
+
+	// unknown classes are passed through by default
+	assert.Equal(t, "btn-primary btn-secondary", Merge("btn-primary btn-secondary"))
+
+	SetPluginGroups(append(DefaultPluginGroups(),
+		PluginGroup{ID: "btn", Patterns: []string{"btn-*"}},
+	)...)
+
+	assert.Equal(t, "btn-secondary", Merge("btn-primary btn-secondary"))
+	assert.Equal(t, "btn-primary md:btn-secondary", Merge("btn-primary md:btn-secondary"))
+	assert.Equal(t, "prose prose-xl", Merge("prose prose-lg prose prose-xl"))
+	assert.Equal(t, "prose-invert prose dark:prose-invert", Merge("prose prose-invert prose dark:prose-invert"))
+	assert.Equal(t, "btn-primary p-4", Merge("p-2 btn-primary p-4"))
+
+	// conflict settings are kept
+	c := DefaultConflictConfig()
+	c.LineClampDisplay = false
+	SetConflictConfig(c)
+	defer SetConflictConfig(DefaultConflictConfig())
+	assert.Equal(t, "btn-secondary", Merge("btn-primary btn-secondary"))
+	assert.Equal(t, "block line-clamp-2", Merge("block line-clamp-2"))
+}
@@ -0,0 +1,67 @@
+// Package prometheus exports the counters of twerge.Stats as Prometheus
+// metrics:
+//
+//	import twergeprom "github.com/conneroisu/twerge/prometheus"
+//
+//	prometheus.MustRegister(twergeprom.NewCollector())
+package prometheus
+
+import (
+	"github.com/conneroisu/twerge"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHitsDesc = prometheus.NewDesc(
+		"twerge_merge_cache_hits_total",
+		"Number of merges answered by the merge cache.",
+		nil, nil,
+	)
+	cacheMissesDesc = prometheus.NewDesc(
+		"twerge_merge_cache_misses_total",
+		"Number of merges computed as their result was not cached.",
+		nil, nil,
+	)
+	cacheEvictionsDesc = prometheus.NewDesc(
+		"twerge_merge_cache_evictions_total",
+		"Number of results dropped by the merge cache to make room for new ones.",
+		nil, nil,
+	)
+	generatedDesc = prometheus.NewDesc(
+		"twerge_generated_classes_total",
+		"Number of class names generated at runtime.",
+		nil, nil,
+	)
+	mapSizeDesc = prometheus.NewDesc(
+		"twerge_class_map_size",
+		"Number of class strings in the class map.",
+		nil, nil,
+	)
+)
+
+// Collector is a prometheus.Collector reading twerge.Stats on every scrape.
+type Collector struct{}
+
+// NewCollector returns a Collector of the twerge counters.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEvictionsDesc
+	ch <- generatedDesc
+	ch <- mapSizeDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := twerge.Stats()
+	ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.CacheHits))
+	ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.CacheMisses))
+	ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.CacheEvictions))
+	ch <- prometheus.MustNewConstMetric(generatedDesc, prometheus.CounterValue, float64(stats.Generated))
+	ch <- prometheus.MustNewConstMetric(mapSizeDesc, prometheus.GaugeValue, float64(stats.MapSize))
+}
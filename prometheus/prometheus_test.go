@@ -0,0 +1,35 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/conneroisu/twerge"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	twerge.SetMapping(map[string]string{"p-2 p-4": "tw-pad", "flex": "tw-flex"})
+	t.Cleanup(func() { twerge.SetMapping(nil) })
+
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(NewCollector()))
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	values := make(map[string]float64, len(families))
+	for _, family := range families {
+		metric := family.GetMetric()[0]
+		if family.GetName() == "twerge_class_map_size" {
+			values[family.GetName()] = metric.GetGauge().GetValue()
+			continue
+		}
+		values[family.GetName()] = metric.GetCounter().GetValue()
+	}
+	assert.Len(t, values, 5)
+	assert.Contains(t, values, "twerge_merge_cache_hits_total")
+	assert.Contains(t, values, "twerge_merge_cache_misses_total")
+	assert.Contains(t, values, "twerge_merge_cache_evictions_total")
+	assert.Contains(t, values, "twerge_generated_classes_total")
+	assert.Equal(t, float64(2), values["twerge_class_map_size"])
+}
@@ -0,0 +1,41 @@
+package twerge
+
+import (
+	"maps"
+	"strings"
+)
+
+// rawCSS maps a generated class name to handwritten CSS emitted with its rule
+// It is protected by mapMutex for concurrent access
+var rawCSS = make(map[string]string)
+
+// RegisterRawCSS attaches handwritten CSS, such as keyframes or selectors
+// too complex for classes, to the generated class name.
+//
+// The CSS is written verbatim after the @apply rule of the class, inside the
+// twerge section, and only while the class is generated, so it is removed
+// together with the class. Registering empty CSS removes it.
+//
+//	twerge.RegisterRawCSS("tw-spinner", `@keyframes tw-spinner-spin {
+//		to { transform: rotate(360deg); }
+//	}
+//	.tw-spinner > svg { animation: tw-spinner-spin 1s linear infinite; }`)
+func RegisterRawCSS(className, css string) {
+	css = strings.TrimSpace(css)
+
+	mapMutex.Lock()
+	defer mapMutex.Unlock()
+	if css == "" {
+		delete(rawCSS, className)
+	} else {
+		rawCSS[className] = css
+	}
+	mapVersion.Add(1)
+}
+
+// registeredRawCSS returns a copy of the raw CSS registered by class name.
+func registeredRawCSS() map[string]string {
+	mapMutex.RLock()
+	defer mapMutex.RUnlock()
+	return maps.Clone(rawCSS)
+}
@@ -0,0 +1,50 @@
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterRawCSS(t *testing.T) {
+	SetMapping(map[string]string{"animate-spin": "tw-spinner", "text-sm": "tw-text"})
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	rawCSS = make(map[string]string)
+	mapMutex.Unlock()
+	defer RegisterRawCSS("tw-spinner", "")
+
+	raw := ".tw-spinner > svg { \n\tcolor: red;\n}\n@keyframes tw-spin { to { rotate: 1turn; } }"
+	RegisterRawCSS("tw-spinner", raw)
+
+	var builder strings.Builder
+	assert.NoError(t, WriteGeneratedCSS(&builder))
+	assert.Equal(t,
+		".tw-spinner { \n\t@apply animate-spin; \n}\n"+raw+"\n"+
+			".tw-text { \n\t@apply text-sm; \n}\n",
+		builder.String(),
+	)
+
+	cssPath := filepath.Join(t.TempDir(), "input.css")
+	assert.NoError(t, GenerateTailwind(cssPath))
+	generated, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(generated), builder.String())
+
+	// patching keeps the raw CSS with its rule
+	assert.NoError(t, PatchTailwind(cssPath))
+	patched, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(generated), string(patched))
+
+	// the raw CSS is removed with its class
+	SetMapping(map[string]string{"text-sm": "tw-text"})
+	assert.NoError(t, PatchTailwind(cssPath))
+	patched, err = os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(patched), "tw-spin")
+	assert.Contains(t, string(patched), ".tw-text {")
+}
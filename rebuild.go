@@ -0,0 +1,144 @@
+package twerge
+
+import (
+	"sync"
+	"time"
+)
+
+// RebuildEventKind is the kind of a RebuildEvent.
+type RebuildEventKind int
+
+const (
+	// RebuildStart is emitted right before the build function runs.
+	RebuildStart RebuildEventKind = iota
+	// RebuildFinish is emitted after the build function succeeded.
+	RebuildFinish
+	// RebuildError is emitted after the build function failed.
+	RebuildError
+)
+
+// String returns the name of the event kind.
+func (k RebuildEventKind) String() string {
+	switch k {
+	case RebuildStart:
+		return "start"
+	case RebuildFinish:
+		return "finish"
+	case RebuildError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// RebuildEvent describes a step of a rebuild run by a Rebuilder.
+type RebuildEvent struct {
+	// Kind is the kind of the event
+	Kind RebuildEventKind
+	// Err is the error returned by the build function for RebuildError events
+	Err error
+	// Duration is how long the build took, zero for RebuildStart events
+	Duration time.Duration
+}
+
+// Rebuilder coalesces rapid calls to Trigger into a single run of a build
+// function once no trigger has happened for a quiet period.
+//
+// It is the building block for watch modes: call Trigger on every file change
+// and the build function runs once after the changes settle. Triggers that
+// arrive while a build is running schedule exactly one follow-up build.
+type Rebuilder struct {
+	quiet   time.Duration
+	build   func() error
+	onEvent func(RebuildEvent)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	running bool
+	pending bool
+	stopped bool
+}
+
+// NewRebuilder creates a Rebuilder that runs build after quiet has elapsed
+// since the last Trigger.
+//
+// onEvent, if not nil, is called synchronously for every RebuildEvent.
+func NewRebuilder(
+	quiet time.Duration,
+	build func() error,
+	onEvent func(RebuildEvent),
+) *Rebuilder {
+	if onEvent == nil {
+		onEvent = func(RebuildEvent) {}
+	}
+	return &Rebuilder{
+		quiet:   quiet,
+		build:   build,
+		onEvent: onEvent,
+	}
+}
+
+// Trigger schedules a build after the quiet period, restarting the quiet
+// period if a build is already scheduled.
+func (r *Rebuilder) Trigger() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+	if r.running {
+		r.pending = true
+		return
+	}
+	r.schedule()
+}
+
+// Stop cancels any scheduled build and ignores future triggers.
+//
+// A build that is already running is allowed to finish.
+func (r *Rebuilder) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	r.pending = false
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// schedule (re)starts the quiet period timer. r.mu must be held.
+func (r *Rebuilder) schedule() {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(r.quiet, r.run)
+}
+
+// run executes the build function and emits its events.
+func (r *Rebuilder) run() {
+	r.mu.Lock()
+	if r.stopped || r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	r.onEvent(RebuildEvent{Kind: RebuildStart})
+	start := time.Now()
+	err := r.build()
+	duration := time.Since(start)
+	if err != nil {
+		r.onEvent(RebuildEvent{Kind: RebuildError, Err: err, Duration: duration})
+	} else {
+		r.onEvent(RebuildEvent{Kind: RebuildFinish, Duration: duration})
+	}
+
+	r.mu.Lock()
+	r.running = false
+	if r.pending && !r.stopped {
+		r.pending = false
+		r.schedule()
+	}
+	r.mu.Unlock()
+}
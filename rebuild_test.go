@@ -0,0 +1,59 @@
+package twerge
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuilderCoalesces(t *testing.T) {
+	var builds atomic.Int32
+	events := make(chan RebuildEvent, 10)
+	r := NewRebuilder(20*time.Millisecond, func() error {
+		builds.Add(1)
+		return nil
+	}, func(e RebuildEvent) { events <- e })
+	defer r.Stop()
+
+	for range 5 {
+		r.Trigger()
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	assert.Equal(t, RebuildStart, (<-events).Kind)
+	assert.Equal(t, RebuildFinish, (<-events).Kind)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), builds.Load())
+}
+
+func TestRebuilderError(t *testing.T) {
+	buildErr := errors.New("boom")
+	events := make(chan RebuildEvent, 10)
+	r := NewRebuilder(time.Millisecond, func() error {
+		return buildErr
+	}, func(e RebuildEvent) { events <- e })
+	defer r.Stop()
+
+	r.Trigger()
+	assert.Equal(t, RebuildStart, (<-events).Kind)
+	e := <-events
+	assert.Equal(t, RebuildError, e.Kind)
+	assert.ErrorIs(t, e.Err, buildErr)
+}
+
+func TestRebuilderStop(t *testing.T) {
+	var builds atomic.Int32
+	r := NewRebuilder(10*time.Millisecond, func() error {
+		builds.Add(1)
+		return nil
+	}, nil)
+
+	r.Trigger()
+	r.Stop()
+	r.Trigger()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(0), builds.Load())
+}
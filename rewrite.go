@@ -0,0 +1,252 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// rewriteAttrRegex matches class attributes with quoted values
+	rewriteAttrRegex = regexp.MustCompile(`(?i)(\sclass\s*=\s*)("[^"]*"|'[^']*')`)
+	// rawTextRegex matches script and style elements, whose content is not
+	// HTML
+	rawTextRegex = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>`)
+	// headEndRegex matches the end tag of the head element
+	headEndRegex = regexp.MustCompile(`(?i)</head\s*>`)
+)
+
+// RewriteOption configures RewriteHTML, NewRewriter and RewriteMiddleware.
+type RewriteOption func(*rewriteOptions)
+
+type rewriteOptions struct {
+	shortNames bool
+	injectCSS  bool
+	css        []MapOption
+}
+
+// WithShortNames replaces class attributes with the class name generated by
+// It instead of the merged classes.
+func WithShortNames() RewriteOption {
+	return func(o *rewriteOptions) {
+		o.shortNames = true
+	}
+}
+
+// WithInjectedCSS adds a <style> element holding the rules of the generated
+// class names used by the document to its <head>, or to its start if it has
+// none, like StyleTag does for the whole stylesheet.
+//
+// The browser reads the element as is, so the rules hold plain CSS
+// declarations, see WithStandaloneCSS, configured further by opts.
+func WithInjectedCSS(opts ...MapOption) RewriteOption {
+	return func(o *rewriteOptions) {
+		o.injectCSS = true
+		o.css = append([]MapOption{WithStandaloneCSS()}, opts...)
+	}
+}
+
+// RewriteHTML returns doc with the classes of every class attribute merged,
+// so that pages rendered without templ, by html/template or fmt, benefit from
+// twerge without changing their templates.
+//
+//	twerge.RewriteHTML([]byte(`<p class="p-2 p-4">`)) // <p class="p-4">
+//
+// Class attributes are found by their quoted values, script and style
+// elements are left unchanged.
+func RewriteHTML(doc []byte, opts ...RewriteOption) []byte {
+	var o rewriteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	raw := rawTextRegex.FindAllIndex(doc, -1)
+	inRawText := func(pos int) bool {
+		for _, r := range raw {
+			if pos >= r[0] && pos < r[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	used := make(map[string]bool)
+	var out bytes.Buffer
+	out.Grow(len(doc))
+	last := 0
+	for _, m := range rewriteAttrRegex.FindAllSubmatchIndex(doc, -1) {
+		if inRawText(m[0]) {
+			continue
+		}
+		value := doc[m[4]:m[5]]
+		quote := value[0]
+		classes := html.UnescapeString(string(value[1 : len(value)-1]))
+		if o.shortNames {
+			classes = It(classes)
+		} else {
+			classes = Merge(classes)
+		}
+		for _, class := range strings.Fields(classes) {
+			used[class] = true
+		}
+
+		out.Write(doc[last:m[4]])
+		out.WriteByte(quote)
+		out.WriteString(html.EscapeString(classes))
+		out.WriteByte(quote)
+		last = m[5]
+	}
+	out.Write(doc[last:])
+	if !o.injectCSS {
+		return out.Bytes()
+	}
+
+	var css strings.Builder
+	_ = writeGenClasses(&css, func(className string) bool {
+		return used[className]
+	}, o.css)
+	if css.Len() == 0 {
+		return out.Bytes()
+	}
+	style := "<style>" + escapeStyle(css.String()) + "</style>"
+
+	rewritten := out.Bytes()
+	at := 0
+	if loc := headEndRegex.FindIndex(rewritten); loc != nil {
+		at = loc[0]
+	}
+	return append(rewritten[:at:at], append([]byte(style), rewritten[at:]...)...)
+}
+
+// Rewriter is an io.WriteCloser buffering an HTML document and writing it
+// rewritten by RewriteHTML on Close.
+type Rewriter struct {
+	w    io.Writer
+	buf  bytes.Buffer
+	opts []RewriteOption
+}
+
+// NewRewriter returns a Rewriter writing to w.
+//
+//	rw := twerge.NewRewriter(w, twerge.WithShortNames(), twerge.WithInjectedCSS())
+//	err := tmpl.Execute(rw, data)
+//	...
+//	err = rw.Close()
+func NewRewriter(w io.Writer, opts ...RewriteOption) *Rewriter {
+	return &Rewriter{w: w, opts: opts}
+}
+
+// Write buffers p.
+func (r *Rewriter) Write(p []byte) (int, error) {
+	return r.buf.Write(p)
+}
+
+// Close writes the rewritten document to the underlying writer.
+func (r *Rewriter) Close() error {
+	_, err := r.w.Write(RewriteHTML(r.buf.Bytes(), r.opts...))
+	r.buf.Reset()
+	return err
+}
+
+// RewriteMiddleware rewrites the class attributes of the HTML responses of
+// next with RewriteHTML.
+//
+// Whether a response is rewritten is decided when next starts writing it,
+// from its Content-Type, sniffed from the first write if it is not set. HTML
+// responses are buffered until next returns, others, and responses that are
+// already compressed, are passed through unchanged and can be flushed.
+func RewriteMiddleware(next http.Handler, opts ...RewriteOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &rewriteRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if !rec.buffering {
+			if !rec.decided {
+				w.WriteHeader(rec.status)
+			}
+			return
+		}
+
+		body := RewriteHTML(rec.body.Bytes(), opts...)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// rewriteRecorder buffers the status and body of HTML responses, and passes
+// other responses through
+type rewriteRecorder struct {
+	http.ResponseWriter
+	status int
+	// headerWritten is set once the handler wrote the status
+	headerWritten bool
+	// decided is set once the response is known to be buffered or passed
+	// through
+	decided   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+// decide buffers the response if it is uncompressed HTML, and otherwise
+// writes the status to pass the response through. first is the first
+// written body, used to sniff the Content-Type if it is not set.
+func (r *rewriteRecorder) decide(first []byte) {
+	r.decided = true
+	header := r.Header()
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(first)
+	}
+	r.buffering = strings.HasPrefix(contentType, "text/html") && header.Get("Content-Encoding") == ""
+	if !r.buffering {
+		r.ResponseWriter.WriteHeader(r.status)
+	}
+}
+
+func (r *rewriteRecorder) WriteHeader(status int) {
+	if r.headerWritten {
+		return
+	}
+	r.headerWritten = true
+	r.status = status
+	if r.Header().Get("Content-Type") != "" {
+		r.decide(nil)
+	}
+}
+
+func (r *rewriteRecorder) Write(p []byte) (int, error) {
+	if !r.decided {
+		r.headerWritten = true
+		r.decide(p)
+	}
+	if r.buffering {
+		return r.body.Write(p)
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher for responses passed through, so streamed
+// responses like server-sent events are not held back.
+func (r *rewriteRecorder) Flush() {
+	if !r.decided && r.Header().Get("Content-Type") != "" {
+		r.headerWritten = true
+		r.decide(nil)
+	}
+	if !r.decided || r.buffering {
+		return
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (r *rewriteRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,109 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteHTML(t *testing.T) {
+	assert.Equal(t,
+		`<p class="p-4" data-class="p-2 p-4">a</p><b CLASS='m-1'>`,
+		string(RewriteHTML([]byte(`<p class="p-2 p-4" data-class="p-2 p-4">a</p><b CLASS='m-2 m-1'>`))),
+	)
+
+	// script and style elements are not HTML
+	script := `<script>el.innerHTML = '<p class="p-2 p-4">'</script>`
+	assert.Equal(t, script, string(RewriteHTML([]byte(script))))
+
+	// entities are decoded before merging and encoded again
+	assert.Equal(t,
+		`<p class="after:content-[&#39;x&#39;]">`,
+		string(RewriteHTML([]byte(`<p class="after:content-[&#39;y&#39;] after:content-[&#39;x&#39;]">`))),
+	)
+}
+
+func TestRewriteHTMLShortNames(t *testing.T) {
+	SetMapping(map[string]string{"px-2 px-4": "tw-pad", "hidden": "tw-unused"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	doc := RewriteHTML(
+		[]byte(`<html><head><title>t</title></head><body><p class="px-2 px-4"></p></body></html>`),
+		WithShortNames(),
+		WithInjectedCSS(),
+	)
+	assert.Equal(t,
+		"<html><head><title>t</title><style>.tw-pad { \n\tpadding-left: 1rem; \n\tpadding-right: 1rem; \n}\n</style></head>"+
+			`<body><p class="tw-pad"></p></body></html>`,
+		string(doc),
+	)
+
+	var buf bytes.Buffer
+	rw := NewRewriter(&buf, WithShortNames())
+	_, err := rw.Write([]byte(`<p class="px-2 `))
+	assert.NoError(t, err)
+	_, err = rw.Write([]byte(`px-4">`))
+	assert.NoError(t, err)
+	assert.NoError(t, rw.Close())
+	assert.Equal(t, `<p class="tw-pad">`, buf.String())
+}
+
+func TestRewriteMiddleware(t *testing.T) {
+	handler := RewriteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data.json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"html": "<p class=\"m-2 m-4\">"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Length", "31")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<p class="m-2 m-4">missing</p>`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, `<p class="m-4">missing</p>`, rec.Body.String())
+	assert.Equal(t, "26", rec.Header().Get("Content-Length"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/data.json", nil))
+	assert.Equal(t, `{"html": "<p class=\"m-2 m-4\">"}`, rec.Body.String())
+}
+
+func TestRewriteMiddlewareStreams(t *testing.T) {
+	var flushed []string
+	handler := RewriteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := w.(*rewriteRecorder)
+		if r.URL.Path == "/events" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("data: <p class=\"m-2 m-4\">\n\n"))
+			assert.NoError(t, http.NewResponseController(w).Flush())
+			flushed = append(flushed, rec.ResponseWriter.(*httptest.ResponseRecorder).Body.String())
+			return
+		}
+		// HTML is buffered, so flushing does not send the unrewritten page
+		_, _ = w.Write([]byte(`<!DOCTYPE html><p class="m-2 m-4">`))
+		assert.NoError(t, http.NewResponseController(w).Flush())
+		flushed = append(flushed, rec.ResponseWriter.(*httptest.ResponseRecorder).Body.String())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "data: <p class=\"m-2 m-4\">\n\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.False(t, rec.Flushed)
+	assert.Equal(t, `<!DOCTYPE html><p class="m-4">`, rec.Body.String())
+
+	assert.Equal(t, []string{"data: <p class=\"m-2 m-4\">\n\n", ""}, flushed)
+}
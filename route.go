@@ -0,0 +1,176 @@
+package twerge
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CommonBundle is the name of the bundle holding classes shared across routes.
+const CommonBundle = "common"
+
+// routeKey is the context key holding the current route
+type routeKey struct{}
+
+// routeClasses maps a route to the set of generated class names used by it
+// It is protected by mapMutex for concurrent access
+var routeClasses = make(map[string]map[string]bool)
+
+// WithRoute returns a copy of ctx that attributes classes generated with
+// ItContext to the given route.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext returns the route stored in ctx by WithRoute.
+func RouteFromContext(ctx context.Context) (string, bool) {
+	route, ok := ctx.Value(routeKey{}).(string)
+	return route, ok
+}
+
+// ItContext is like It but records the generated class name against the
+// route stored in ctx, if any, and in the Collector of ctx, if any. If ctx
+// carries a scope, see NewScope, the class name is generated by its Merger
+// instead.
+//
+// templ components can pass their implicit ctx:
+//
+//	<div class={ twerge.ItContext(ctx, "flex items-center") }></div>
+func ItContext(ctx context.Context, classes string) string {
+	var className string
+	if m, ok := ScopeFromContext(ctx); ok {
+		className = m.Generate(classes)
+	} else if route, ok := RouteFromContext(ctx); ok {
+		className = ItRoute(route, classes)
+	} else {
+		className = It(classes)
+	}
+	if c, ok := CollectorFromContext(ctx); ok {
+		c.Add(className)
+	}
+	return className
+}
+
+// ItRoute is like It but records the generated class name against route.
+func ItRoute(route, classes string) string {
+	className := It(classes)
+
+	mapMutex.Lock()
+	set, ok := routeClasses[route]
+	if !ok {
+		set = make(map[string]bool)
+		routeClasses[route] = set
+	}
+	set[className] = true
+	mapMutex.Unlock()
+
+	return className
+}
+
+// RouteBundles splits the generated classes into per-route bundles.
+//
+// The returned map is keyed by route, with CommonBundle holding every class
+// used by more than one route or not attributed to any route. Each bundle maps
+// generated class names to their merged classes. The classes of a route named
+// like CommonBundle are added to the common bundle.
+func RouteBundles() map[string]map[string]string {
+	bundles, _ := routeBundles()
+	return bundles
+}
+
+// routeBundles returns the bundles of RouteBundles and the sorted routes they
+// were split by.
+func routeBundles() (map[string]map[string]string, []string) {
+	mapMutex.RLock()
+	defer mapMutex.RUnlock()
+
+	uses := make(map[string]int, len(GenClassMergeStr))
+	for _, set := range routeClasses {
+		for className := range set {
+			uses[className]++
+		}
+	}
+
+	bundles := map[string]map[string]string{CommonBundle: {}}
+	for className, merged := range GenClassMergeStr {
+		if uses[className] != 1 {
+			bundles[CommonBundle][className] = merged
+		}
+	}
+	for route, set := range routeClasses {
+		bundle := bundles[route]
+		if bundle == nil {
+			bundle = make(map[string]string, len(set))
+			bundles[route] = bundle
+		}
+		for className := range set {
+			merged, ok := GenClassMergeStr[className]
+			if ok && uses[className] == 1 {
+				bundle[className] = merged
+			}
+		}
+	}
+	return bundles, slices.Sorted(maps.Keys(routeClasses))
+}
+
+// GenerateRouteBundles writes one CSS file of @apply rules per route into dir,
+// plus a common.css file for shared classes.
+//
+// Route file names are derived from the route, e.g. "/products/list" is
+// written to products-list.css and "/" to index.css. An error is returned
+// before writing any file if a route would be written to common.css, or two
+// routes to the same file, like "/a/b" and "/a-b".
+func GenerateRouteBundles(dir string, opts ...MapOption) error {
+	bundles, routes := routeBundles()
+	commonFile := routeFileName(CommonBundle)
+	files := map[string]string{commonFile: CommonBundle}
+	for _, route := range routes {
+		name := routeFileName(route)
+		if name == commonFile {
+			return fmt.Errorf("route %q would be written to %s, the bundle of shared classes", route, name)
+		}
+		if other, ok := files[name]; ok {
+			return fmt.Errorf("routes %q and %q would both be written to %s", other, route, name)
+		}
+		files[name] = route
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating bundle directory: %w", err)
+	}
+
+	o := newMapOptions(opts)
+	for name, route := range files {
+		var builder strings.Builder
+		_ = o.writeRules(&builder, bundles[route])
+
+		path := filepath.Join(dir, name)
+		err = os.WriteFile(path, []byte(builder.String()), 0644)
+		if err != nil {
+			return fmt.Errorf("error writing bundle %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// routeFileName converts a route into a CSS file name.
+func routeFileName(route string) string {
+	name := strings.Trim(route, "/")
+	if name == "" {
+		name = "index"
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+	return name + ".css"
+}
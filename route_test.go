@@ -0,0 +1,75 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteBundles(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	routeClasses = make(map[string]map[string]bool)
+	mapMutex.Unlock()
+
+	home := WithRoute(context.Background(), "/")
+	products := WithRoute(context.Background(), "/products/list")
+
+	shared := ItContext(home, "flex items-center")
+	assert.Equal(t, shared, ItContext(products, "flex items-center"))
+	homeOnly := ItContext(home, "text-4xl font-bold")
+	productsOnly := ItContext(products, "grid grid-cols-3")
+	unscoped := ItContext(context.Background(), "p-4")
+
+	bundles := RouteBundles()
+	assert.Contains(t, bundles[CommonBundle], shared)
+	assert.Contains(t, bundles[CommonBundle], unscoped)
+	assert.Equal(t, map[string]string{homeOnly: "text-4xl font-bold"}, bundles["/"])
+	assert.Equal(t, map[string]string{productsOnly: "grid grid-cols-3"}, bundles["/products/list"])
+
+	dir := t.TempDir()
+	assert.NoError(t, GenerateRouteBundles(dir))
+	for _, name := range []string{"common.css", "index.css", "products-list.css"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(t, err, name)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "index.css"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "."+homeOnly+" {")
+}
+
+func TestGenerateRouteBundlesCollisions(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	reset := func() {
+		mapMutex.Lock()
+		routeClasses = make(map[string]map[string]bool)
+		mapMutex.Unlock()
+	}
+	reset()
+
+	// a route named like the common bundle keeps the shared classes
+	shared := ItContext(WithRoute(context.Background(), "/"), "flex items-center")
+	assert.Equal(t, shared, ItContext(WithRoute(context.Background(), "/about"), "flex items-center"))
+	common := ItContext(WithRoute(context.Background(), CommonBundle), "text-4xl font-bold")
+	bundles := RouteBundles()
+	assert.Contains(t, bundles[CommonBundle], shared)
+	assert.Contains(t, bundles[CommonBundle], common)
+
+	dir := t.TempDir()
+	assert.ErrorContains(t, GenerateRouteBundles(dir), `route "common" would be written to common.css`)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	reset()
+	ItContext(WithRoute(context.Background(), "/a/b"), "p-4")
+	ItContext(WithRoute(context.Background(), "/a-b"), "m-4")
+	assert.ErrorContains(t, GenerateRouteBundles(dir), `routes "/a-b" and "/a/b" would both be written to a-b.css`)
+}
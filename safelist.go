@@ -0,0 +1,44 @@
+package twerge
+
+import "strings"
+
+// classPatterns matches classes against patterns like the Patterns of a
+// PluginGroup: a pattern ending in "*" matches a class prefix, any other
+// pattern the class itself
+type classPatterns struct {
+	classes  map[string]bool
+	prefixes []string
+}
+
+// newClassPatterns returns the classPatterns of patterns, or nil if there
+// are none.
+func newClassPatterns(patterns []string) *classPatterns {
+	if len(patterns) == 0 {
+		return nil
+	}
+	p := &classPatterns{classes: make(map[string]bool, len(patterns))}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			p.prefixes = append(p.prefixes, prefix)
+			continue
+		}
+		p.classes[pattern] = true
+	}
+	return p
+}
+
+// match reports whether class matches one of the patterns.
+func (p *classPatterns) match(class string) bool {
+	if p == nil {
+		return false
+	}
+	if p.classes[class] {
+		return true
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(class, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// SavingsStats is a snapshot of the payload savings measured by SavingsMiddleware.
+type SavingsStats struct {
+	// Responses is the number of responses measured
+	Responses int64 `json:"responses"`
+	// ClassesServed is the number of generated class names found in responses
+	ClassesServed int64 `json:"classes_served"`
+	// OriginalBytes is the size the merged classes of the class names
+	// would have taken
+	OriginalBytes int64 `json:"original_bytes"`
+	// ServedBytes is the size of the generated class names actually served
+	ServedBytes int64 `json:"served_bytes"`
+	// SavedBytes is OriginalBytes minus ServedBytes
+	SavedBytes int64 `json:"saved_bytes"`
+}
+
+// savings holds the aggregated counters of SavingsMiddleware
+var savings struct {
+	responses     atomic.Int64
+	classesServed atomic.Int64
+	originalBytes atomic.Int64
+	servedBytes   atomic.Int64
+}
+
+// SavingsMiddleware measures, per response, how many bytes were saved by
+// serving generated class names instead of the merged classes they stand for.
+//
+// Responses are streamed through unchanged while their class attributes are
+// counted. Results are aggregated across responses and are available through
+// GetSavingsStats and SavingsHandler.
+func SavingsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &savingsRecorder{ResponseWriter: w}
+		rec.scanner.onValue = recordSavings
+		next.ServeHTTP(rec, r)
+		savings.responses.Add(1)
+	})
+}
+
+// GetSavingsStats returns a snapshot of the savings aggregated so far.
+func GetSavingsStats() SavingsStats {
+	stats := SavingsStats{
+		Responses:     savings.responses.Load(),
+		ClassesServed: savings.classesServed.Load(),
+		OriginalBytes: savings.originalBytes.Load(),
+		ServedBytes:   savings.servedBytes.Load(),
+	}
+	stats.SavedBytes = stats.OriginalBytes - stats.ServedBytes
+	return stats
+}
+
+// ResetSavingsStats clears the savings aggregated so far.
+func ResetSavingsStats() {
+	savings.responses.Store(0)
+	savings.classesServed.Store(0)
+	savings.originalBytes.Store(0)
+	savings.servedBytes.Store(0)
+}
+
+// SavingsHandler returns an http.Handler serving the aggregated savings as JSON.
+func SavingsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GetSavingsStats())
+	})
+}
+
+// savingsRecorder passes writes through while counting the class names of
+// their class attributes
+type savingsRecorder struct {
+	http.ResponseWriter
+	scanner classAttrScanner
+}
+
+func (s *savingsRecorder) Write(p []byte) (int, error) {
+	s.scanner.scan(p)
+	return s.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher, so streamed responses like server-sent
+// events are not held back.
+func (s *savingsRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (s *savingsRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// maxClassAttrLen is the longest class attribute value classAttrScanner
+// reports, longer ones are skipped
+const maxClassAttrLen = 4096
+
+// classAttrScanner finds the values of the single or double quoted class
+// attributes of HTML written to it in chunks of any size.
+type classAttrScanner struct {
+	// onValue is called with the value of every class attribute
+	onValue func(value []byte)
+	// matched is the length of the prefix of `class=` matched so far
+	matched int
+	// prev is the last byte scanned
+	prev byte
+	// quote is the quote of the value being read, 0 outside of values
+	quote byte
+	value []byte
+	// skip drops a value longer than maxClassAttrLen
+	skip bool
+}
+
+func (c *classAttrScanner) scan(p []byte) {
+	const attr = "class="
+	for _, b := range p {
+		prev := c.prev
+		c.prev = b
+		switch {
+		case c.quote != 0:
+			if b == c.quote {
+				if !c.skip {
+					c.onValue(c.value)
+				}
+				c.quote, c.value, c.skip = 0, c.value[:0], false
+			} else if len(c.value) < maxClassAttrLen {
+				c.value = append(c.value, b)
+			} else {
+				c.skip = true
+			}
+		case c.matched == len(attr):
+			c.matched = 0
+			if b == '"' || b == '\'' {
+				c.quote = b
+			}
+		case c.matched > 0 && lower(b) == attr[c.matched]:
+			c.matched++
+		case lower(b) == 'c' && isHTMLSpace(prev):
+			c.matched = 1
+		default:
+			c.matched = 0
+		}
+	}
+}
+
+// lower returns the ASCII letter b in lower case.
+func lower(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// isHTMLSpace reports whether b separates HTML attributes.
+func isHTMLSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// recordSavings adds the generated class names of a class attribute value to
+// the aggregated counters.
+func recordSavings(value []byte) {
+	var served, mergedBytes, servedBytes int64
+	mapMutex.RLock()
+	for _, className := range strings.Fields(string(value)) {
+		merged, ok := GenClassMergeStr[className]
+		if !ok {
+			continue
+		}
+		served++
+		mergedBytes += int64(len(merged))
+		servedBytes += int64(len(className))
+	}
+	mapMutex.RUnlock()
+
+	savings.classesServed.Add(served)
+	savings.originalBytes.Add(mergedBytes)
+	savings.servedBytes.Add(servedBytes)
+}
@@ -0,0 +1,53 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavingsMiddleware(t *testing.T) {
+	SetMapping(map[string]string{"flex items-center justify-between": "tw-1"})
+	t.Cleanup(func() { SetMapping(nil) })
+	ResetSavingsStats()
+
+	handler := SavingsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// attributes split across writes are counted
+		_, _ = w.Write([]byte(`<div class="tw-1 custom" data-class="tw-1"><span CLA`))
+		_, _ = w.Write([]byte(`SS='tw-1'></span></div>`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, `<div class="tw-1 custom" data-class="tw-1"><span CLASS='tw-1'></span></div>`, rec.Body.String())
+
+	stats := GetSavingsStats()
+	assert.Equal(t, int64(1), stats.Responses)
+	assert.Equal(t, int64(2), stats.ClassesServed)
+	assert.Equal(t, int64(66), stats.OriginalBytes)
+	assert.Equal(t, int64(8), stats.ServedBytes)
+	assert.Equal(t, int64(58), stats.SavedBytes)
+
+	rec = httptest.NewRecorder()
+	SavingsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	var decoded SavingsStats
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.Equal(t, stats, decoded)
+}
+
+func TestSavingsMiddlewareFlush(t *testing.T) {
+	handler := SavingsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("data: a\n\n"))
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "data: a\n\n", rec.Body.String())
+}
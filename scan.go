@@ -0,0 +1,83 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/conneroisu/twerge/scan"
+)
+
+// packageRegex matches the package clause of a .templ or .go file
+var packageRegex = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+
+// ScanTemplFiles returns the class strings passed as literals to twerge
+// functions in the .templ files below dir, sorted and deduplicated.
+func ScanTemplFiles(dir string) ([]string, error) {
+	occurrences, err := scanTemplDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var literals []scan.Occurrence
+	for _, o := range occurrences {
+		if o.Func != "" {
+			literals = append(literals, o)
+		}
+	}
+	return scan.Classes(literals), nil
+}
+
+// ScanClasses returns the class strings found in the .templ, .go and .html
+// files below dir, sorted and deduplicated.
+//
+// Like ScanTemplFiles, it finds literals passed to twerge functions. In .templ
+// and .html files static class attributes are found too. Files generated by
+// templ and directories like node_modules and vendor are skipped. See the
+// scan package for the file and line of each class string.
+func ScanClasses(dir string) ([]string, error) {
+	occurrences, err := scan.Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return scan.Classes(occurrences), nil
+}
+
+// CountClassUsage counts how often each class string is used in the .templ
+// files below dir, both as a static class attribute and as a literal passed to
+// twerge functions.
+func CountClassUsage(dir string) (map[string]int, error) {
+	occurrences, err := scanTemplDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]int)
+	for _, o := range occurrences {
+		usage[o.Classes]++
+	}
+	return usage, nil
+}
+
+// scanTemplDir returns the class strings of the .templ files below dir
+func scanTemplDir(dir string) ([]scan.Occurrence, error) {
+	occurrences, err := scan.Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+	templ := occurrences[:0]
+	for _, o := range occurrences {
+		if filepath.Ext(o.File) == ".templ" {
+			templ = append(templ, o)
+		}
+	}
+	return templ, nil
+}
+
+// scanClassLiterals returns the class string literals passed to twerge functions
+func scanClassLiterals(content []byte) []string {
+	var classes []string
+	for _, o := range scan.Go("", content) {
+		classes = append(classes, o.Classes)
+	}
+	return classes
+}
@@ -0,0 +1,315 @@
+// Package scan finds the class strings of templ templates, Go and HTML files.
+//
+// Templates are parsed with the templ parser, so class attributes and
+// twerge calls are found wherever templ allows them, with the line they
+// appear on:
+//
+//	occurrences, err := scan.Dir("./views")
+//	for _, o := range occurrences {
+//		fmt.Printf("%s:%d: %q\n", o.File, o.Line, o.Classes)
+//	}
+package scan
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	parser "github.com/a-h/templ/parser/v2"
+)
+
+// Occurrence is a class string found in a file.
+type Occurrence struct {
+	// File is the path of the file
+	File string
+	// Line is the 1-based line of the class string
+	Line int
+	// Classes is the class string as written
+	Classes string
+	// Func is the twerge function the class string is passed to, or empty for
+	// a static class attribute
+	Func string
+}
+
+// stringLit matches a Go interpreted or raw string literal
+const stringLit = "(\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`)"
+
+var (
+	// singleCallRegex matches twerge calls taking a single class string literal
+	singleCallRegex = regexp.MustCompile(`twerge\.(It|ItCritical|RuntimeGenerate|Merge|Class|CSSComponent)\(\s*` + stringLit + `\s*\)`)
+	// ifCallRegex matches twerge.If calls with class string literals
+	ifCallRegex = regexp.MustCompile(`twerge\.(If)\([^,()]+,\s*` + stringLit + `\s*,\s*` + stringLit + `\s*\)`)
+	// contextCallRegex matches twerge calls taking a context and a single
+	// class string literal
+	contextCallRegex = regexp.MustCompile(`twerge\.(ItContext|RuntimeGenerateContext)\([^,()]+,\s*` + stringLit + `\s*\)`)
+	// classAttrRegex matches static class attributes of HTML
+	classAttrRegex = regexp.MustCompile(`class="([^"]*)"`)
+)
+
+// Option configures Dir.
+type Option func(*options)
+
+type options struct {
+	generated bool
+}
+
+// WithGenerated also scans the *_templ.go files generated by templ, which
+// Dir skips by default as they repeat the classes of their templates.
+func WithGenerated() Option {
+	return func(o *options) {
+		o.generated = true
+	}
+}
+
+// Dir returns the class strings of the .templ, .go and .html files below
+// dir, ordered by file and line.
+//
+// Directories like node_modules, vendor and hidden directories are skipped.
+func Dir(dir string, opts ...Option) ([]Occurrence, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var occurrences []Occurrence
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "node_modules" || name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !Scanned(path) && !(o.generated && strings.HasSuffix(path, "_templ.go")) {
+			return nil
+		}
+		found, err := File(path)
+		if err != nil {
+			return err
+		}
+		occurrences = append(occurrences, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %w", dir, err)
+	}
+	return occurrences, nil
+}
+
+// Scanned reports whether Dir scans the file at path by default.
+func Scanned(path string) bool {
+	switch filepath.Ext(path) {
+	case ".templ", ".html":
+		return true
+	case ".go":
+		return !strings.HasSuffix(path, "_templ.go")
+	}
+	return false
+}
+
+// File returns the class strings of the file at path, a .templ, .go or
+// .html file.
+func File(path string) ([]Occurrence, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(path) {
+	case ".templ":
+		return Templ(path, content)
+	case ".go":
+		return Go(path, content), nil
+	case ".html":
+		return HTML(path, content), nil
+	}
+	return nil, fmt.Errorf("unsupported file %s", path)
+}
+
+// Templ returns the class strings of the templ template content of the file
+// name: static class attributes and literals passed to twerge functions.
+func Templ(name string, content []byte) ([]Occurrence, error) {
+	tf, err := parser.ParseString(string(content))
+	offset := 0
+	if err == nil && tf.Package.Expression.Value == "" {
+		// without a package clause the templ parser reads the whole file as
+		// Go code, so one is added and the lines shifted back
+		tf, err = parser.ParseString("package main\n" + string(content))
+		offset = 1
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", name, err)
+	}
+
+	s := &templScanner{file: name, offset: offset}
+	for _, expr := range tf.Header {
+		s.expression(expr.Expression)
+	}
+	for _, node := range tf.Nodes {
+		switch n := node.(type) {
+		case parser.TemplateFileGoExpression:
+			s.expression(n.Expression)
+		case parser.HTMLTemplate:
+			s.nodes(n.Children)
+		}
+	}
+	slices.SortStableFunc(s.occurrences, func(a, b Occurrence) int {
+		return a.Line - b.Line
+	})
+	return s.occurrences, nil
+}
+
+// Go returns the literals passed to twerge functions in the Go source
+// content of the file name.
+func Go(name string, content []byte) []Occurrence {
+	return calls(name, string(content), 1)
+}
+
+// HTML returns the static class attributes and the literals passed to
+// twerge functions in the HTML content of the file name.
+func HTML(name string, content []byte) []Occurrence {
+	occurrences := calls(name, string(content), 1)
+	for _, m := range classAttrRegex.FindAllSubmatchIndex(content, -1) {
+		classes := strings.TrimSpace(string(content[m[2]:m[3]]))
+		if classes == "" {
+			continue
+		}
+		occurrences = append(occurrences, Occurrence{
+			File:    name,
+			Line:    1 + bytes.Count(content[:m[2]], []byte("\n")),
+			Classes: classes,
+		})
+	}
+	slices.SortStableFunc(occurrences, func(a, b Occurrence) int {
+		return a.Line - b.Line
+	})
+	return occurrences
+}
+
+// Classes returns the class strings of occurrences, sorted and deduplicated.
+func Classes(occurrences []Occurrence) []string {
+	classes := make([]string, 0, len(occurrences))
+	for _, o := range occurrences {
+		classes = append(classes, o.Classes)
+	}
+	slices.Sort(classes)
+	return slices.Compact(classes)
+}
+
+// templScanner collects the occurrences of the nodes of a template
+type templScanner struct {
+	file        string
+	offset      int
+	occurrences []Occurrence
+}
+
+func (s *templScanner) nodes(nodes []parser.Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case parser.Element:
+			s.attributes(n.Attributes)
+			s.nodes(n.Children)
+		case parser.RawElement:
+			s.attributes(n.Attributes)
+		case parser.ScriptElement:
+			s.attributes(n.Attributes)
+		case parser.IfExpression:
+			s.expression(n.Expression)
+			s.nodes(n.Then)
+			for _, elseIf := range n.ElseIfs {
+				s.expression(elseIf.Expression)
+				s.nodes(elseIf.Then)
+			}
+			s.nodes(n.Else)
+		case parser.SwitchExpression:
+			s.expression(n.Expression)
+			for _, c := range n.Cases {
+				s.nodes(c.Children)
+			}
+		case parser.ForExpression:
+			s.expression(n.Expression)
+			s.nodes(n.Children)
+		case parser.TemplElementExpression:
+			s.expression(n.Expression)
+			s.nodes(n.Children)
+		case parser.CallTemplateExpression:
+			s.expression(n.Expression)
+		case parser.StringExpression:
+			s.expression(n.Expression)
+		case parser.GoCode:
+			s.expression(n.Expression)
+		}
+	}
+}
+
+func (s *templScanner) attributes(attributes []parser.Attribute) {
+	for _, attribute := range attributes {
+		switch a := attribute.(type) {
+		case parser.ConstantAttribute:
+			classes := strings.TrimSpace(a.Value)
+			if a.Name == "class" && classes != "" {
+				s.occurrences = append(s.occurrences, Occurrence{
+					File:    s.file,
+					Line:    int(a.NameRange.From.Line) + 1 - s.offset,
+					Classes: classes,
+				})
+			}
+		case parser.ExpressionAttribute:
+			s.expression(a.Expression)
+		case parser.BoolExpressionAttribute:
+			s.expression(a.Expression)
+		case parser.SpreadAttributes:
+			s.expression(a.Expression)
+		case parser.ConditionalAttribute:
+			s.expression(a.Expression)
+			s.attributes(a.Then)
+			s.attributes(a.Else)
+		}
+	}
+}
+
+func (s *templScanner) expression(expr parser.Expression) {
+	line := int(expr.Range.From.Line) + 1 - s.offset
+	s.occurrences = append(s.occurrences, calls(s.file, expr.Value, line)...)
+}
+
+// calls returns the literals passed to twerge functions in the Go code src
+// of the file name, starting at line.
+func calls(name, src string, line int) []Occurrence {
+	var occurrences []Occurrence
+	add := func(m []int, fn string, literals ...int) {
+		for _, start := range literals {
+			classes, err := strconv.Unquote(src[m[start]:m[start+1]])
+			if err != nil || classes == "" {
+				continue
+			}
+			occurrences = append(occurrences, Occurrence{
+				File:    name,
+				Line:    line + strings.Count(src[:m[start]], "\n"),
+				Classes: classes,
+				Func:    fn,
+			})
+		}
+	}
+	for _, m := range singleCallRegex.FindAllStringSubmatchIndex(src, -1) {
+		add(m, src[m[2]:m[3]], 4)
+	}
+	for _, m := range ifCallRegex.FindAllStringSubmatchIndex(src, -1) {
+		add(m, src[m[2]:m[3]], 4, 6)
+	}
+	for _, m := range contextCallRegex.FindAllStringSubmatchIndex(src, -1) {
+		add(m, src[m[2]:m[3]], 4)
+	}
+	slices.SortStableFunc(occurrences, func(a, b Occurrence) int {
+		return a.Line - b.Line
+	})
+	return occurrences
+}
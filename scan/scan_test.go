@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTempl(t *testing.T) {
+	content := "package views\n\n" +
+		"templ Card(active bool) {\n" +
+		"\t<div class=\"flex p-4\">\n" +
+		"\t\t<p class={ twerge.If(active,\n\t\t\t\"text-blue-500\",\n\t\t\t`text-gray-500`) }></p>\n" +
+		"\t\tif active {\n" +
+		"\t\t\t@twerge.CSSComponent(\"px-4 py-2\")\n" +
+		"\t\t\t<span class={ twerge.It(dynamic) }>{ twerge.Merge(\"p-2 p-4\") }</span>\n" +
+		"\t\t}\n" +
+		"\t\t<!-- <b class=\"commented\"></b> -->\n" +
+		"\t\t<i class={ twerge.ItContext(ctx, \"italic\") }></i>\n" +
+		"\t</div>\n}\n"
+
+	occurrences, err := Templ("card.templ", []byte(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []Occurrence{
+		{File: "card.templ", Line: 4, Classes: "flex p-4"},
+		{File: "card.templ", Line: 6, Classes: "text-blue-500", Func: "If"},
+		{File: "card.templ", Line: 7, Classes: "text-gray-500", Func: "If"},
+		{File: "card.templ", Line: 9, Classes: "px-4 py-2", Func: "CSSComponent"},
+		{File: "card.templ", Line: 10, Classes: "p-2 p-4", Func: "Merge"},
+		{File: "card.templ", Line: 13, Classes: "italic", Func: "ItContext"},
+	}, occurrences)
+
+	// files without a package clause keep their lines
+	occurrences, err = Templ("a.templ", []byte("templ A() {\n\t<div class=\"grid\"></div>\n}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Occurrence{{File: "a.templ", Line: 2, Classes: "grid"}}, occurrences)
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"views/page.templ":    "package views\n\ntempl Page() {\n\t<div class=\"flex\"></div>\n}\n",
+		"views/page_templ.go": "package views\n\nvar c = twerge.It(\"generated\")\n",
+		"handlers/home.go":    "package handlers\n\nvar c = twerge.Merge(\"p-2 p-4\")\n",
+		"static/index.html":   "<main class=\"grid\"></main>\n",
+		"vendor/x/a.html":     "<div class=\"vendored\"></div>\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	occurrences, err := Dir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flex", "grid", "p-2 p-4"}, Classes(occurrences))
+
+	occurrences, err = Dir(dir, WithGenerated())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flex", "generated", "grid", "p-2 p-4"}, Classes(occurrences))
+	assert.Contains(t, occurrences, Occurrence{File: filepath.Join(dir, "handlers/home.go"), Line: 3, Classes: "p-2 p-4", Func: "Merge"})
+}
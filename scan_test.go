@@ -0,0 +1,49 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanClasses(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"views/page.templ":        "templ Page() {\n\t<div class=\"flex p-4\"></div>\n\t<p class={ twerge.It(\"m-1 m-2\") }></p>\n}\n",
+		"views/page_templ.go":     "templ_7745c5c3_Buffer.WriteString(\"<div class=\\\"flex p-4 generated\\\">\")\n",
+		"handlers/home.go":        "package handlers\n\nvar c = twerge.Merge(\"p-2 p-4\")\nvar html = `<b class=\"go-static\"></b>`\n",
+		"static/index.html":       "<main class=\" grid gap-2 \"></main>\n",
+		"node_modules/x/a.html":   "<div class=\"vendored\"></div>\n",
+		".cache/b.templ":          "<div class=\"hidden-dir\"></div>\n",
+		"views/button.templ":      "templ Button() {\n\t@twerge.CSSComponent(\"px-4 py-2\")\n}\n",
+		"views/unrelated.css":     ".a { color: red; }\n",
+		"views/partials/empty.go": "package partials\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	classes, err := ScanClasses(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flex p-4", "grid gap-2", "m-1 m-2", "p-2 p-4", "px-4 py-2"}, classes)
+}
+
+func TestCountClassUsage(t *testing.T) {
+	dir := t.TempDir()
+	content := "templ A() {\n" +
+		"\t<div class=\"flex p-4\"></div>\n" +
+		"\t<p class={ twerge.It(\"flex p-4\") }></p>\n" +
+		"\t<p class={ twerge.If(ok, \"m-2\", \"m-4\") }></p>\n" +
+		"}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.templ"), []byte(content), 0644))
+
+	usage, err := CountClassUsage(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"flex p-4": 2, "m-2": 1, "m-4": 1}, usage)
+}
@@ -0,0 +1,48 @@
+package twerge
+
+import "context"
+
+// scopeKey is the context key holding the Merger of a scope
+type scopeKey struct{}
+
+// NewScope returns a copy of ctx carrying a new Merger, and the Merger.
+//
+// ItContext and RuntimeGenerateContext generate the class names of ctx with
+// the Merger instead of the package-level class map, so a multi-tenant
+// server can build the stylesheet of a tenant, or a request, without mixing
+// in the classes of others:
+//
+//	ctx, scope := twerge.NewScope(r.Context())
+//	err := page().Render(ctx, &body)
+//	css := scope.CSS()
+//
+// The Merger merges with the settings of Merge, see SetConfig, and starts
+// with an empty class map. Class names of a scope are not recorded against
+// the route of ctx, see WithRoute, as routes are shared by all scopes. A
+// Collector of ctx records them, but writes the rules of the package-level
+// class map, so the rules of a scope are written by its Merger.
+func NewScope(ctx context.Context) (context.Context, *Merger) {
+	mergeSettingsMutex.Lock()
+	m := &Merger{
+		merge:     *quietMerge.Load(),
+		classMap:  make(map[string]string),
+		generated: make(map[string]string),
+		naming:    settings.naming(),
+	}
+	mergeSettingsMutex.Unlock()
+	return WithScope(ctx, m), m
+}
+
+// WithScope returns a copy of ctx whose class names are generated with m,
+// like those of NewScope, e.g. to keep the Merger of a tenant, with the
+// Config of the tenant, across its requests.
+func WithScope(ctx context.Context, m *Merger) context.Context {
+	return context.WithValue(ctx, scopeKey{}, m)
+}
+
+// ScopeFromContext returns the Merger stored in ctx by NewScope or
+// WithScope.
+func ScopeFromContext(ctx context.Context) (*Merger, bool) {
+	m, ok := ctx.Value(scopeKey{}).(*Merger)
+	return m, ok
+}
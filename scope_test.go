@@ -0,0 +1,38 @@
+package twerge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScope(t *testing.T) {
+	ctxA, tenantA := NewScope(context.Background())
+	ctxB, tenantB := NewScope(context.Background())
+
+	assert.Equal(t, "tw-0", ItContext(ctxA, "scope-a p-2 p-4"))
+	assert.Equal(t, "tw-0", RuntimeGenerateContext(ctxB, "scope-b m-1"))
+	assert.Equal(t, "tw-1", ItContext(ctxA, "scope-a-2 flex"))
+
+	assert.Equal(t, map[string]string{"scope-a p-2 p-4": "tw-0", "scope-a-2 flex": "tw-1"}, tenantA.ClassMap())
+	assert.Equal(t, map[string]string{"scope-b m-1": "tw-0"}, tenantB.ClassMap())
+	assert.Contains(t, tenantA.CSS(), "@apply scope-a-2 flex")
+	assert.NotContains(t, tenantB.CSS(), "scope-a")
+
+	// the package-level class map is left alone
+	classMap := TakeSnapshot().ClassMap
+	assert.NotContains(t, classMap, "scope-a p-2 p-4")
+	assert.NotContains(t, classMap, "scope-b m-1")
+
+	m, ok := ScopeFromContext(ctxA)
+	assert.True(t, ok)
+	assert.Same(t, tenantA, m)
+	_, ok = ScopeFromContext(context.Background())
+	assert.False(t, ok)
+
+	// a Merger of its own config can be bound to requests
+	tenantC := New(&Config{Conflicts: DefaultConflictConfig(), ClassPrefix: "c-"})
+	assert.Equal(t, "c-0", ItContext(WithScope(context.Background(), tenantC), "p-2 p-4"))
+	assert.Equal(t, map[string]string{"c-0": "p-4"}, tenantC.Rules())
+}
@@ -0,0 +1,185 @@
+package twerge
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Config selects the class groups and conflicts a merger knows about.
+//
+// Start from DefaultConfig, the zero value disables all cross-group conflicts.
+type Config struct {
+	// TailwindVersion selects the class groups of the Tailwind version, so
+	// that utilities added in v4, like text-shadow-*, are merged instead of
+	// being passed through or mistaken for other groups.
+	TailwindVersion TailwindVersion
+	// Conflicts toggles conflicts between class groups of different CSS
+	// properties.
+	Conflicts ConflictConfig
+	// PluginGroups group unknown classes of Tailwind plugins.
+	PluginGroups []PluginGroup
+	// ClassGroups maps the IDs of custom class groups to their classes, see
+	// Extend.
+	ClassGroups map[string][]string
+	// TailwindPrefix is the prefix option of the Tailwind configuration, like
+	// "tw-". Only classes with the prefix are merged, others are passed
+	// through unchanged.
+	TailwindPrefix string
+	// ClassPrefix is the prefix of generated class names, DefaultClassPrefix
+	// if empty. Applications sharing a page need distinct prefixes.
+	ClassPrefix string
+	// Naming selects how class names are generated.
+	Naming NamingStrategy
+	// HashLength is the number of hash characters of class names generated
+	// with a hashing Naming, DefaultHashLength if zero. Names are lengthened
+	// when they collide.
+	HashLength int
+	// Namer, if not nil, generates the class names instead of Naming.
+	Namer Namer
+	// Dedupe gives class strings merging to the same classes the class name
+	// already generated for them, so their rule is written once. Lint
+	// reports the class strings sharing a name.
+	Dedupe bool
+	// CanonicalKeys makes RuntimeGenerate look up and record class strings
+	// by their Canonical form, so "p-4 flex" and "flex p-4" share a class
+	// name and a single entry of the class map. It is ignored in production
+	// mode, where class names are looked up by the registered class strings.
+	CanonicalKeys bool
+	// Cache stores the merge results, a new in-memory LRU cache if nil. A
+	// Cache must not be shared by mergers of different configs.
+	Cache Cache
+	// CacheSize is the number of merge results the in-memory LRU cache
+	// holds, 1000 if zero. It is ignored if Cache is set.
+	CacheSize int
+	// CacheMetrics, if not nil, is notified of the cache hits, misses and
+	// evictions of the merger.
+	CacheMetrics CacheMetrics
+	// DarkMode selects how WithStandaloneCSS writes the dark: variant.
+	DarkMode DarkMode
+	// DarkSelector is the selector of DarkModeClass, DefaultDarkSelector if
+	// empty.
+	DarkSelector string
+	// Variants maps the names of custom variants to how WithStandaloneCSS
+	// writes them: a selector template with "&" standing for the element,
+	// like "&:is(:hover, :focus)" or ".theme-blue &", or a media query
+	// starting with "@media ". They take precedence over the built-in
+	// variants.
+	Variants map[string]string
+	// Screens maps the names of the breakpoints, like "md", to the min-width
+	// WithStandaloneCSS writes their @media blocks with, like "768px", or to
+	// a media query in parentheses, like "(orientation: portrait)". Rules of
+	// wider breakpoints are written later. DefaultScreens are used if nil.
+	Screens map[string]string
+	// Safelist holds classes always kept as written, never removed by
+	// conflicting classes, e.g. classes toggled by JavaScript at runtime. A
+	// pattern ending in "*" matches a class prefix, so "js-*" matches
+	// "js-open". Patterns match the whole class, including its variants.
+	Safelist []string
+	// Blocklist holds classes removed from merged class lists, matched like
+	// Safelist.
+	Blocklist []string
+}
+
+// DefaultConfig returns the Config Merge uses by default.
+func DefaultConfig() *Config {
+	return &Config{
+		TailwindVersion: TailwindV3,
+		Conflicts:       DefaultConflictConfig(),
+	}
+}
+
+var (
+	// mergeSettingsMutex protects the settings Merge is built from
+	mergeSettingsMutex sync.Mutex
+	// settings is the Config of Merge
+	settings = *DefaultConfig()
+	// mergeConfig is the config Merge is built from
+	mergeConfig = defaultConfig
+)
+
+// NewMerge returns a merger using the given Config, independent of the
+// settings of Merge.
+//
+//	mergeV4 := twerge.NewMerge(&twerge.Config{
+//		TailwindVersion: twerge.TailwindV4,
+//		Conflicts:       twerge.DefaultConflictConfig(),
+//	})
+//	mergeV4("text-shadow-sm text-shadow-lg") // "text-shadow-lg"
+//
+// Like Merge, it records the merged class lists in ClassMapStr.
+func NewMerge(c *Config) func(classes string) string {
+	return createTwMerge(c.build(), c.Cache, recordMerged)
+}
+
+// SetConfig replaces Merge with a merger using the given Config.
+//
+// Like SetConflictConfig, it resets the merge cache, unless c.Cache is set,
+// and is not safe to call concurrently with Merge, so it should be called
+// once at program start.
+func SetConfig(c *Config) {
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	settings = *c
+	settings.PluginGroups = slices.Clone(c.PluginGroups)
+	settings.ClassGroups = cloneClassGroups(c.ClassGroups)
+	if settings.TailwindVersion == TailwindUnknown {
+		settings.TailwindVersion = TailwindV3
+	}
+	rebuildMerge()
+	canonicalKeys.Store(settings.CanonicalKeys)
+
+	mapMutex.Lock()
+	classNaming = settings.naming()
+	mapMutex.Unlock()
+}
+
+// build returns the config a merger using c is built from.
+func (c *Config) build() *config {
+	conf := c.Conflicts.apply(datasetFor(c.TailwindVersion))
+	if len(c.ClassGroups) > 0 {
+		conf = extendConfig(conf, classGroupParts(c.ClassGroups))
+	}
+	conf.PluginGroups = slices.Clone(c.PluginGroups)
+	conf.Prefix = c.TailwindPrefix
+	if c.CacheSize > 0 {
+		conf.MaxCacheSize = c.CacheSize
+	}
+	conf.CacheMetrics = c.CacheMetrics
+	conf.DarkMode = c.DarkMode
+	conf.DarkSelector = c.DarkSelector
+	conf.Variants = maps.Clone(c.Variants)
+	if c.Screens != nil {
+		conf.MediaVariants = newMediaVariants(c.Screens)
+	}
+	conf.Safelist = newClassPatterns(c.Safelist)
+	conf.Blocklist = newClassPatterns(c.Blocklist)
+	return conf
+}
+
+// rebuildMerge replaces Merge with a merger using the current settings.
+//
+// mergeSettingsMutex must be held.
+func rebuildMerge() {
+	mergeConfig = settings.build()
+	Merge = createTwMerge(withStats(mergeConfig), settings.Cache, recordMerged)
+	merge := createTwMerge(mergeConfig, nil, nil)
+	quietMerge.Store(&merge)
+}
+
+// datasetFor returns the class groups of the Tailwind version.
+//
+// Unknown versions use the v3 class groups.
+func datasetFor(v TailwindVersion) *config {
+	if v == TailwindV4 {
+		return v4Config
+	}
+	return defaultConfig
+}
+
+// currentConfig returns the config Merge is built from.
+func currentConfig() *config {
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	return mergeConfig
+}
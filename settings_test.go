@@ -0,0 +1,98 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMergeV4(t *testing.T) {
+	merge := NewMerge(&Config{
+		TailwindVersion: TailwindV4,
+		Conflicts:       DefaultConflictConfig(),
+	})
+
+	for in, want := range map[string]string{
+		"text-shadow-sm text-shadow-lg":                   "text-shadow-lg",
+		"text-shadow-lg text-shadow-none":                 "text-shadow-none",
+		"text-shadow-red-500 text-shadow-blue-500":        "text-shadow-blue-500",
+		"inset-shadow-xs inset-shadow-[0_1px_2px_black]":  "inset-shadow-[0_1px_2px_black]",
+		"inset-shadow-red-500 inset-shadow-blue-500":      "inset-shadow-blue-500",
+		"inset-ring inset-ring-2":                         "inset-ring-2",
+		"field-sizing-fixed field-sizing-content":         "field-sizing-content",
+		"@container @container-normal":                    "@container-normal",
+		"bg-linear-to-r bg-radial":                        "bg-radial",
+		"bg-gradient-to-r bg-conic-45":                    "bg-conic-45",
+		"@md:flex @md:grid":                               "@md:grid",
+		"@min-md:p-2 @min-md:p-4":                         "@min-md:p-4",
+		"@max-lg:hidden @max-lg:block":                    "@max-lg:block",
+		"@[17.5rem]:underline @[17.5rem]:no-underline":    "@[17.5rem]:no-underline",
+		"inset-0 inset-x-2 inset-shadow-sm inset-ring-sm": "inset-0 inset-x-2 inset-shadow-sm inset-ring-sm",
+	} {
+		assert.Equal(t, want, merge(in), in)
+	}
+
+	// text shadows no longer remove text colors and font sizes
+	assert.Equal(t, "text-red-500 text-lg text-shadow-lg", merge("text-red-500 text-lg text-shadow-lg"))
+	// container query variants do not conflict with other variants
+	assert.Equal(t, "md:p-2 @md:p-4", merge("md:p-2 @md:p-4"))
+}
+
+func TestSetConfig(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	// v3 mistakes text shadows for text colors
+	assert.Equal(t, "text-shadow-lg", Merge("text-red-500 text-shadow-lg"))
+
+	SetConfig(&Config{TailwindVersion: TailwindV4, Conflicts: DefaultConflictConfig()})
+	assert.Equal(t, TailwindV4, CurrentTailwindVersion())
+	assert.Equal(t, "text-red-500 text-shadow-lg", Merge("text-red-500 text-shadow-lg"))
+
+	// the v3 class groups are left untouched
+	_, ok := defaultConfig.ClassGroups.NextPart["text-shadow"]
+	assert.False(t, ok)
+	_, ok = defaultConfig.ClassGroups.NextPart["text"].NextPart["shadow"]
+	assert.False(t, ok)
+
+	SetConfig(&Config{})
+	assert.Equal(t, TailwindV3, CurrentTailwindVersion())
+}
+
+func TestTailwindPrefix(t *testing.T) {
+	m := New(&Config{Conflicts: DefaultConflictConfig(), TailwindPrefix: "tw-"})
+
+	for in, want := range map[string]string{
+		"tw-bg-red-500 tw-bg-blue-500":          "tw-bg-blue-500",
+		"hover:tw-p-2 hover:tw-p-4":             "hover:tw-p-4",
+		"!tw-font-medium !tw-font-bold":         "!tw-font-bold",
+		"-tw-mt-4 tw-mt-2":                      "tw-mt-2",
+		"tw-[color:red] tw-[color:blue]":        "tw-[color:blue]",
+		"tw-text-lg/7 tw-leading-none":          "tw-text-lg/7 tw-leading-none",
+		"bg-red-500 bg-blue-500 tw-bg-blue-500": "bg-red-500 bg-blue-500 tw-bg-blue-500",
+		"tw- tw-p-2":                            "tw- tw-p-2",
+	} {
+		assert.Equal(t, want, m.Merge(in), in)
+	}
+}
+
+func TestSafelistBlocklist(t *testing.T) {
+	m := New(&Config{
+		Conflicts: DefaultConflictConfig(),
+		Safelist:  []string{"hidden", "js-*"},
+		Blocklist: []string{"debug-outline", "container"},
+	})
+
+	for in, want := range map[string]string{
+		// safelisted classes are never removed, nor remove others
+		"hidden block":         "hidden block",
+		"block flex hidden":    "flex hidden",
+		"p-2 js-open p-4":      "js-open p-4",
+		"md:hidden md:block":   "md:block",
+		"container p-2":        "p-2",
+		"debug-outline p-2":    "p-2",
+		"md:container flex":    "md:container flex",
+		"container hidden p-4": "hidden p-4",
+	} {
+		assert.Equal(t, want, m.Merge(in), in)
+	}
+}
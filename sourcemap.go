@@ -0,0 +1,179 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/conneroisu/twerge/scan"
+)
+
+// WriteGeneratedCSSWithSourceMap writes the @apply rules of every registered
+// class to css, like WriteGeneratedCSS, and a version 3 source map of them to
+// sourceMap, so that browser DevTools show the template each rule comes from.
+//
+// Every line of a rule maps to the first of the occurrences, as returned by
+// scan.Dir, whose class string has the class name of the rule. file is the
+// name of the CSS file; the map is expected next to it as file + ".map",
+// which the comment ending the stylesheet points to.
+//
+//	occurrences, err := scan.Dir("./views")
+//	...
+//	err = twerge.WriteGeneratedCSSWithSourceMap(cssFile, mapFile, "styles.css", occurrences)
+func WriteGeneratedCSSWithSourceMap(
+	css, sourceMap io.Writer,
+	file string,
+	occurrences []scan.Occurrence,
+	opts ...MapOption,
+) error {
+	o := newMapOptions(opts)
+	snap := takeSnapshot()
+
+	origins := make(map[string]scan.Occurrence, len(occurrences))
+	for _, occurrence := range occurrences {
+		className, ok := snap.ClassMap[occurrence.Classes]
+		if _, seen := origins[className]; ok && !seen {
+			origins[className] = occurrence
+		}
+	}
+
+	lines := &lineCounter{w: css}
+	var mappings sourceMapBuilder
+	err := o.inLayer(lines, func(w io.Writer) error {
+		for _, className := range o.order(snap.Rules) {
+			start := lines.lines
+			err := o.writeRule(lines, className, snap.Rules[className], snap.RawCSS[className])
+			if err != nil {
+				return err
+			}
+			if origin, ok := origins[className]; ok {
+				mappings.add(start, lines.lines, origin.File, origin.Line-1)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(file)
+	_, err = io.WriteString(lines, "/*# sourceMappingURL="+name+".map */\n")
+	if err != nil {
+		return fmt.Errorf("error writing css: %w", err)
+	}
+
+	content, err := json.Marshal(mappings.sourceMap(name))
+	if err != nil {
+		return fmt.Errorf("error encoding source map: %w", err)
+	}
+	_, err = sourceMap.Write(content)
+	if err != nil {
+		return fmt.Errorf("error writing source map: %w", err)
+	}
+	return nil
+}
+
+// lineCounter counts the lines written to w
+type lineCounter struct {
+	w     io.Writer
+	lines int
+}
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.lines += bytes.Count(p[:n], []byte("\n"))
+	return n, err
+}
+
+// sourceMap is the JSON of a version 3 source map
+type sourceMap struct {
+	Version  int      `json:"version"`
+	File     string   `json:"file"`
+	Sources  []string `json:"sources"`
+	Names    []string `json:"names"`
+	Mappings string   `json:"mappings"`
+}
+
+// sourceMapBuilder collects the source lines of generated lines
+type sourceMapBuilder struct {
+	sources []string
+	indexes map[string]int
+	// lines maps generated lines to their source index and line, or -1 for
+	// lines without a source
+	lines [][2]int
+}
+
+// add maps the generated lines from start up to end to line of source.
+func (b *sourceMapBuilder) add(start, end int, source string, line int) {
+	if b.indexes == nil {
+		b.indexes = make(map[string]int)
+	}
+	index, ok := b.indexes[source]
+	if !ok {
+		index = len(b.sources)
+		b.indexes[source] = index
+		b.sources = append(b.sources, filepath.ToSlash(source))
+	}
+	for len(b.lines) < end {
+		b.lines = append(b.lines, [2]int{-1, 0})
+	}
+	for i := start; i < end; i++ {
+		b.lines[i] = [2]int{index, line}
+	}
+}
+
+// sourceMap returns the source map of the generated file.
+func (b *sourceMapBuilder) sourceMap(file string) sourceMap {
+	var mappings strings.Builder
+	prevIndex, prevLine := 0, 0
+	for i, mapped := range b.lines {
+		if i > 0 {
+			mappings.WriteByte(';')
+		}
+		if mapped[0] < 0 {
+			continue
+		}
+		// generated column, source index, source line and source column,
+		// all but the generated column relative to the previous segment
+		writeVLQ(&mappings, 0)
+		writeVLQ(&mappings, mapped[0]-prevIndex)
+		writeVLQ(&mappings, mapped[1]-prevLine)
+		writeVLQ(&mappings, 0)
+		prevIndex, prevLine = mapped[0], mapped[1]
+	}
+	return sourceMap{
+		Version:  3,
+		File:     file,
+		Sources:  append([]string{}, b.sources...),
+		Names:    []string{},
+		Mappings: mappings.String(),
+	}
+}
+
+// base64Digits are the digits of base 64 VLQ values
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// writeVLQ writes value as a base 64 VLQ, the encoding of source map
+// mappings.
+func writeVLQ(b *strings.Builder, value int) {
+	vlq := value << 1
+	if value < 0 {
+		vlq = -value<<1 | 1
+	}
+	for {
+		digit := vlq & 31
+		vlq >>= 5
+		if vlq > 0 {
+			digit |= 32
+		}
+		b.WriteByte(base64Digits[digit])
+		if vlq == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/twerge/scan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGeneratedCSSWithSourceMap(t *testing.T) {
+	SetMapping(map[string]string{"flex": "tw-a", "p-2 p-4": "tw-b", "grid": "tw-c"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	var css, sourceMap bytes.Buffer
+	err := WriteGeneratedCSSWithSourceMap(&css, &sourceMap, "static/styles.css", []scan.Occurrence{
+		{File: "views/a.templ", Line: 4, Classes: "flex"},
+		{File: "views/b.templ", Line: 2, Classes: "p-2 p-4"},
+		{File: "views/c.templ", Line: 9, Classes: "p-2 p-4"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(css.String(), "}\n/*# sourceMappingURL=styles.css.map */\n"))
+
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(sourceMap.Bytes(), &decoded))
+	assert.Equal(t, map[string]any{
+		"version": float64(3),
+		"file":    "styles.css",
+		"sources": []any{"views/a.templ", "views/b.templ"},
+		"names":   []any{},
+		// tw-a from line 4 of a.templ, tw-b from line 2 of b.templ, tw-c
+		// without a source
+		"mappings": "AAGA;AAAA;AAAA;ACFA;AAAA;AAAA",
+	}, decoded)
+}
+
+func TestWriteVLQ(t *testing.T) {
+	for value, want := range map[int]string{0: "A", 1: "C", -1: "D", 15: "e", 16: "gB", -17: "jB", 1000: "w+B"} {
+		var b strings.Builder
+		writeVLQ(&b, value)
+		assert.Equal(t, want, b.String(), value)
+	}
+}
@@ -0,0 +1,1011 @@
+package twerge
+
+import (
+	"cmp"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WithStandaloneCSS writes plain CSS declarations instead of @apply rules, so
+// the stylesheet works without a Tailwind build step.
+//
+// Utilities that can not be resolved, see ResolveCSS, are listed in a comment
+// of their rule.
+func WithStandaloneCSS() MapOption {
+	return func(o *mapOptions) {
+		o.standalone = true
+	}
+}
+
+// declaration is a CSS property and its value
+type declaration struct {
+	property string
+	value    string
+}
+
+// ruleBlock holds the declarations of a rule for one selector and media query
+type ruleBlock struct {
+	media    string
+	selector string
+	decls    []declaration
+}
+
+// ResolveCSS returns plain CSS for a rule of className applying classes,
+// without @apply, and the classes it could not resolve.
+//
+// Common utilities are resolved using the default Tailwind theme: spacing,
+// sizing, colors with opacity modifiers, typography, borders, layout and
+// arbitrary values. Variants are resolved for pseudo-classes like hover:,
+// group-hover: and peer-focus:, named like group-hover/edit: or arbitrary like
+// group-[.open]:, the attributes aria-*: and data-*:, the
+// breakpoints sm: to 2xl:, dark:, print:, motion-safe:/motion-reduce: and the
+// custom variants of Config.Variants.
+//
+//	css, unresolved := twerge.ResolveCSS("tw-0", "p-4 hover:bg-red-500/50")
+//	// .tw-0 {
+//	// 	padding: 1rem;
+//	// }
+//	// .tw-0:hover {
+//	// 	background-color: rgb(239 68 68 / 0.5);
+//	// }
+func ResolveCSS(className, classes string) (string, []string) {
+	blocks, unresolved := resolveRule(className, classes)
+
+	var builder strings.Builder
+	for i, block := range blocks {
+		indent := ""
+		if block.media != "" {
+			builder.WriteString("@media " + block.media + " { \n")
+			indent = "\t"
+		}
+		builder.WriteString(indent + block.selector + " { \n")
+		for _, d := range block.decls {
+			builder.WriteString(indent + "\t" + d.property + ": " + d.value + "; \n")
+		}
+		if i == 0 && len(unresolved) > 0 {
+			builder.WriteString("\t/* twerge: unresolved " + strings.Join(unresolved, " ") + " */ \n")
+		}
+		builder.WriteString(indent + "}\n")
+		if block.media != "" {
+			builder.WriteString("}\n")
+		}
+	}
+	return builder.String(), unresolved
+}
+
+// writeStandaloneRule writes the plain CSS rule for the class name followed by
+// raw CSS, if any.
+func writeStandaloneRule(w io.Writer, className, classes, raw string) error {
+	css, _ := ResolveCSS(className, classes)
+	if raw != "" {
+		css += raw + "\n"
+	}
+	_, err := io.WriteString(w, css)
+	return err
+}
+
+// resolveRule resolves classes into rule blocks for the class name, the first
+// being the block of classes without variants.
+func resolveRule(className, classes string) ([]ruleBlock, []string) {
+	conf := currentConfig()
+	splitModifiers := makeSplitModifiers(conf)
+	blocks := []ruleBlock{{selector: "." + className}}
+	var unresolved []string
+	for _, class := range strings.Fields(classes) {
+		baseClass, modifiers, hasImportant, _ := splitModifiers(class)
+		media, selector, ok := resolveVariants(conf, "."+className, modifiers)
+		if !ok {
+			unresolved = append(unresolved, class)
+			continue
+		}
+		baseClass, ok = stripPrefix(conf, baseClass)
+		if !ok {
+			unresolved = append(unresolved, class)
+			continue
+		}
+		decls, ok := resolveUtility(baseClass)
+		if !ok {
+			unresolved = append(unresolved, class)
+			continue
+		}
+		if hasImportant {
+			for i := range decls {
+				decls[i].value += " !important"
+			}
+		}
+
+		i := slices.IndexFunc(blocks, func(b ruleBlock) bool {
+			return b.media == media && b.selector == selector
+		})
+		if i == -1 {
+			i = len(blocks)
+			blocks = append(blocks, ruleBlock{media: media, selector: selector})
+		}
+		blocks[i].decls = append(blocks[i].decls, decls...)
+	}
+
+	// media queries come after the rules they override, in breakpoint order
+	mediaVariants := conf.mediaVariants()
+	slices.SortStableFunc(blocks[1:], func(a, b ruleBlock) int {
+		return mediaOrder(mediaVariants, a.media) - mediaOrder(mediaVariants, b.media)
+	})
+	return blocks, unresolved
+}
+
+// mediaVariant is a variant resolved to a media query
+type mediaVariant struct {
+	name  string
+	query string
+}
+
+// DefaultScreens returns the breakpoints of Tailwind, which WithStandaloneCSS
+// uses if Config.Screens is nil.
+func DefaultScreens() map[string]string {
+	return map[string]string{
+		"sm":  "640px",
+		"md":  "768px",
+		"lg":  "1024px",
+		"xl":  "1280px",
+		"2xl": "1536px",
+	}
+}
+
+// defaultMediaVariants are the media variants of the default breakpoints
+var defaultMediaVariants = newMediaVariants(DefaultScreens())
+
+// newMediaVariants returns the variants resolved to media queries, with the
+// breakpoints of screens ordered by their width, in the order their rules are
+// written.
+func newMediaVariants(screens map[string]string) []mediaVariant {
+	variants := []mediaVariant{
+		{"motion-safe", "(prefers-reduced-motion: no-preference)"},
+		{"motion-reduce", "(prefers-reduced-motion: reduce)"},
+	}
+	for _, name := range slices.SortedFunc(maps.Keys(screens), func(a, b string) int {
+		return cmp.Or(cmp.Compare(screenWidth(screens[a]), screenWidth(screens[b])), cmp.Compare(a, b))
+	}) {
+		query := screens[name]
+		if !strings.HasPrefix(query, "(") {
+			query = "(min-width: " + query + ")"
+		}
+		variants = append(variants, mediaVariant{name, query})
+	}
+	return append(variants,
+		mediaVariant{"dark", "(prefers-color-scheme: dark)"},
+		mediaVariant{"print", "print"},
+	)
+}
+
+// screenWidth returns the width of a breakpoint in pixels, with rem and em
+// of 16px, and +Inf for media queries and other units.
+func screenWidth(width string) float64 {
+	scale := 1.0
+	number, ok := strings.CutSuffix(width, "px")
+	if !ok {
+		number, ok = strings.CutSuffix(width, "rem")
+		if !ok {
+			number, ok = strings.CutSuffix(width, "em")
+		}
+		scale = 16
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil {
+		return math.Inf(1)
+	}
+	return value * scale
+}
+
+// mediaVariants returns the media variants of the breakpoints of conf.
+func (c *config) mediaVariants() []mediaVariant {
+	if c.MediaVariants == nil {
+		return defaultMediaVariants
+	}
+	return c.MediaVariants
+}
+
+// mediaOrder returns the position of the media query among variants,
+// ordering combined media queries by their first variant.
+func mediaOrder(variants []mediaVariant, media string) int {
+	for i, v := range variants {
+		if strings.HasPrefix(media, v.query) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// pseudoVariants maps variants to the pseudo-classes and -elements they add
+// to the selector
+var pseudoVariants = map[string]string{
+	"hover":         ":hover",
+	"focus":         ":focus",
+	"focus-visible": ":focus-visible",
+	"focus-within":  ":focus-within",
+	"active":        ":active",
+	"visited":       ":visited",
+	"disabled":      ":disabled",
+	"enabled":       ":enabled",
+	"checked":       ":checked",
+	"required":      ":required",
+	"invalid":       ":invalid",
+	"first":         ":first-child",
+	"last":          ":last-child",
+	"only":          ":only-child",
+	"odd":           ":nth-child(odd)",
+	"even":          ":nth-child(even)",
+	"empty":         ":empty",
+	"placeholder":   "::placeholder",
+	"selection":     "::selection",
+	"marker":        "::marker",
+	"file":          "::file-selector-button",
+	"before":        "::before",
+	"after":         "::after",
+}
+
+// resolveVariants returns the media query and selector of the variants
+// applied to selector.
+func resolveVariants(conf *config, selector string, modifiers []string) (string, string, bool) {
+	mediaVariants := conf.mediaVariants()
+	var queries []string
+	for _, modifier := range modifiers {
+		if template, ok := conf.Variants[modifier]; ok {
+			if query, ok := strings.CutPrefix(template, "@media "); ok {
+				queries = append(queries, query)
+			} else {
+				selector = applyVariant(template, selector)
+			}
+			continue
+		}
+		if modifier == "dark" && conf.DarkMode == DarkModeClass {
+			dark := conf.DarkSelector
+			if dark == "" {
+				dark = DefaultDarkSelector
+			}
+			selector += ":where(" + dark + ", " + dark + " *)"
+			continue
+		}
+		if i := slices.IndexFunc(mediaVariants, func(v mediaVariant) bool {
+			return v.name == modifier
+		}); i != -1 {
+			queries = append(queries, mediaVariants[i].query)
+			continue
+		}
+		if state, ok := stateSelector(modifier); ok {
+			selector += state
+			continue
+		}
+		if kind, state, name, ok := groupVariant(modifier); ok {
+			if group, ok := groupSelector(groupMarker(kind, name), state); ok {
+				combinator := " "
+				if kind == "peer" {
+					combinator = " ~ "
+				}
+				selector = group + combinator + selector
+				continue
+			}
+		}
+		return "", "", false
+	}
+	slices.SortStableFunc(queries, func(a, b string) int {
+		return mediaOrder(mediaVariants, a) - mediaOrder(mediaVariants, b)
+	})
+	return strings.Join(queries, " and "), selector, true
+}
+
+// stateSelector returns the pseudo-class, pseudo-element or attribute
+// selector of a state variant, like hover or aria-expanded.
+func stateSelector(modifier string) (string, bool) {
+	if pseudo, ok := pseudoVariants[modifier]; ok {
+		return pseudo, true
+	}
+	return attributeVariant(modifier)
+}
+
+// resolveUtility returns the declarations of a utility without variants.
+func resolveUtility(class string) ([]declaration, bool) {
+	if decls, ok := staticUtilities[class]; ok {
+		return slices.Clone(decls), true
+	}
+
+	negative := strings.HasPrefix(class, "-")
+	if negative {
+		class = class[1:]
+	}
+
+	// try the longest utility prefix first, e.g. border-t before border
+	for i := len(class); i > 0; i = strings.LastIndexByte(class[:i], '-') {
+		prefix, value := class[:i], strings.TrimPrefix(class[i:], "-")
+		u, ok := valueUtilities[prefix]
+		if !ok || (negative && !u.negatable) {
+			continue
+		}
+		decls, ok := u.resolve(value)
+		if !ok {
+			continue
+		}
+		if negative {
+			for j := range decls {
+				decls[j].value = negateValue(decls[j].value)
+			}
+		}
+		return decls, true
+	}
+	return nil, false
+}
+
+// negateValue returns the negative of a CSS length.
+func negateValue(value string) string {
+	switch {
+	case value == "0px" || value == "0":
+		return value
+	case value[0] >= '0' && value[0] <= '9' || value[0] == '.':
+		return "-" + value
+	default:
+		return "calc(" + value + " * -1)"
+	}
+}
+
+// valueUtility resolves the value of a utility, the part after its prefix
+type valueUtility struct {
+	resolve func(value string) ([]declaration, bool)
+	// negatable utilities accept a leading "-", like -mt-4
+	negatable bool
+}
+
+// valueUtilities maps utility prefixes to their resolvers
+var valueUtilities = map[string]valueUtility{
+	"p":  {resolve: spacingProperties("padding")},
+	"px": {resolve: spacingProperties("padding-left", "padding-right")},
+	"py": {resolve: spacingProperties("padding-top", "padding-bottom")},
+	"pt": {resolve: spacingProperties("padding-top")},
+	"pr": {resolve: spacingProperties("padding-right")},
+	"pb": {resolve: spacingProperties("padding-bottom")},
+	"pl": {resolve: spacingProperties("padding-left")},
+	"ps": {resolve: spacingProperties("padding-inline-start")},
+	"pe": {resolve: spacingProperties("padding-inline-end")},
+
+	"m":  {resolve: marginProperties("margin"), negatable: true},
+	"mx": {resolve: marginProperties("margin-left", "margin-right"), negatable: true},
+	"my": {resolve: marginProperties("margin-top", "margin-bottom"), negatable: true},
+	"mt": {resolve: marginProperties("margin-top"), negatable: true},
+	"mr": {resolve: marginProperties("margin-right"), negatable: true},
+	"mb": {resolve: marginProperties("margin-bottom"), negatable: true},
+	"ml": {resolve: marginProperties("margin-left"), negatable: true},
+	"ms": {resolve: marginProperties("margin-inline-start"), negatable: true},
+	"me": {resolve: marginProperties("margin-inline-end"), negatable: true},
+
+	"gap":   {resolve: spacingProperties("gap")},
+	"gap-x": {resolve: spacingProperties("column-gap")},
+	"gap-y": {resolve: spacingProperties("row-gap")},
+
+	"w":     {resolve: sizeProperties("vw", "width")},
+	"h":     {resolve: sizeProperties("vh", "height")},
+	"size":  {resolve: sizeProperties("", "width", "height")},
+	"min-w": {resolve: sizeProperties("vw", "min-width")},
+	"min-h": {resolve: sizeProperties("vh", "min-height")},
+	"max-w": {resolve: maxWidth},
+	"max-h": {resolve: sizeProperties("vh", "max-height")},
+	"basis": {resolve: sizeProperties("", "flex-basis")},
+
+	"inset":   {resolve: sizeProperties("", "inset"), negatable: true},
+	"inset-x": {resolve: sizeProperties("", "left", "right"), negatable: true},
+	"inset-y": {resolve: sizeProperties("", "top", "bottom"), negatable: true},
+	"top":     {resolve: sizeProperties("", "top"), negatable: true},
+	"right":   {resolve: sizeProperties("", "right"), negatable: true},
+	"bottom":  {resolve: sizeProperties("", "bottom"), negatable: true},
+	"left":    {resolve: sizeProperties("", "left"), negatable: true},
+
+	"text":   {resolve: text},
+	"bg":     {resolve: colorProperties("background-color")},
+	"fill":   {resolve: colorProperties("fill")},
+	"stroke": {resolve: colorProperties("stroke")},
+	"accent": {resolve: colorProperties("accent-color")},
+	"caret":  {resolve: colorProperties("caret-color")},
+
+	"border":   {resolve: borderProperties("border-color", "border-width")},
+	"border-x": {resolve: borderProperties("", "border-left-width", "border-right-width")},
+	"border-y": {resolve: borderProperties("", "border-top-width", "border-bottom-width")},
+	"border-t": {resolve: borderProperties("", "border-top-width")},
+	"border-r": {resolve: borderProperties("", "border-right-width")},
+	"border-b": {resolve: borderProperties("", "border-bottom-width")},
+	"border-l": {resolve: borderProperties("", "border-left-width")},
+
+	"rounded":    {resolve: radiusProperties("border-radius")},
+	"rounded-t":  {resolve: radiusProperties("border-top-left-radius", "border-top-right-radius")},
+	"rounded-r":  {resolve: radiusProperties("border-top-right-radius", "border-bottom-right-radius")},
+	"rounded-b":  {resolve: radiusProperties("border-bottom-right-radius", "border-bottom-left-radius")},
+	"rounded-l":  {resolve: radiusProperties("border-top-left-radius", "border-bottom-left-radius")},
+	"rounded-tl": {resolve: radiusProperties("border-top-left-radius")},
+	"rounded-tr": {resolve: radiusProperties("border-top-right-radius")},
+	"rounded-br": {resolve: radiusProperties("border-bottom-right-radius")},
+	"rounded-bl": {resolve: radiusProperties("border-bottom-left-radius")},
+
+	"font":     {resolve: font},
+	"leading":  {resolve: themeProperties(leadings, spacingValue, "line-height")},
+	"tracking": {resolve: themeProperties(trackings, nil, "letter-spacing"), negatable: true},
+	"shadow":   {resolve: themeProperties(shadows, nil, "box-shadow")},
+	"opacity":  {resolve: themeProperties(nil, percentValue, "opacity")},
+	"z":        {resolve: themeProperties(map[string]string{"auto": "auto"}, integerValue, "z-index"), negatable: true},
+	"order":    {resolve: themeProperties(map[string]string{"first": "-9999", "last": "9999", "none": "0"}, integerValue, "order"), negatable: true},
+
+	"grid-cols": {resolve: gridTracks("grid-template-columns")},
+	"grid-rows": {resolve: gridTracks("grid-template-rows")},
+	"col-span": {resolve: func(v string) ([]declaration, bool) {
+		if v == "full" {
+			return []declaration{{"grid-column", "1 / -1"}}, true
+		}
+		n, ok := integerValue(v)
+		return []declaration{{"grid-column", "span " + n + " / span " + n}}, ok
+	}},
+	"row-span": {resolve: func(v string) ([]declaration, bool) {
+		if v == "full" {
+			return []declaration{{"grid-row", "1 / -1"}}, true
+		}
+		n, ok := integerValue(v)
+		return []declaration{{"grid-row", "span " + n + " / span " + n}}, ok
+	}},
+}
+
+// declarations returns one declaration of value per property.
+func declarations(value string, properties []string) []declaration {
+	decls := make([]declaration, len(properties))
+	for i, property := range properties {
+		decls[i] = declaration{property, value}
+	}
+	return decls
+}
+
+// spacingProperties resolves spacing scale values.
+func spacingProperties(properties ...string) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		value, ok := spacingValue(v)
+		return declarations(value, properties), ok
+	}
+}
+
+// marginProperties resolves spacing scale values and auto.
+func marginProperties(properties ...string) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		if v == "auto" {
+			return declarations("auto", properties), true
+		}
+		value, ok := spacingValue(v)
+		return declarations(value, properties), ok
+	}
+}
+
+// sizeProperties resolves spacing scale values, fractions and keywords, with
+// screen resolving to 100 of the viewport unit.
+func sizeProperties(viewportUnit string, properties ...string) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		value, ok := sizeValue(v)
+		if !ok && v == "screen" && viewportUnit != "" {
+			value, ok = "100"+viewportUnit, true
+		}
+		return declarations(value, properties), ok
+	}
+}
+
+// maxWidths are the max-w-* sizes of the default theme
+var maxWidths = map[string]string{
+	"none":       "none",
+	"xs":         "20rem",
+	"sm":         "24rem",
+	"md":         "28rem",
+	"lg":         "32rem",
+	"xl":         "36rem",
+	"2xl":        "42rem",
+	"3xl":        "48rem",
+	"4xl":        "56rem",
+	"5xl":        "64rem",
+	"6xl":        "72rem",
+	"7xl":        "80rem",
+	"prose":      "65ch",
+	"screen-sm":  "640px",
+	"screen-md":  "768px",
+	"screen-lg":  "1024px",
+	"screen-xl":  "1280px",
+	"screen-2xl": "1536px",
+}
+
+// maxWidth resolves max-w-* values.
+func maxWidth(v string) ([]declaration, bool) {
+	if value, ok := maxWidths[v]; ok {
+		return []declaration{{"max-width", value}}, true
+	}
+	return sizeProperties("", "max-width")(v)
+}
+
+// fontSizes are the text-* sizes of the default theme with their line heights
+var fontSizes = map[string][2]string{
+	"xs":   {"0.75rem", "1rem"},
+	"sm":   {"0.875rem", "1.25rem"},
+	"base": {"1rem", "1.5rem"},
+	"lg":   {"1.125rem", "1.75rem"},
+	"xl":   {"1.25rem", "1.75rem"},
+	"2xl":  {"1.5rem", "2rem"},
+	"3xl":  {"1.875rem", "2.25rem"},
+	"4xl":  {"2.25rem", "2.5rem"},
+	"5xl":  {"3rem", "1"},
+	"6xl":  {"3.75rem", "1"},
+	"7xl":  {"4.5rem", "1"},
+	"8xl":  {"6rem", "1"},
+	"9xl":  {"8rem", "1"},
+}
+
+// text resolves font sizes and text colors.
+func text(v string) ([]declaration, bool) {
+	if size, ok := fontSizes[v]; ok {
+		return []declaration{{"font-size", size[0]}, {"line-height", size[1]}}, true
+	}
+	if isArbitraryLength(v) {
+		value, _ := arbitraryValue(v)
+		return []declaration{{"font-size", value}}, true
+	}
+	return colorProperties("color")(v)
+}
+
+// colorProperties resolves colors.
+func colorProperties(properties ...string) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		value, ok := colorValue(v)
+		return declarations(value, properties), ok
+	}
+}
+
+// borderProperties resolves border widths of properties and, if
+// colorProperty is not empty, border colors.
+func borderProperties(colorProperty string, properties ...string) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		if v == "" {
+			return declarations("1px", properties), true
+		}
+		if n, ok := integerValue(v); ok {
+			return declarations(n+"px", properties), true
+		}
+		if isArbitraryLength(v) {
+			value, _ := arbitraryValue(v)
+			return declarations(value, properties), true
+		}
+		if colorProperty == "" {
+			return nil, false
+		}
+		return colorProperties(colorProperty)(v)
+	}
+}
+
+// radii are the rounded-* sizes of the default theme
+var radii = map[string]string{
+	"":     "0.25rem",
+	"none": "0px",
+	"sm":   "0.125rem",
+	"md":   "0.375rem",
+	"lg":   "0.5rem",
+	"xl":   "0.75rem",
+	"2xl":  "1rem",
+	"3xl":  "1.5rem",
+	"full": "9999px",
+}
+
+// radiusProperties resolves border radii.
+func radiusProperties(properties ...string) func(string) ([]declaration, bool) {
+	return themeProperties(radii, nil, properties...)
+}
+
+// fontWeights are the font-* weights of the default theme
+var fontWeights = map[string]string{
+	"thin":       "100",
+	"extralight": "200",
+	"light":      "300",
+	"normal":     "400",
+	"medium":     "500",
+	"semibold":   "600",
+	"bold":       "700",
+	"extrabold":  "800",
+	"black":      "900",
+}
+
+// fontFamilies are the font-* families of the default theme
+var fontFamilies = map[string]string{
+	"sans":  `ui-sans-serif, system-ui, sans-serif, "Apple Color Emoji", "Segoe UI Emoji", "Segoe UI Symbol", "Noto Color Emoji"`,
+	"serif": `ui-serif, Georgia, Cambria, "Times New Roman", Times, serif`,
+	"mono":  `ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, "Liberation Mono", "Courier New", monospace`,
+}
+
+// font resolves font weights and families.
+func font(v string) ([]declaration, bool) {
+	if weight, ok := fontWeights[v]; ok {
+		return []declaration{{"font-weight", weight}}, true
+	}
+	if family, ok := fontFamilies[v]; ok {
+		return []declaration{{"font-family", family}}, true
+	}
+	return nil, false
+}
+
+// leadings are the leading-* line heights of the default theme
+var leadings = map[string]string{
+	"none":    "1",
+	"tight":   "1.25",
+	"snug":    "1.375",
+	"normal":  "1.5",
+	"relaxed": "1.625",
+	"loose":   "2",
+}
+
+// trackings are the tracking-* letter spacings of the default theme
+var trackings = map[string]string{
+	"tighter": "-0.05em",
+	"tight":   "-0.025em",
+	"normal":  "0em",
+	"wide":    "0.025em",
+	"wider":   "0.05em",
+	"widest":  "0.1em",
+}
+
+// shadows are the shadow-* box shadows of the default theme
+var shadows = map[string]string{
+	"":      "0 1px 3px 0 rgb(0 0 0 / 0.1), 0 1px 2px -1px rgb(0 0 0 / 0.1)",
+	"sm":    "0 1px 2px 0 rgb(0 0 0 / 0.05)",
+	"md":    "0 4px 6px -1px rgb(0 0 0 / 0.1), 0 2px 4px -2px rgb(0 0 0 / 0.1)",
+	"lg":    "0 10px 15px -3px rgb(0 0 0 / 0.1), 0 4px 6px -4px rgb(0 0 0 / 0.1)",
+	"xl":    "0 20px 25px -5px rgb(0 0 0 / 0.1), 0 8px 10px -6px rgb(0 0 0 / 0.1)",
+	"2xl":   "0 25px 50px -12px rgb(0 0 0 / 0.25)",
+	"inner": "inset 0 2px 4px 0 rgb(0 0 0 / 0.05)",
+	"none":  "0 0 #0000",
+}
+
+// themeProperties resolves the values of a theme, then values accepted by
+// fallback, if any, then arbitrary values.
+func themeProperties(
+	theme map[string]string,
+	fallback func(string) (string, bool),
+	properties ...string,
+) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		if value, ok := theme[v]; ok {
+			return declarations(value, properties), true
+		}
+		if fallback != nil {
+			if value, ok := fallback(v); ok {
+				return declarations(value, properties), true
+			}
+		}
+		value, ok := arbitraryValue(v)
+		return declarations(value, properties), ok
+	}
+}
+
+// gridTracks resolves grid-cols-* and grid-rows-* values.
+func gridTracks(property string) func(string) ([]declaration, bool) {
+	return func(v string) ([]declaration, bool) {
+		if v == "none" {
+			return []declaration{{property, "none"}}, true
+		}
+		if n, ok := integerValue(v); ok {
+			return []declaration{{property, "repeat(" + n + ", minmax(0, 1fr))"}}, true
+		}
+		value, ok := arbitraryValue(v)
+		return []declaration{{property, value}}, ok
+	}
+}
+
+// arbitraryValue returns the CSS value of an arbitrary value like
+// [calc(100%_-_1rem)], with underscores as spaces, and without a type hint
+// like the length: of [length:12px].
+func arbitraryValue(v string) (string, bool) {
+	inner, ok := strings.CutPrefix(v, "[")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, "]")
+	if !ok || inner == "" {
+		return "", false
+	}
+	if hint, value, ok := strings.Cut(inner, ":"); ok && isTypeHint(hint) {
+		if value == "" {
+			return "", false
+		}
+		inner = value
+	}
+	return strings.ReplaceAll(inner, "_", " "), true
+}
+
+// isTypeHint reports whether s is a type hint of an arbitrary value, like
+// the labels matched by arbitraryRegex.
+func isTypeHint(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// spacingValue resolves values of the spacing scale, where each step is
+// 0.25rem, and arbitrary values.
+func spacingValue(v string) (string, bool) {
+	switch v {
+	case "0":
+		return "0px", true
+	case "px":
+		return "1px", true
+	}
+	if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 && math.Mod(n*4, 1) == 0 && v[0] != '.' {
+		return formatNumber(n/4) + "rem", true
+	}
+	return arbitraryValue(v)
+}
+
+// sizeValue resolves spacing values, fractions and sizing keywords.
+func sizeValue(v string) (string, bool) {
+	switch v {
+	case "auto", "min-content", "max-content", "fit-content":
+		return v, true
+	case "full":
+		return "100%", true
+	case "min", "max", "fit":
+		return v + "-content", true
+	}
+	if num, den, ok := strings.Cut(v, "/"); ok {
+		n, err1 := strconv.Atoi(num)
+		d, err2 := strconv.Atoi(den)
+		if err1 != nil || err2 != nil || d == 0 {
+			return "", false
+		}
+		return formatNumber(math.Round(float64(n)/float64(d)*100*1e6)/1e6) + "%", true
+	}
+	return spacingValue(v)
+}
+
+// percentValue resolves a percentage like 50 to 0.5.
+func percentValue(v string) (string, bool) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > 100 {
+		return "", false
+	}
+	return formatNumber(float64(n) / 100), true
+}
+
+// integerValue resolves a non-negative integer.
+func integerValue(v string) (string, bool) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return "", false
+	}
+	return strconv.Itoa(n), true
+}
+
+// colorValue resolves a color of the default theme or an arbitrary color,
+// with an optional opacity modifier like red-500/50.
+func colorValue(v string) (string, bool) {
+	color, alpha, hasAlpha := cutOpacityModifier(v)
+	value, ok := namedColor(color)
+	if !ok {
+		value, ok = arbitraryValue(color)
+		if !ok || isArbitraryLength(color) {
+			return "", false
+		}
+	}
+	if !hasAlpha {
+		return value, true
+	}
+
+	opacity, ok := percentValue(alpha)
+	if !ok {
+		opacity, ok = arbitraryValue(alpha)
+		if !ok {
+			return "", false
+		}
+	}
+	if rgb, ok := hexToRGB(value); ok {
+		return "rgb(" + rgb + " / " + opacity + ")", true
+	}
+	percent := opacity
+	if f, err := strconv.ParseFloat(opacity, 64); err == nil {
+		percent = formatNumber(f*100) + "%"
+	}
+	return "color-mix(in srgb, " + value + " " + percent + ", transparent)", true
+}
+
+// cutOpacityModifier splits a color at its opacity modifier, the last "/"
+// outside of brackets.
+func cutOpacityModifier(v string) (color, alpha string, ok bool) {
+	depth := 0
+	for i := len(v) - 1; i >= 0; i-- {
+		switch v[i] {
+		case ']':
+			depth++
+		case '[':
+			depth--
+		case '/':
+			if depth == 0 {
+				return v[:i], v[i+1:], true
+			}
+		}
+	}
+	return v, "", false
+}
+
+// namedColor resolves a color of the default theme, like red-500 or white.
+func namedColor(v string) (string, bool) {
+	if value, ok := specialColors[v]; ok {
+		return value, true
+	}
+	name, shade, ok := strings.Cut(v, "-")
+	if !ok {
+		return "", false
+	}
+	shades, ok := tailwindColors[name]
+	if !ok {
+		return "", false
+	}
+	i := slices.Index(colorShades, shade)
+	if i == -1 {
+		return "", false
+	}
+	return shades[i], true
+}
+
+// hexToRGB converts a #rgb or #rrggbb color to space separated channels.
+func hexToRGB(hex string) (string, bool) {
+	hex, ok := strings.CutPrefix(hex, "#")
+	if !ok {
+		return "", false
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return "", false
+	}
+	channels := make([]string, 3)
+	for i := range channels {
+		c, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return "", false
+		}
+		channels[i] = strconv.FormatUint(c, 10)
+	}
+	return strings.Join(channels, " "), true
+}
+
+// formatNumber formats n without trailing zeros.
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// staticUtilities maps utilities without values to their declarations
+var staticUtilities = map[string][]declaration{
+	"block":        {{"display", "block"}},
+	"inline-block": {{"display", "inline-block"}},
+	"inline":       {{"display", "inline"}},
+	"flex":         {{"display", "flex"}},
+	"inline-flex":  {{"display", "inline-flex"}},
+	"grid":         {{"display", "grid"}},
+	"inline-grid":  {{"display", "inline-grid"}},
+	"table":        {{"display", "table"}},
+	"contents":     {{"display", "contents"}},
+	"hidden":       {{"display", "none"}},
+
+	"static":   {{"position", "static"}},
+	"fixed":    {{"position", "fixed"}},
+	"absolute": {{"position", "absolute"}},
+	"relative": {{"position", "relative"}},
+	"sticky":   {{"position", "sticky"}},
+
+	"visible":   {{"visibility", "visible"}},
+	"invisible": {{"visibility", "hidden"}},
+
+	"flex-row":          {{"flex-direction", "row"}},
+	"flex-row-reverse":  {{"flex-direction", "row-reverse"}},
+	"flex-col":          {{"flex-direction", "column"}},
+	"flex-col-reverse":  {{"flex-direction", "column-reverse"}},
+	"flex-wrap":         {{"flex-wrap", "wrap"}},
+	"flex-wrap-reverse": {{"flex-wrap", "wrap-reverse"}},
+	"flex-nowrap":       {{"flex-wrap", "nowrap"}},
+	"flex-1":            {{"flex", "1 1 0%"}},
+	"flex-auto":         {{"flex", "1 1 auto"}},
+	"flex-initial":      {{"flex", "0 1 auto"}},
+	"flex-none":         {{"flex", "none"}},
+	"grow":              {{"flex-grow", "1"}},
+	"grow-0":            {{"flex-grow", "0"}},
+	"shrink":            {{"flex-shrink", "1"}},
+	"shrink-0":          {{"flex-shrink", "0"}},
+
+	"items-start":     {{"align-items", "flex-start"}},
+	"items-end":       {{"align-items", "flex-end"}},
+	"items-center":    {{"align-items", "center"}},
+	"items-baseline":  {{"align-items", "baseline"}},
+	"items-stretch":   {{"align-items", "stretch"}},
+	"justify-start":   {{"justify-content", "flex-start"}},
+	"justify-end":     {{"justify-content", "flex-end"}},
+	"justify-center":  {{"justify-content", "center"}},
+	"justify-between": {{"justify-content", "space-between"}},
+	"justify-around":  {{"justify-content", "space-around"}},
+	"justify-evenly":  {{"justify-content", "space-evenly"}},
+	"self-auto":       {{"align-self", "auto"}},
+	"self-start":      {{"align-self", "flex-start"}},
+	"self-end":        {{"align-self", "flex-end"}},
+	"self-center":     {{"align-self", "center"}},
+	"self-stretch":    {{"align-self", "stretch"}},
+
+	"overflow-auto":       {{"overflow", "auto"}},
+	"overflow-hidden":     {{"overflow", "hidden"}},
+	"overflow-visible":    {{"overflow", "visible"}},
+	"overflow-scroll":     {{"overflow", "scroll"}},
+	"overflow-x-auto":     {{"overflow-x", "auto"}},
+	"overflow-x-hidden":   {{"overflow-x", "hidden"}},
+	"overflow-x-scroll":   {{"overflow-x", "scroll"}},
+	"overflow-y-auto":     {{"overflow-y", "auto"}},
+	"overflow-y-hidden":   {{"overflow-y", "hidden"}},
+	"overflow-y-scroll":   {{"overflow-y", "scroll"}},
+	"text-left":           {{"text-align", "left"}},
+	"text-center":         {{"text-align", "center"}},
+	"text-right":          {{"text-align", "right"}},
+	"text-justify":        {{"text-align", "justify"}},
+	"text-start":          {{"text-align", "start"}},
+	"text-end":            {{"text-align", "end"}},
+	"italic":              {{"font-style", "italic"}},
+	"not-italic":          {{"font-style", "normal"}},
+	"uppercase":           {{"text-transform", "uppercase"}},
+	"lowercase":           {{"text-transform", "lowercase"}},
+	"capitalize":          {{"text-transform", "capitalize"}},
+	"normal-case":         {{"text-transform", "none"}},
+	"underline":           {{"text-decoration-line", "underline"}},
+	"overline":            {{"text-decoration-line", "overline"}},
+	"line-through":        {{"text-decoration-line", "line-through"}},
+	"no-underline":        {{"text-decoration-line", "none"}},
+	"whitespace-normal":   {{"white-space", "normal"}},
+	"whitespace-nowrap":   {{"white-space", "nowrap"}},
+	"whitespace-pre":      {{"white-space", "pre"}},
+	"whitespace-pre-line": {{"white-space", "pre-line"}},
+	"whitespace-pre-wrap": {{"white-space", "pre-wrap"}},
+	"break-words":         {{"overflow-wrap", "break-word"}},
+	"break-all":           {{"word-break", "break-all"}},
+	"truncate":            {{"overflow", "hidden"}, {"text-overflow", "ellipsis"}, {"white-space", "nowrap"}},
+	"antialiased":         {{"-webkit-font-smoothing", "antialiased"}, {"-moz-osx-font-smoothing", "grayscale"}},
+
+	"border-solid":  {{"border-style", "solid"}},
+	"border-dashed": {{"border-style", "dashed"}},
+	"border-dotted": {{"border-style", "dotted"}},
+	"border-double": {{"border-style", "double"}},
+	"border-none":   {{"border-style", "none"}},
+
+	"list-none":    {{"list-style-type", "none"}},
+	"list-disc":    {{"list-style-type", "disc"}},
+	"list-decimal": {{"list-style-type", "decimal"}},
+
+	"cursor-auto":         {{"cursor", "auto"}},
+	"cursor-default":      {{"cursor", "default"}},
+	"cursor-pointer":      {{"cursor", "pointer"}},
+	"cursor-wait":         {{"cursor", "wait"}},
+	"cursor-text":         {{"cursor", "text"}},
+	"cursor-move":         {{"cursor", "move"}},
+	"cursor-not-allowed":  {{"cursor", "not-allowed"}},
+	"pointer-events-none": {{"pointer-events", "none"}},
+	"pointer-events-auto": {{"pointer-events", "auto"}},
+	"select-none":         {{"user-select", "none"}},
+	"select-text":         {{"user-select", "text"}},
+	"select-all":          {{"user-select", "all"}},
+	"select-auto":         {{"user-select", "auto"}},
+
+	"sr-only": {
+		{"position", "absolute"},
+		{"width", "1px"},
+		{"height", "1px"},
+		{"padding", "0"},
+		{"margin", "-1px"},
+		{"overflow", "hidden"},
+		{"clip", "rect(0, 0, 0, 0)"},
+		{"white-space", "nowrap"},
+		{"border-width", "0"},
+	},
+}
@@ -0,0 +1,165 @@
+package twerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveUtility(t *testing.T) {
+	for class, want := range map[string][]declaration{
+		"p-4":                   {{"padding", "1rem"}},
+		"px-2.5":                {{"padding-left", "0.625rem"}, {"padding-right", "0.625rem"}},
+		"mt-px":                 {{"margin-top", "1px"}},
+		"-mt-4":                 {{"margin-top", "-1rem"}},
+		"mx-auto":               {{"margin-left", "auto"}, {"margin-right", "auto"}},
+		"-ml-[calc(1rem)]":      {{"margin-left", "calc(calc(1rem) * -1)"}},
+		"m-0":                   {{"margin", "0px"}},
+		"w-1/3":                 {{"width", "33.333333%"}},
+		"h-screen":              {{"height", "100vh"}},
+		"w-[calc(100%_-_1rem)]": {{"width", "calc(100% - 1rem)"}},
+		"max-w-prose":           {{"max-width", "65ch"}},
+		"text-red-500":          {{"color", "#ef4444"}},
+		"bg-blue-500/50":        {{"background-color", "rgb(59 130 246 / 0.5)"}},
+		"bg-[#abc]/[.25]":       {{"background-color", "rgb(170 187 204 / .25)"}},
+		"text-current/50":       {{"color", "color-mix(in srgb, currentColor 50%, transparent)"}},
+		"text-lg":               {{"font-size", "1.125rem"}, {"line-height", "1.75rem"}},
+		"text-[13px]":           {{"font-size", "13px"}},
+		"text-[length:12px]":    {{"font-size", "12px"}},
+		"text-[color:#fff]":     {{"color", "#fff"}},
+		"bg-[color:#abc]/50":    {{"background-color", "rgb(170 187 204 / 0.5)"}},
+		"w-[length:50%]":        {{"width", "50%"}},
+		"text-center":           {{"text-align", "center"}},
+		"font-semibold":         {{"font-weight", "600"}},
+		"leading-6":             {{"line-height", "1.5rem"}},
+		"-tracking-wide":        {{"letter-spacing", "-0.025em"}},
+		"border":                {{"border-width", "1px"}},
+		"border-t-2":            {{"border-top-width", "2px"}},
+		"border-gray-200":       {{"border-color", "#e5e7eb"}},
+		"rounded-lg":            {{"border-radius", "0.5rem"}},
+		"rounded":               {{"border-radius", "0.25rem"}},
+		"opacity-75":            {{"opacity", "0.75"}},
+		"-z-10":                 {{"z-index", "-10"}},
+		"grid-cols-3":           {{"grid-template-columns", "repeat(3, minmax(0, 1fr))"}},
+		"hidden":                {{"display", "none"}},
+	} {
+		got, ok := resolveUtility(class)
+		assert.True(t, ok, class)
+		assert.Equal(t, want, got, class)
+	}
+
+	for _, class := range []string{"-bg-red-500", "text-sm/6", "bg-nope-500", "ring-2", "border-x-red-500", "p-1.3"} {
+		_, ok := resolveUtility(class)
+		assert.False(t, ok, class)
+	}
+}
+
+func TestResolveCSS(t *testing.T) {
+	css, unresolved := ResolveCSS("tw-0", "md:p-8 p-4 hover:bg-red-500 sm:dark:hidden !m-2 group-hover:underline ring-2 fancy:flex")
+	assert.Equal(t, []string{"ring-2", "fancy:flex"}, unresolved)
+	assert.Equal(t, strings.Join([]string{
+		".tw-0 { ",
+		"\tpadding: 1rem; ",
+		"\tmargin: 0.5rem !important; ",
+		"\t/* twerge: unresolved ring-2 fancy:flex */ ",
+		"}",
+		".tw-0:hover { ",
+		"\tbackground-color: #ef4444; ",
+		"}",
+		".group:hover .tw-0 { ",
+		"\ttext-decoration-line: underline; ",
+		"}",
+		"@media (min-width: 640px) and (prefers-color-scheme: dark) { ",
+		"\t.tw-0 { ",
+		"\t\tdisplay: none; ",
+		"\t}",
+		"}",
+		"@media (min-width: 768px) { ",
+		"\t.tw-0 { ",
+		"\t\tpadding: 2rem; ",
+		"\t}",
+		"}",
+		"",
+	}, "\n"), css)
+}
+
+func TestResolveCSSTypeHints(t *testing.T) {
+	css, unresolved := ResolveCSS("tw-0", "text-[length:12px] bg-[color:#fff]")
+	assert.Empty(t, unresolved)
+	assert.Equal(t, ".tw-0 { \n\tfont-size: 12px; \n\tbackground-color: #fff; \n}\n", css)
+}
+
+func TestWithStandaloneCSS(t *testing.T) {
+	var out strings.Builder
+	assert.NoError(t, WriteCSS(&out, map[string]string{"p-2 p-4": "tw-pad"}, WithStandaloneCSS(), WithPrefix("app-")))
+	assert.Equal(t, ".app-tw-pad { \n\tpadding: 1rem; \n}\n", out.String())
+}
+
+func TestResolveCSSVariants(t *testing.T) {
+	conf := DefaultConfig()
+	conf.DarkMode = DarkModeClass
+	conf.Variants = map[string]string{
+		"hocus":  "&:is(:hover, :focus)",
+		"themed": ".theme-blue &",
+		"tall":   "@media (min-height: 800px)",
+	}
+	SetConfig(conf)
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	for classes, want := range map[string]string{
+		"dark:hidden":                      ".tw-0:where(.dark, .dark *) { \n\tdisplay: none; \n}\n",
+		"hocus:underline":                  ".tw-0:is(:hover, :focus) { \n\ttext-decoration-line: underline; \n}\n",
+		"themed:hidden":                    ".theme-blue .tw-0 { \n\tdisplay: none; \n}\n",
+		"tall:hidden":                      "@media (min-height: 800px) { \n\t.tw-0 { \n\t\tdisplay: none; \n\t}\n}\n",
+		"aria-expanded:hidden":             ".tw-0[aria-expanded=\"true\"] { \n\tdisplay: none; \n}\n",
+		"aria-[sort=ascending]:hidden":     ".tw-0[aria-sort=ascending] { \n\tdisplay: none; \n}\n",
+		"data-[state=open]:hidden":         ".tw-0[data-state=open] { \n\tdisplay: none; \n}\n",
+		"data-active:hidden":               ".tw-0[data-active] { \n\tdisplay: none; \n}\n",
+		"group-aria-checked:hidden":        ".group[aria-checked=\"true\"] .tw-0 { \n\tdisplay: none; \n}\n",
+		"peer-data-[on]:hidden":            ".peer[data-on] ~ .tw-0 { \n\tdisplay: none; \n}\n",
+		"group-hover/edit:hidden":          ".group\\/edit:hover .tw-0 { \n\tdisplay: none; \n}\n",
+		"peer-checked/box:hidden":          ".peer\\/box:checked ~ .tw-0 { \n\tdisplay: none; \n}\n",
+		"group-[.open]:hidden":             ".group.open .tw-0 { \n\tdisplay: none; \n}\n",
+		"group-[:nth-of-type(2)_&]:hidden": ":nth-of-type(2) .group .tw-0 { \n\tdisplay: none; \n}\n",
+		"peer-[.a/b]/x:hidden":             ".peer\\/x.a/b ~ .tw-0 { \n\tdisplay: none; \n}\n",
+	} {
+		css, unresolved := ResolveCSS("tw-0", classes)
+		assert.Empty(t, unresolved, classes)
+		assert.Equal(t, ".tw-0 { \n}\n"+want, css, classes)
+	}
+
+	_, unresolved := ResolveCSS("tw-0", "aria-unknown:hidden data-[]:hidden group-hover/:flex")
+	assert.Equal(t, []string{"aria-unknown:hidden", "data-[]:hidden", "group-hover/:flex"}, unresolved)
+
+	conf.DarkSelector = "[data-theme=dark]"
+	SetConfig(conf)
+	css, _ := ResolveCSS("tw-0", "dark:hidden")
+	assert.Contains(t, css, ".tw-0:where([data-theme=dark], [data-theme=dark] *) {")
+}
+
+func TestScreens(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Screens = map[string]string{
+		"desktop":  "1200px",
+		"tablet":   "900px",
+		"sm":       "40rem",
+		"portrait": "(orientation: portrait)",
+	}
+	SetConfig(conf)
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	css, unresolved := ResolveCSS("tw-0", "portrait:hidden desktop:p-4 tablet:p-2 md:p-8 sm:p-1")
+	assert.Equal(t, []string{"md:p-8"}, unresolved)
+	assert.Equal(t,
+		".tw-0 { \n\t/* twerge: unresolved md:p-8 */ \n}\n"+
+			"@media (min-width: 40rem) { \n\t.tw-0 { \n\t\tpadding: 0.25rem; \n\t}\n}\n"+
+			"@media (min-width: 900px) { \n\t.tw-0 { \n\t\tpadding: 0.5rem; \n\t}\n}\n"+
+			"@media (min-width: 1200px) { \n\t.tw-0 { \n\t\tpadding: 1rem; \n\t}\n}\n"+
+			"@media (orientation: portrait) { \n\t.tw-0 { \n\t\tdisplay: none; \n\t}\n}\n",
+		css,
+	)
+
+	css, _ = ResolveCSS("tw-0", "desktop:dark:p-4")
+	assert.Contains(t, css, "@media (min-width: 1200px) and (prefers-color-scheme: dark) {")
+}
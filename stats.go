@@ -0,0 +1,79 @@
+package twerge
+
+import (
+	"sync/atomic"
+)
+
+// Statistics are the counters of the package-level merger and class map,
+// see Stats.
+type Statistics struct {
+	// CacheHits is the number of merges answered by the merge cache
+	CacheHits uint64 `json:"cache_hits"`
+	// CacheMisses is the number of merges computed as their result was not
+	// cached
+	CacheMisses uint64 `json:"cache_misses"`
+	// CacheEvictions is the number of results dropped by the in-memory merge
+	// cache to make room for new ones
+	CacheEvictions uint64 `json:"cache_evictions"`
+	// Generated is the number of class names generated by It and Merge
+	Generated uint64 `json:"generated"`
+	// MapSize is the number of class strings in the class map
+	MapSize int `json:"map_size"`
+}
+
+// mergeStats counts the cache lookups of Merge and the class names generated
+var mergeStats struct {
+	hits, misses, evictions, generated atomic.Uint64
+}
+
+// Stats returns the counters of Merge and It since the program started, so
+// that services can monitor the overhead of merging classes at runtime.
+//
+// The cache counters cover the package-level Merge, mergers created with New
+// or NewMerge report to Config.CacheMetrics instead.
+func Stats() Statistics {
+	mapMutex.RLock()
+	size := len(ClassMapStr)
+	mapMutex.RUnlock()
+	return Statistics{
+		CacheHits:      mergeStats.hits.Load(),
+		CacheMisses:    mergeStats.misses.Load(),
+		CacheEvictions: mergeStats.evictions.Load(),
+		Generated:      mergeStats.generated.Load(),
+		MapSize:        size,
+	}
+}
+
+// statsMetrics counts the cache lookups of Merge in mergeStats, passing them
+// on to the CacheMetrics of its Config
+type statsMetrics struct {
+	next CacheMetrics
+}
+
+func (m statsMetrics) Hit() {
+	mergeStats.hits.Add(1)
+	if m.next != nil {
+		m.next.Hit()
+	}
+}
+
+func (m statsMetrics) Miss() {
+	mergeStats.misses.Add(1)
+	if m.next != nil {
+		m.next.Miss()
+	}
+}
+
+func (m statsMetrics) Evict() {
+	mergeStats.evictions.Add(1)
+	if m.next != nil {
+		m.next.Evict()
+	}
+}
+
+// withStats returns conf counting its cache lookups in mergeStats.
+func withStats(conf *config) *config {
+	counted := *conf
+	counted.CacheMetrics = statsMetrics{next: conf.CacheMetrics}
+	return &counted
+}
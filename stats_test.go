@@ -0,0 +1,36 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+
+	metrics := &countingMetrics{}
+	conf := DefaultConfig()
+	conf.CacheMetrics = metrics
+	SetConfig(conf)
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	before := Stats()
+	Merge("px-2 px-3 stats-test")
+	Merge("px-2 px-3 stats-test")
+	It("stats-test")
+
+	after := Stats()
+	assert.Equal(t, uint64(1), after.CacheHits-before.CacheHits)
+	assert.Equal(t, uint64(2), after.CacheMisses-before.CacheMisses)
+	// Merge records the first class list, It generates the second
+	assert.Equal(t, uint64(2), after.Generated-before.Generated)
+	assert.Equal(t, 2, after.MapSize)
+	// the CacheMetrics of the Config are notified as well
+	assert.Equal(t, int64(1), metrics.hits.Load())
+	assert.Equal(t, int64(2), metrics.misses.Load())
+
+}
@@ -0,0 +1,129 @@
+package twerge
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Suggestion is a class string worth registering with RegisterClasses.
+type Suggestion struct {
+	// Classes is the most used class string merging to Merged
+	Classes string
+	// Variants are the other class strings merging to Merged
+	Variants []string
+	// Merged is the merged value shared by Classes and Variants
+	Merged string
+	// Name is a suggested class name for Classes and Variants
+	Name string
+	// Count is how often Classes and Variants are used
+	Count int
+	// SavedBytes estimates the bytes saved per rendering of every use by
+	// serving Name instead of the class strings
+	SavedBytes int
+}
+
+// Suggest ranks class strings worth registering, from the number of uses of
+// each class string, e.g. as counted by CountClassUsage.
+//
+// Class strings merging to the same value, as reported by Lint, are grouped
+// into one suggestion sharing a name. Suggestions are ordered by SavedBytes;
+// class strings already registered or saving nothing are left out. Like
+// Canonical, Suggest does not record the class strings in the class map.
+func Suggest(usage map[string]int) []Suggestion {
+	mapMutex.RLock()
+	taken := make(map[string]bool, len(GenClassMergeStr))
+	for className := range GenClassMergeStr {
+		taken[className] = true
+	}
+	registered := make(map[string]bool, len(ClassMapStr))
+	for original := range ClassMapStr {
+		registered[original] = true
+	}
+	mapMutex.RUnlock()
+
+	merge := *quietMerge.Load()
+
+	groups := make(map[string][]string)
+	for classes := range usage {
+		key := normalizeMerged(merge(classes))
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], classes)
+	}
+
+	var suggestions []Suggestion
+	for _, key := range sortedGroupKeys(groups) {
+		variants := groups[key]
+		sort.Slice(variants, func(i, j int) bool {
+			if usage[variants[i]] != usage[variants[j]] {
+				return usage[variants[i]] > usage[variants[j]]
+			}
+			return variants[i] < variants[j]
+		})
+		if registered[variants[0]] {
+			continue
+		}
+
+		s := Suggestion{
+			Classes:  variants[0],
+			Variants: variants[1:],
+			Merged:   merge(variants[0]),
+			Name:     suggestName(key, taken),
+		}
+		for _, classes := range variants {
+			s.Count += usage[classes]
+			s.SavedBytes += usage[classes] * (len(classes) - len(s.Name))
+		}
+		if s.SavedBytes <= 0 {
+			continue
+		}
+		taken[s.Name] = true
+		suggestions = append(suggestions, s)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].SavedBytes > suggestions[j].SavedBytes
+	})
+	return suggestions
+}
+
+// suggestName derives a readable class name from the first of the sorted
+// classes that is not taken yet.
+func suggestName(classes string, taken map[string]bool) string {
+	fields := strings.Fields(classes)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, strings.Join(fields, "-"))
+	base = strings.Trim(base, "-")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "c-" + base
+	}
+
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	return name
+}
+
+// sortedGroupKeys returns the keys of groups in ascending order.
+func sortedGroupKeys(groups map[string][]string) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
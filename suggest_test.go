@@ -0,0 +1,34 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	SetMapping(map[string]string{"m-2 m-4": "tw-margin", "flex items-center": "flex-items-center"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	suggestions := Suggest(map[string]int{
+		"items-center flex p-2 p-4":       1,
+		"flex items-center p-4":           3,
+		"text-sm font-bold text-gray-700": 2,
+		"m-2 m-4":                         9,
+		"p-2":                             5,
+	})
+	assert.Len(t, suggestions, 2)
+
+	assert.Equal(t, "text-sm font-bold text-gray-700", suggestions[0].Classes)
+	assert.Equal(t, "font-bold-text-gray-700", suggestions[0].Name)
+	assert.Equal(t, 16, suggestions[0].SavedBytes)
+
+	assert.Equal(t, "flex items-center p-4", suggestions[1].Classes)
+	assert.Equal(t, []string{"items-center flex p-2 p-4"}, suggestions[1].Variants)
+	assert.Equal(t, "flex-items-center-2", suggestions[1].Name)
+	assert.Equal(t, 4, suggestions[1].Count)
+	assert.Equal(t, 3*(21-19)+(25-19), suggestions[1].SavedBytes)
+
+	// the suggested class strings are not recorded
+	assert.Equal(t, map[string]string{"m-2 m-4": "tw-margin", "flex items-center": "flex-items-center"}, TakeSnapshot().ClassMap)
+}
@@ -0,0 +1,223 @@
+package twerge
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Snapshot is a consistent copy of the registered classes.
+type Snapshot struct {
+	// ClassMap maps original class strings to generated class names
+	ClassMap map[string]string
+	// Rules maps generated class names to their merged classes
+	Rules map[string]string
+	// RawCSS maps generated class names to their raw CSS, see RegisterRawCSS
+	RawCSS map[string]string
+	// Components maps components to the class names of their parts, see
+	// RegisterComponent
+	Components map[string]map[string]string
+}
+
+// takeSnapshot copies the class maps, merging original class strings that
+// have no generated rule yet.
+func takeSnapshot() Snapshot {
+	mapMutex.RLock()
+	snap := Snapshot{
+		ClassMap: maps.Clone(ClassMapStr),
+		Rules:    maps.Clone(GenClassMergeStr),
+		RawCSS:   maps.Clone(rawCSS),
+	}
+	if len(componentParts) > 0 {
+		snap.Components = make(map[string]map[string]string, len(componentParts))
+		for component, parts := range componentParts {
+			snap.Components[component] = maps.Clone(parts)
+		}
+	}
+	mapMutex.RUnlock()
+
+	for original, className := range snap.ClassMap {
+		if _, ok := snap.Rules[className]; !ok {
+			snap.Rules[className] = Merge(original)
+		}
+	}
+	return snap
+}
+
+// WriteCSS writes the @apply rules of the snapshot to w, ordered by class name
+// unless configured otherwise, like WriteGeneratedCSS.
+func (s Snapshot) WriteCSS(w io.Writer, opts ...MapOption) error {
+	o := newMapOptions(opts)
+	return o.writeRulesWithRaw(w, s.Rules, s.RawCSS)
+}
+
+// Target is a file generated from a snapshot of the registered classes.
+//
+// Every target passed to GenerateTailwind is rendered from the same snapshot
+// before any file is written, so the artifacts never disagree with each other.
+type Target struct {
+	// Path is the file the target is written to
+	Path string
+	// Render returns the content of the file
+	Render func(snap Snapshot) ([]byte, error)
+	// Write, if not nil, is used instead of Render to stream the content of
+	// large files to w
+	Write func(w io.Writer, snap Snapshot) error
+}
+
+// SafelistTarget writes every Tailwind class used by the generated rules to
+// path, one per line, for the content or safelist setting of Tailwind.
+func SafelistTarget(path string) Target {
+	return Target{
+		Path: path,
+		Render: func(snap Snapshot) ([]byte, error) {
+			used := make(map[string]string)
+			for _, merged := range snap.Rules {
+				for _, class := range strings.Fields(merged) {
+					used[class] = class
+				}
+			}
+			var buf bytes.Buffer
+			for _, class := range SortedKeys(used) {
+				buf.WriteString(class)
+				buf.WriteByte('\n')
+			}
+			return buf.Bytes(), nil
+		},
+	}
+}
+
+// TemplTarget writes a .templ file using every generated class name to path,
+// like GenerateTempl.
+func TemplTarget(path string) Target {
+	return Target{
+		Path: path,
+		Render: func(snap Snapshot) ([]byte, error) {
+			return templShim(templPackageName(path), snap), nil
+		},
+	}
+}
+
+// ManifestTarget writes the snapshot as JSON to path, with the "classes" and
+// "rules" fields holding Snapshot.ClassMap and Snapshot.Rules.
+func ManifestTarget(path string) Target {
+	return Target{
+		Path: path,
+		Render: func(snap Snapshot) ([]byte, error) {
+			content, err := json.MarshalIndent(struct {
+				Classes map[string]string `json:"classes"`
+				Rules   map[string]string `json:"rules"`
+			}{snap.ClassMap, snap.Rules}, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("error encoding manifest: %w", err)
+			}
+			return append(content, '\n'), nil
+		},
+	}
+}
+
+// templShim returns a .templ file referencing every generated class name of
+// the snapshot, so Tailwind finds them when scanning templates.
+func templShim(pkgName string, snap Snapshot) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by twerge. DO NOT EDIT.\n\n")
+	buf.WriteString("package ")
+	buf.WriteString(pkgName)
+	buf.WriteString("\n\n")
+	buf.WriteString("templ empty() {\n")
+	buf.WriteString("<div class=\"")
+	buf.WriteString("mb-4")
+	buf.WriteString("\"></div>\n")
+	for _, k := range SortedKeys(snap.Rules) {
+		buf.WriteString("<div class=\"")
+		buf.WriteString(k)
+		buf.WriteString("\"></div>\n")
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// writeTargets renders every target from snap and writes them.
+//
+// Nothing is written if a target fails to render. Files are written to
+// temporary files first and renamed into place once all were written.
+func writeTargets(snap Snapshot, targets []Target) error {
+	byPath := make(map[string]Target, len(targets))
+	for _, target := range targets {
+		byPath[target.Path] = target
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	temps := make(map[string]string, len(paths))
+	cleanup := func() {
+		for _, tmp := range temps {
+			_ = os.Remove(tmp)
+		}
+	}
+	for _, path := range paths {
+		f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("error writing output file: %w", err)
+		}
+		temps[path] = f.Name()
+		err = writeTarget(f, byPath[path], snap)
+		if err == nil {
+			err = f.Chmod(0644)
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return err
+		}
+	}
+
+	for _, path := range paths {
+		err := os.Rename(temps[path], path)
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("error writing output file: %w", err)
+		}
+		delete(temps, path)
+	}
+	return nil
+}
+
+// writeTarget renders the target from snap into f.
+func writeTarget(f *os.File, target Target, snap Snapshot) error {
+	if target.Write == nil {
+		content, err := target.Render(snap)
+		if err != nil {
+			return fmt.Errorf("error rendering %s: %w", target.Path, err)
+		}
+		_, err = f.Write(content)
+		if err != nil {
+			return fmt.Errorf("error writing output file: %w", err)
+		}
+		return nil
+	}
+
+	w := bufio.NewWriter(f)
+	err := target.Write(w, snap)
+	if err != nil {
+		return fmt.Errorf("error rendering %s: %w", target.Path, err)
+	}
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
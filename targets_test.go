@@ -0,0 +1,100 @@
+package twerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTailwindTargets(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad", "flex items-center": "tw-flex"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	dir := filepath.Join(t.TempDir(), "views")
+	assert.NoError(t, os.Mkdir(dir, 0755))
+	cssPath := filepath.Join(dir, "input.css")
+	safelist := filepath.Join(dir, "safelist.txt")
+	templPath := filepath.Join(dir, "classes.templ")
+	manifest := filepath.Join(dir, "twerge.json")
+
+	err := GenerateTailwind(cssPath, SafelistTarget(safelist), TemplTarget(templPath), ManifestTarget(manifest))
+	assert.NoError(t, err)
+
+	css, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(css), ".tw-flex { \n\t@apply flex items-center; \n}\n.tw-pad {")
+
+	content, err := os.ReadFile(safelist)
+	assert.NoError(t, err)
+	assert.Equal(t, "flex\nitems-center\np-4\n", string(content))
+
+	content, err = os.ReadFile(templPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "package views\n")
+	assert.Contains(t, string(content), `<div class="tw-flex"></div>`)
+	assert.Contains(t, string(content), `<div class="tw-pad"></div>`)
+
+	content, err = os.ReadFile(manifest)
+	assert.NoError(t, err)
+	var snap struct {
+		Classes map[string]string `json:"classes"`
+		Rules   map[string]string `json:"rules"`
+	}
+	assert.NoError(t, json.Unmarshal(content, &snap))
+	assert.Equal(t, "tw-pad", snap.Classes["p-2 p-4"])
+	assert.Equal(t, "flex items-center", snap.Rules["tw-flex"])
+
+	// a failing target leaves every file untouched
+	assert.NoError(t, os.Remove(manifest))
+	err = GenerateTailwind(cssPath, ManifestTarget(manifest), Target{
+		Path:   filepath.Join(dir, "broken"),
+		Render: func(Snapshot) ([]byte, error) { return nil, errors.New("broken") },
+	})
+	assert.Error(t, err)
+	assert.NoFileExists(t, manifest)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestStreamingTarget(t *testing.T) {
+	snap := Snapshot{
+		Rules:  map[string]string{"tw-b": "p-4", "tw-a": "flex"},
+		RawCSS: map[string]string{"tw-b": ".tw-b:hover { color: red; }"},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, snap.WriteCSS(&buf, WithPrefix("x-")))
+	assert.Equal(t,
+		".x-tw-a { \n\t@apply flex; \n}\n.x-tw-b { \n\t@apply p-4; \n}\n.tw-b:hover { color: red; }\n",
+		buf.String(),
+	)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "styles.css")
+	err := writeTargets(snap, []Target{{Path: path, Write: func(w io.Writer, snap Snapshot) error {
+		return snap.WriteCSS(w)
+	}}})
+	assert.NoError(t, err)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ".tw-a { \n\t@apply flex; \n}\n.tw-b { \n\t@apply p-4; \n}\n.tw-b:hover { color: red; }\n", string(content))
+
+	// a target failing while streaming leaves every file untouched
+	err = writeTargets(snap, []Target{{Path: path, Write: func(w io.Writer, _ Snapshot) error {
+		_, _ = io.WriteString(w, "partial")
+		return errors.New("broken")
+	}}})
+	assert.ErrorContains(t, err, "broken")
+	after, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, content, after)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
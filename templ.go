@@ -0,0 +1,70 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Class returns the generated class name of classes, like It, as a templ CSS
+// class carrying its rule.
+//
+// templ renders the rule of each class once per response in a <style>
+// element before the element using it, so no stylesheet has to be pasted into
+// the page:
+//
+//	<button class={ twerge.Class("px-4 py-2 bg-blue-500") }>Save</button>
+//
+// The browser reads the <style> element as is, so unlike StyleTag the rules
+// hold plain CSS declarations, resolved like WithStandaloneCSS, instead of
+// @apply.
+func Class(classes string) templ.CSSClass {
+	className := It(classes)
+
+	mapMutex.RLock()
+	merged, ok := GenClassMergeStr[className]
+	mapMutex.RUnlock()
+	if !ok {
+		merged = Merge(classes)
+	}
+
+	var builder strings.Builder
+	_ = writeStandaloneRule(&builder, className, merged, registeredRawCSS()[className])
+	return templ.ComponentCSSClass{
+		ID:    className,
+		Class: templ.SafeCSS(escapeStyle(builder.String())),
+	}
+}
+
+// CSSComponent returns a templ component rendering the <style> element of the
+// rule of classes, see Class.
+//
+// Rules already rendered in the same response are skipped, so the component
+// can be used next to every element using the classes:
+//
+//	@twerge.CSSComponent("px-4 py-2 bg-blue-500")
+//	<button class={ twerge.It("px-4 py-2 bg-blue-500") }>Save</button>
+func CSSComponent(classes string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		return templ.RenderCSSItems(ctx, w, Class(classes))
+	})
+}
+
+// WithClass is like With, returning the templ CSS class of Class carrying the
+// rule of the merged classes:
+//
+//	var card = twerge.WithClass("rounded-lg border p-4")
+//
+//	templ Card(class string) {
+//		<div class={ card(class) }>{ children... }</div>
+//	}
+func WithClass(base string) func(extra string) templ.CSSClass {
+	c := &composer{base: strings.Join(strings.Fields(base), " ")}
+	return func(extra string) templ.CSSClass {
+		return Class(c.classes(extra))
+	}
+}
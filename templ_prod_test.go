@@ -0,0 +1,38 @@
+//go:build twerge_prod && !tinygo
+
+package twerge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotReloadScriptProduction(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, HotReloadScript("/twerge/hot-reload").Render(context.Background(), &b))
+	assert.Empty(t, b.String())
+}
+
+func TestAssetTagProduction(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	assert.NoError(t, os.WriteFile(manifestPath, []byte(`{"src/input.css": {"file": "assets/input-4f2a.css"}}`), 0644))
+	t.Cleanup(ReloadAssetTags)
+
+	render := func() string {
+		var tag strings.Builder
+		assert.NoError(t, AssetTag(manifestPath).Render(context.Background(), &tag))
+		return tag.String()
+	}
+	assert.Equal(t, `<link rel="stylesheet" href="/assets/input-4f2a.css">`, render())
+
+	// the manifest is read again only after ReloadAssetTags
+	assert.NoError(t, os.WriteFile(manifestPath, []byte(`{"src/input.css": {"file": "assets/input-9e3b.css"}}`), 0644))
+	assert.Equal(t, `<link rel="stylesheet" href="/assets/input-4f2a.css">`, render())
+	ReloadAssetTags()
+	assert.Equal(t, `<link rel="stylesheet" href="/assets/input-9e3b.css">`, render())
+}
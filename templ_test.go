@@ -0,0 +1,41 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClass(t *testing.T) {
+	RegisterClasses(map[string]string{"px-2 px-4": "tw-button"})
+
+	class := Class("px-2 px-4")
+	assert.Equal(t, "tw-button", class.ClassName())
+	assert.Equal(t, templ.ComponentCSSClass{
+		ID:    "tw-button",
+		Class: templ.SafeCSS(".tw-button { \n\tpadding-left: 1rem; \n\tpadding-right: 1rem; \n}\n"),
+	}, class)
+}
+
+func TestCSSComponent(t *testing.T) {
+	RegisterClasses(map[string]string{"m-1 m-2": "tw-margin"})
+
+	ctx := templ.InitializeContext(context.Background())
+	var out strings.Builder
+	assert.NoError(t, CSSComponent("m-1 m-2").Render(ctx, &out))
+	// rules are rendered once per response
+	assert.NoError(t, CSSComponent("m-1 m-2").Render(ctx, &out))
+	assert.Equal(t, `<style type="text/css">.tw-margin { `+"\n\tmargin: 0.5rem; \n}\n"+`</style>`, out.String())
+}
+
+func TestWithClass(t *testing.T) {
+	RegisterClasses(map[string]string{"px-2 px-4": "tw-composed"})
+
+	class := WithClass("px-2")("px-4")
+	assert.Equal(t, "tw-composed", class.ClassName())
+}
@@ -0,0 +1,66 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"html/template"
+	"strings"
+)
+
+// FuncMap returns template functions for html/template users.
+//
+//   - twMerge merges classes like Merge
+//   - twIt returns a generated class name like It
+//   - twIf returns a generated class name like If
+//   - twStyleTag returns a <style> element holding the generated stylesheet
+//   - twCriticalCSS returns a <style> element holding the critical rules
+//   - twAsset returns the bundled stylesheet path like AssetPath
+//
+// Example:
+//
+//	tmpl := template.New("page").Funcs(twerge.FuncMap())
+//
+//	<head>{{ twStyleTag }}</head>
+//	<div class="{{ twIt "flex items-center p-4" }}"></div>
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"twMerge":       Merge,
+		"twIt":          It,
+		"twIf":          If,
+		"twStyleTag":    StyleTag,
+		"twCriticalCSS": CriticalCSS,
+		"twAsset":       AssetPath,
+	}
+}
+
+// StyleTag returns a <style> element holding the @apply rules of every
+// generated class, see WriteGeneratedCSS. WithMinify shrinks the inlined
+// stylesheet.
+//
+// Any "<" in the stylesheet is written as a CSS escape so class strings can
+// not close the element early.
+func StyleTag(opts ...MapOption) template.HTML {
+	var builder strings.Builder
+	_ = WriteGeneratedCSS(&builder, opts...)
+	return template.HTML("<style>" + escapeStyle(builder.String()) + "</style>")
+}
+
+// escapeStyle writes any "<" in css as a CSS escape so it can not close a
+// <style> element early.
+func escapeStyle(css string) string {
+	return strings.ReplaceAll(css, "<", `\3c `)
+}
+
+// CriticalCSS returns a <style> element holding the rules of the classes
+// marked as critical, see WriteCriticalCSS, to inline in the head of pages
+// while DeferredCSS serves the other rules:
+//
+//	<head>
+//		@templ.Raw(string(twerge.CriticalCSS(twerge.WithMinify())))
+//		<link rel="stylesheet" href="/deferred.css"/>
+//	</head>
+func CriticalCSS(opts ...MapOption) template.HTML {
+	var builder strings.Builder
+	_ = WriteCriticalCSS(&builder, opts...)
+	return template.HTML("<style>" + escapeStyle(builder.String()) + "</style>")
+}
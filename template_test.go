@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package twerge
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncMap(t *testing.T) {
+	SetMapping(map[string]string{"p-2 p-4": "tw-pad"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	tmpl, err := template.New("page").Funcs(FuncMap()).Parse(
+		`{{ twStyleTag }}<div class="{{ twIt "p-2 p-4" }}"><p class="{{ twMerge "m-1 m-2" }}"></p></div>`,
+	)
+	assert.NoError(t, err)
+
+	var out strings.Builder
+	assert.NoError(t, tmpl.Execute(&out, nil))
+	assert.Equal(t,
+		"<style>.tw-pad { \n\t@apply p-4; \n}\n</style>"+`<div class="tw-pad"><p class="m-2"></p></div>`,
+		out.String(),
+	)
+}
+
+func TestStyleTagEscapes(t *testing.T) {
+	SetMapping(map[string]string{"content-['</style><script>']": "tw-evil"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	tag := string(StyleTag())
+	assert.Equal(t, 1, strings.Count(tag, "</style>"))
+	assert.True(t, strings.HasSuffix(tag, "</style>"))
+	assert.NotContains(t, tag, "<script>")
+}
+
+func TestCriticalCSS(t *testing.T) {
+	SetMapping(nil)
+	t.Cleanup(func() { SetMapping(nil) })
+	mapMutex.Lock()
+	criticalClasses = make(map[string]bool)
+	mapMutex.Unlock()
+
+	RegisterClasses(map[string]string{"flex items-center": "tw-header", "p-2 p-4": "tw-footer"})
+	MarkCritical("flex items-center")
+
+	assert.Contains(t, string(CriticalCSS()), ".tw-header { \n\t@apply flex items-center; \n}\n")
+	assert.NotContains(t, string(CriticalCSS()), "tw-footer")
+
+	rec := httptest.NewRecorder()
+	DeferredCSS().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deferred.css", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ".tw-footer { \n\t@apply p-4; \n}\n", rec.Body.String())
+}
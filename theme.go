@@ -0,0 +1,474 @@
+package twerge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Theme holds the values of a Tailwind theme that extend the default class
+// groups, read from a tailwind.config.js by LoadTailwindTheme.
+type Theme struct {
+	// Colors are the names of the theme colors, with their shades, like
+	// "brand" and "brand-500"
+	Colors []string
+	// Spacing are the keys of the spacing scale, like "18" or "header"
+	Spacing []string
+	// Screens are the names of the breakpoints, like "3xl"
+	Screens []string
+	// MaxWidth are the keys of the maxWidth scale, like "8xl" or "content"
+	MaxWidth []string
+	// FontSize are the keys of the fontSize scale, like "huge"
+	FontSize []string
+	// BorderRadius are the keys of the borderRadius scale, like "card"
+	BorderRadius []string
+	// BoxShadow are the keys of the boxShadow scale, like "card"
+	BoxShadow []string
+	// DropShadow are the keys of the dropShadow scale
+	DropShadow []string
+	// Blur are the keys of the blur scale
+	Blur []string
+	// BackdropBlur are the keys of the backdropBlur scale
+	BackdropBlur []string
+	// Columns are the keys of the columns scale
+	Columns []string
+}
+
+// themeColorGroups maps the class groups of colors to their class prefixes
+var themeColorGroups = map[string]string{
+	"bg-color":              "bg",
+	"text-color":            "text",
+	"border-color":          "border",
+	"border-color-x":        "border-x",
+	"border-color-y":        "border-y",
+	"border-color-t":        "border-t",
+	"border-color-r":        "border-r",
+	"border-color-b":        "border-b",
+	"border-color-l":        "border-l",
+	"divide-color":          "divide",
+	"outline-color":         "outline",
+	"ring-color":            "ring",
+	"ring-offset-color":     "ring-offset",
+	"shadow-color":          "shadow",
+	"placeholder-color":     "placeholder",
+	"caret-color":           "caret",
+	"accent":                "accent",
+	"fill":                  "fill",
+	"stroke":                "stroke",
+	"text-decoration-color": "decoration",
+	"gradient-from":         "from",
+	"gradient-via":          "via",
+	"gradient-to":           "to",
+}
+
+// themeSpacingGroups are the class groups using the spacing scale, which are
+// also their class prefixes
+var themeSpacingGroups = []string{
+	"p", "px", "py", "pt", "pr", "pb", "pl", "ps", "pe",
+	"m", "mx", "my", "mt", "mr", "mb", "ml", "ms", "me",
+	"gap", "gap-x", "gap-y", "space-x", "space-y",
+	"inset", "inset-x", "inset-y", "top", "right", "bottom", "left", "start", "end",
+	"w", "h", "min-w", "min-h", "max-h", "size", "basis", "indent",
+	"scroll-p", "scroll-px", "scroll-py", "scroll-pt", "scroll-pr", "scroll-pb", "scroll-pl", "scroll-ps", "scroll-pe",
+	"scroll-m", "scroll-mx", "scroll-my", "scroll-mt", "scroll-mr", "scroll-mb", "scroll-ml", "scroll-ms", "scroll-me",
+	"translate-x", "translate-y", "border-spacing", "border-spacing-x", "border-spacing-y",
+}
+
+// themeScales are the sized scales of a theme, with the class groups using
+// them mapped to their class prefixes
+var themeScales = []struct {
+	key    string
+	keys   func(t *Theme) *[]string
+	groups map[string]string
+}{
+	{"maxWidth", func(t *Theme) *[]string { return &t.MaxWidth }, map[string]string{"max-w": "max-w"}},
+	{"fontSize", func(t *Theme) *[]string { return &t.FontSize }, map[string]string{"font-size": "text"}},
+	{"borderRadius", func(t *Theme) *[]string { return &t.BorderRadius }, map[string]string{
+		"rounded": "rounded", "rounded-s": "rounded-s", "rounded-e": "rounded-e",
+		"rounded-t": "rounded-t", "rounded-r": "rounded-r", "rounded-b": "rounded-b", "rounded-l": "rounded-l",
+		"rounded-ss": "rounded-ss", "rounded-se": "rounded-se", "rounded-ee": "rounded-ee", "rounded-es": "rounded-es",
+		"rounded-tl": "rounded-tl", "rounded-tr": "rounded-tr", "rounded-br": "rounded-br", "rounded-bl": "rounded-bl",
+	}},
+	{"boxShadow", func(t *Theme) *[]string { return &t.BoxShadow }, map[string]string{"shadow": "shadow"}},
+	{"dropShadow", func(t *Theme) *[]string { return &t.DropShadow }, map[string]string{"drop-shadow": "drop-shadow"}},
+	{"blur", func(t *Theme) *[]string { return &t.Blur }, map[string]string{"blur": "blur"}},
+	{"backdropBlur", func(t *Theme) *[]string { return &t.BackdropBlur }, map[string]string{"backdrop-blur": "backdrop-blur"}},
+	{"columns", func(t *Theme) *[]string { return &t.Columns }, map[string]string{"columns": "columns"}},
+}
+
+// WithTheme adds the classes of the custom colors, spacing, screens and sized
+// scales, like maxWidth or borderRadius, of t to the class groups of
+// Tailwind, like Extend, and returns c.
+//
+// Merge then resolves classes like p-header or max-w-content, which it would
+// otherwise pass through, and Validate accepts theme colors like
+// bg-brand-500.
+//
+//	theme, err := twerge.LoadTailwindTheme("tailwind.config.js")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	twerge.SetConfig(twerge.DefaultConfig().WithTheme(theme))
+func (c *Config) WithTheme(t *Theme) *Config {
+	for group, prefix := range themeColorGroups {
+		for _, color := range t.Colors {
+			c.Extend(group, prefix+"-"+color)
+		}
+	}
+	for _, group := range themeSpacingGroups {
+		for _, key := range t.Spacing {
+			c.Extend(group, group+"-"+key)
+		}
+	}
+	for _, screen := range t.Screens {
+		c.Extend("max-w", "max-w-screen-"+screen)
+	}
+	for _, scale := range themeScales {
+		for group, prefix := range scale.groups {
+			for _, key := range *scale.keys(t) {
+				c.Extend(group, prefix+"-"+key)
+			}
+		}
+	}
+	return c
+}
+
+// themeRegex matches the start of the theme of a Tailwind configuration
+var themeRegex = regexp.MustCompile(`\btheme\s*:\s*\{`)
+
+// LoadTailwindTheme reads the theme of the Tailwind configuration at path.
+//
+// A .json file is either the configuration, e.g. exported with
+// JSON.stringify(resolveConfig(config)), or the theme alone. Other files,
+// like tailwind.config.js or .ts, are not run: the object literal of their
+// theme is read, so values computed by code are not known. Colors whose
+// value is computed, like colors.sky, get the default shades.
+func LoadTailwindTheme(path string) (*Theme, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tailwind config: %w", err)
+	}
+	var theme any
+	if filepath.Ext(path) == ".json" {
+		var config map[string]any
+		if err := json.Unmarshal(src, &config); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		theme = config
+		if t, ok := config["theme"]; ok {
+			theme = t
+		}
+	} else {
+		theme, err = ParseTailwindTheme(src)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+	}
+	return newTheme(theme), nil
+}
+
+// ParseTailwindTheme returns the theme object of the JavaScript or
+// TypeScript source of a Tailwind configuration, with objects as
+// map[string]any, arrays as []any and strings and numbers as string. Values
+// computed by code, like function calls or imported values, are nil.
+//
+// It returns an empty theme if the source has none.
+func ParseTailwindTheme(src []byte) (map[string]any, error) {
+	loc := themeRegex.FindIndex(src)
+	if loc == nil {
+		return map[string]any{}, nil
+	}
+	p := &jsParser{src: src, pos: loc[1] - 1}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	theme, _ := value.(map[string]any)
+	return theme, nil
+}
+
+// newTheme returns the Theme of a theme object, reading the values of both
+// the theme and its extend section.
+func newTheme(theme any) *Theme {
+	t := &Theme{}
+	sections := []any{theme}
+	if m, ok := theme.(map[string]any); ok {
+		sections = append(sections, m["extend"])
+	}
+	for _, section := range sections {
+		m, _ := section.(map[string]any)
+		t.Colors = appendColors(t.Colors, "", m["colors"])
+		t.Spacing = appendKeys(t.Spacing, m["spacing"])
+		t.Screens = appendKeys(t.Screens, m["screens"])
+		for _, scale := range themeScales {
+			*scale.keys(t) = appendKeys(*scale.keys(t), m[scale.key])
+		}
+	}
+	all := []*[]string{&t.Colors, &t.Spacing, &t.Screens}
+	for _, scale := range themeScales {
+		all = append(all, scale.keys(t))
+	}
+	for _, values := range all {
+		slices.Sort(*values)
+		*values = slices.Compact(*values)
+	}
+	return t
+}
+
+// appendColors appends the color names of value, prefixed with name, to
+// colors. Nested objects are shades, with DEFAULT standing for the color
+// itself.
+func appendColors(colors []string, name string, value any) []string {
+	join := func(key string) string {
+		if name == "" {
+			return key
+		}
+		if key == "DEFAULT" {
+			return name
+		}
+		return name + "-" + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, shade := range v {
+			colors = appendColors(colors, join(key), shade)
+		}
+	case nil:
+		// computed colors, like colors.sky, get the default shades
+		if name != "" {
+			colors = append(colors, name)
+			for _, shade := range colorShades {
+				colors = append(colors, name+"-"+shade)
+			}
+		}
+	default:
+		if name != "" {
+			colors = append(colors, name)
+		}
+	}
+	return colors
+}
+
+// appendKeys appends the keys of the object value to keys.
+func appendKeys(keys []string, value any) []string {
+	m, _ := value.(map[string]any)
+	for key := range m {
+		if key != "DEFAULT" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// jsParser reads JavaScript object literals, skipping the expressions it
+// cannot evaluate
+type jsParser struct {
+	src []byte
+	pos int
+}
+
+// skipSpace skips whitespace and comments.
+func (p *jsParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])):
+			p.pos++
+		case p.peekString("//"):
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case p.peekString("/*"):
+			end := strings.Index(string(p.src[p.pos+2:]), "*/")
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *jsParser) peekString(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:]), s)
+}
+
+// value reads the value at the current position.
+func (p *jsParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unexpected end of file")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || c == '\'' || c == '`':
+		return p.string()
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.src) && strings.ContainsRune("-+.eExXabcdefABCDEF0123456789_", rune(p.src[p.pos])) {
+			p.pos++
+		}
+		return string(p.src[start:p.pos]), nil
+	default:
+		return nil, p.skipExpression()
+	}
+}
+
+// object reads an object literal, skipping spread and computed properties.
+func (p *jsParser) object() (map[string]any, error) {
+	p.pos++
+	m := make(map[string]any)
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated object")
+		}
+		switch {
+		case p.src[p.pos] == '}':
+			p.pos++
+			return m, nil
+		case p.src[p.pos] == ',':
+			p.pos++
+			continue
+		case p.peekString("..."), p.src[p.pos] == '[':
+			if err := p.skipExpression(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var key string
+		if c := p.src[p.pos]; c == '"' || c == '\'' || c == '`' {
+			s, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		} else {
+			start := p.pos
+			// numeric keys like 1.5 are not identifiers
+			for p.pos < len(p.src) && (isJSIdentifierByte(p.src[p.pos]) || p.src[p.pos] == '.') {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos], p.pos)
+			}
+			key = string(p.src[start:p.pos])
+		}
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '(' {
+			// a method, like extend(theme) { ... }
+			if err := p.skipExpression(); err != nil {
+				return nil, err
+			}
+			m[key] = nil
+			continue
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+			// a shorthand property
+			m[key] = nil
+			continue
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+}
+
+// array reads an array literal.
+func (p *jsParser) array() ([]any, error) {
+	p.pos++
+	var values []any
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		switch p.src[p.pos] {
+		case ']':
+			p.pos++
+			return values, nil
+		case ',':
+			p.pos++
+			continue
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// string reads a quoted string, keeping template literal placeholders as
+// written.
+func (p *jsParser) string() (string, error) {
+	quote := p.src[p.pos]
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			b.WriteByte(p.src[p.pos])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// skipExpression skips an expression up to the comma or closing bracket
+// ending it.
+func (p *jsParser) skipExpression() error {
+	depth := 0
+	for p.pos < len(p.src) {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		switch c := p.src[p.pos]; c {
+		case '"', '\'', '`':
+			if _, err := p.string(); err != nil {
+				return err
+			}
+			continue
+		case '{', '[', '(':
+			depth++
+		case '}', ']', ')':
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return nil
+			}
+		}
+		p.pos++
+	}
+	if depth > 0 {
+		return fmt.Errorf("unterminated expression")
+	}
+	return nil
+}
+
+// isJSIdentifierByte reports whether c can be part of a JavaScript
+// identifier, ignoring non-ASCII letters.
+func isJSIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
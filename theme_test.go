@@ -0,0 +1,114 @@
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tailwindConfigJS = `import colors from "tailwindcss/colors"
+import type { Config } from "tailwindcss"
+
+/** @type {import('tailwindcss').Config} */
+export default {
+  content: ["./**/*.templ"],
+  theme: {
+    screens: {
+      tablet: "640px",
+      // a comment with a } brace
+      '3xl': '1920px',
+    },
+    extend: {
+      colors: {
+        ...colors,
+        brand: {
+          DEFAULT: "#0a84ff",
+          500: "#0a84ff",
+          'dark': '#003366',
+        },
+        sky: colors.sky,
+        accent: ` + "`#ff0`" + `,
+      },
+      spacing: {
+        header: "4.5rem",
+        1.5: "0.375rem",
+      },
+      maxWidth: {
+        '8xl': "88rem",
+        content: "72ch",
+      },
+      borderRadius: {
+        card: "1.25rem",
+      },
+      fontFamily: {
+        sans: ["Inter", ...defaultTheme.fontFamily.sans],
+      },
+    },
+  },
+  plugins: [require("@tailwindcss/forms")],
+} satisfies Config
+`
+
+func TestLoadTailwindTheme(t *testing.T) {
+	dir := t.TempDir()
+	jsPath := filepath.Join(dir, "tailwind.config.ts")
+	assert.NoError(t, os.WriteFile(jsPath, []byte(tailwindConfigJS), 0644))
+
+	theme, err := LoadTailwindTheme(jsPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.5", "header"}, theme.Spacing)
+	assert.Equal(t, []string{"3xl", "tablet"}, theme.Screens)
+	assert.Equal(t, []string{"8xl", "content"}, theme.MaxWidth)
+	assert.Equal(t, []string{"card"}, theme.BorderRadius)
+	assert.Empty(t, theme.FontSize)
+	assert.Contains(t, theme.Colors, "brand")
+	assert.Contains(t, theme.Colors, "brand-500")
+	assert.Contains(t, theme.Colors, "brand-dark")
+	assert.Contains(t, theme.Colors, "accent")
+	// computed colors get the default shades
+	assert.Contains(t, theme.Colors, "sky-950")
+
+	jsonPath := filepath.Join(dir, "tailwind.json")
+	err = os.WriteFile(jsonPath, []byte(`{"theme": {"colors": {"brand": {"500": "#0a84ff"}}, "spacing": {"header": "4.5rem"}}}`), 0644)
+	assert.NoError(t, err)
+	theme, err = LoadTailwindTheme(jsonPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &Theme{Colors: []string{"brand-500"}, Spacing: []string{"header"}}, theme)
+
+	_, err = LoadTailwindTheme(filepath.Join(dir, "missing.js"))
+	assert.Error(t, err)
+	_, err = ParseTailwindTheme([]byte(`theme: { colors: { brand: "#fff"`))
+	assert.Error(t, err)
+	empty, err := ParseTailwindTheme([]byte(`module.exports = {}`))
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestConfigWithTheme(t *testing.T) {
+	theme := &Theme{Colors: []string{"brand", "brand-500"}, Spacing: []string{"header"}, Screens: []string{"tablet"}}
+	merge := NewMerge(DefaultConfig().WithTheme(theme))
+	assert.Equal(t, "p-header", merge("p-2 p-header"))
+	assert.Equal(t, "-mt-header", merge("mt-2 -mt-header"))
+	assert.Equal(t, "max-w-screen-tablet", merge("max-w-sm max-w-screen-tablet"))
+	assert.Equal(t, "bg-brand-500", merge("bg-red-500 bg-brand-500"))
+	assert.Equal(t, "text-lg text-brand", merge("text-lg text-red-500 text-brand"))
+
+	theme.MaxWidth = []string{"8xl", "content"}
+	theme.FontSize = []string{"huge"}
+	theme.BorderRadius = []string{"card"}
+	theme.BoxShadow = []string{"card"}
+	merge = NewMerge(DefaultConfig().WithTheme(theme))
+	assert.Equal(t, "max-w-8xl", merge("max-w-7xl max-w-8xl"))
+	assert.Equal(t, "max-w-content", merge("max-w-md max-w-content"))
+	assert.Equal(t, "text-brand text-huge", merge("text-sm text-brand text-huge"))
+	assert.Equal(t, "rounded-t-card", merge("rounded-t-lg rounded-t-card"))
+	assert.Equal(t, "shadow-card", merge("shadow-md shadow-card"))
+
+	assert.NotEmpty(t, Validate("bg-brand-500 p-header"))
+	SetConfig(DefaultConfig().WithTheme(theme))
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+	assert.Empty(t, Validate("bg-brand-500 border-t-brand text-brand p-header max-w-screen-tablet"))
+	assert.NotEmpty(t, Validate("bg-brand-5000"))
+}
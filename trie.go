@@ -0,0 +1,135 @@
+package twerge
+
+import (
+	"strings"
+	"sync"
+)
+
+// classTrie is the class groups of a config flattened into a slice of nodes,
+// so that looking up a class walks its parts as substrings of the class
+// without splitting or joining it
+type classTrie struct {
+	nodes     []trieNode
+	separator byte
+}
+
+// trieNode is a classPart of a classTrie
+type trieNode struct {
+	// children maps the next part of a class to the index of its node
+	children map[string]int32
+	// validators match the remaining parts of a class
+	validators []classGroupValidator
+	// groupID is the class group of classes ending at the node
+	groupID string
+}
+
+// trieCache caches the trie of the config of Merge for the functions
+// inspecting classes outside of Merge, like Validate and ClassGroup
+var trieCache struct {
+	mu   sync.Mutex
+	conf *config
+	trie *classTrie
+}
+
+// currentTrie returns the config of Merge and its trie, compiled once per
+// config.
+func currentTrie() (*config, *classTrie) {
+	conf := currentConfig()
+	trieCache.mu.Lock()
+	defer trieCache.mu.Unlock()
+	if trieCache.conf != conf {
+		trieCache.conf, trieCache.trie = conf, compileTrie(conf)
+	}
+	return conf, trieCache.trie
+}
+
+// compileTrie flattens the class groups of conf into a classTrie.
+func compileTrie(conf *config) *classTrie {
+	t := &classTrie{separator: byte(conf.ClassSeparator)}
+	t.add(conf.ClassGroups)
+	return t
+}
+
+// add appends part and its next parts to the trie, returning its index.
+func (t *classTrie) add(part classPart) int32 {
+	index := int32(len(t.nodes))
+	t.nodes = append(t.nodes, trieNode{
+		validators: part.Validators,
+		groupID:    part.ClassGroupID,
+	})
+	if len(part.NextPart) == 0 {
+		return index
+	}
+	children := make(map[string]int32, len(part.NextPart))
+	for key, next := range part.NextPart {
+		children[key] = t.add(next)
+	}
+	t.nodes[index].children = children
+	return index
+}
+
+// find returns the class group of class.
+func (t *classTrie) find(class string) (string, bool) {
+	return t.findFrom(0, class, 0)
+}
+
+// findFrom returns the class group of the parts of class starting at pos
+// below the node at index. pos is -1 once every part was matched.
+//
+// Like the class group tree, more specific next parts take precedence over
+// the validators of a node.
+func (t *classTrie) findFrom(index int32, class string, pos int) (string, bool) {
+	n := &t.nodes[index]
+	if pos < 0 {
+		return n.groupID, n.groupID != ""
+	}
+
+	if n.children != nil {
+		part, next := class[pos:], -1
+		if end := strings.IndexByte(part, t.separator); end >= 0 {
+			part, next = part[:end], pos+end+1
+		}
+		if child, ok := n.children[part]; ok {
+			if groupID, ok := t.findFrom(child, class, next); ok {
+				return groupID, true
+			}
+		}
+	}
+
+	remaining := class[pos:]
+	for _, validator := range n.validators {
+		if validator.Fn(remaining) {
+			return validator.ClassGroupID, true
+		}
+	}
+	return "", false
+}
+
+// match is like findFrom, but also returns the validator matching the last
+// parts of class, if any, and the value it matched.
+func (t *classTrie) match(index int32, class string, pos int) (groupID string, validator *classGroupValidator, value string, ok bool) {
+	n := &t.nodes[index]
+	if pos < 0 {
+		return n.groupID, nil, "", n.groupID != ""
+	}
+
+	if n.children != nil {
+		part, next := class[pos:], -1
+		if end := strings.IndexByte(part, t.separator); end >= 0 {
+			part, next = part[:end], pos+end+1
+		}
+		if child, ok := n.children[part]; ok {
+			if groupID, validator, value, ok := t.match(child, class, next); ok {
+				return groupID, validator, value, true
+			}
+		}
+	}
+
+	remaining := class[pos:]
+	for i := range n.validators {
+		if n.validators[i].Fn(remaining) {
+			return n.validators[i].ClassGroupID, &n.validators[i], remaining, true
+		}
+	}
+	return "", nil, "", false
+}
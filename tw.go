@@ -3,9 +3,11 @@ package twerge
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"slices"
-	"sort"
 	"strings"
 )
 
@@ -16,8 +18,27 @@ const (
 	twergeBeginMarker = "/* twerge:begin */"
 	// twergeEndMarker is the end of the section where the generated CSS will be placed
 	twergeEndMarker = "/* twerge:end */"
+
+	// defaultTailwindCSS is the content of a new Tailwind v3 input CSS file
+	defaultTailwindCSS = `@tailwind base;
+@tailwind components;
+@tailwind utilities;
+
+` + twergeBeginMarker + `
+` + twergeEndMarker + `
+`
+
+	// defaultTailwindV4CSS is the content of a new Tailwind v4 input CSS file
+	defaultTailwindV4CSS = `@import "tailwindcss";
+
+` + twergeBeginMarker + `
+` + twergeEndMarker + `
+`
 )
 
+// ruleHeaderRegex matches the first line of a rule written by writeRule
+var ruleHeaderRegex = regexp.MustCompile(`^\.(\S+) \{ \n$`)
+
 // GenerateTailwind creates an input CSS file for the Tailwind CLI
 // that includes all the @apply directives from the provided class map.
 //
@@ -25,82 +46,182 @@ const (
 //
 // The marker is used to identify the start and end of the @apply directives generated
 // by Twerge.
+//
+// Additional targets, like a safelist or a manifest, are generated from the
+// same snapshot of the class map and written together with the input CSS, see
+// Target.
 func GenerateTailwind(
 	cssPath string,
+	targets ...Target,
 ) error {
-	// Read base CSS content if the file exists
-	var baseContent []byte
-	var err error
+	return generateTailwind(cssPath, takeSnapshot(), renderRules, targets)
+}
+
+// renderRules writes the rules of the snapshot sorted by class name.
+func renderRules(w io.Writer, _ []byte, snap Snapshot) error {
+	for _, name := range SortedKeys(snap.Rules) {
+		err := writeRawRule(w, name, snap.Rules[name], snap.RawCSS[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PatchTailwind updates the twerge section of the CSS file at cssPath like
+// GenerateTailwind, but keeps the existing rules in place.
+//
+// Rules of newly registered classes are appended to the section, rules of
+// classes that are no longer registered are removed and rules whose classes
+// changed are rewritten where they are. This keeps diffs of generated CSS
+// files under version control minimal.
+func PatchTailwind(
+	cssPath string,
+	targets ...Target,
+) error {
+	return generateTailwind(cssPath, takeSnapshot(), patchRules, targets)
+}
+
+// generateTailwind replaces the twerge section of the CSS file at cssPath
+// with the output of render, which is given the current section content and
+// the snapshot to write, and writes the targets from the same snapshot.
+func generateTailwind(
+	cssPath string,
+	snap Snapshot,
+	render func(w io.Writer, section []byte, snap Snapshot) error,
+	targets []Target,
+) error {
+	target, err := tailwindTarget(cssPath, render)
+	if err != nil {
+		return err
+	}
+	return writeTargets(snap, append([]Target{target}, targets...))
+}
 
-	baseContent, err = os.ReadFile(cssPath)
+// tailwindTarget returns the target of the CSS file at cssPath with its
+// twerge section replaced by the output of render, like generateTailwind.
+func tailwindTarget(
+	cssPath string,
+	render func(w io.Writer, section []byte, snap Snapshot) error,
+) (Target, error) {
+	// Read base CSS content if the file exists
+	baseContent, err := os.ReadFile(cssPath)
 	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("error reading input file: %w", err)
+		return Target{}, fmt.Errorf("error reading input file: %w", err)
 	}
 
 	// If file doesn't exist, create minimal Tailwind directives
 	if os.IsNotExist(err) {
-		baseContent = []byte(`@tailwind base;
-@tailwind components;
-@tailwind utilities;
+		baseContent = []byte(tailwindInputCSS())
+	}
 
-/* twerge:start */
-/* twerge:end */
-`)
+	section, _ := betweenMarkers(baseContent)
+	layout, err := sectionLayout(baseContent, []string{""})
+	if err != nil {
+		return Target{}, fmt.Errorf("error adding twerge content: %w", err)
 	}
 
-	// Generate Twerge CSS content
-	// Get all keys and sort them for consistent output
+	return Target{
+		Path: cssPath,
+		Write: func(w io.Writer, snap Snapshot) error {
+			return writeLayout(w, layout, func(w io.Writer, _ string) error {
+				return render(w, section, snap)
+			})
+		},
+	}, nil
+}
 
+// patchRules updates the rules of a twerge section in place, appending the
+// rules missing from it in sorted order.
+func patchRules(w io.Writer, section []byte, snap Snapshot) error {
 	var builder strings.Builder
-	var gendClasses []string
-	for generated, merged := range sortMap(GenClassMergeStr) {
-		gendClasses = append(gendClasses, generated)
-		// Create a CSS rule using the generated class name and the merged Tailwind classes
-		builder.WriteString(".")
-		builder.WriteString(generated)
-		builder.WriteString(" { \n\t@apply ")
-		builder.WriteString(merged)
-		builder.WriteString("; \n}\n")
-	}
-	for givenClasses, gendClass := range ClassMapStr {
-		if slices.Contains(gendClasses, gendClass) {
+	written := make(map[string]bool, len(snap.Rules))
+	for _, chunk := range splitRules(section) {
+		name, ok := ruleName(chunk)
+		if !ok {
+			// Keep anything that is not a rule, like comments
+			builder.WriteString(chunk)
+			continue
+		}
+		classes, registered := snap.Rules[name]
+		if !registered || written[name] {
 			continue
 		}
-		builder.WriteString(".")
-		builder.WriteString(gendClass)
-		builder.WriteString(" { \n\t@apply ")
-		builder.WriteString(Merge(givenClasses))
-		builder.WriteString("; \n}\n")
+		written[name] = true
+		var rule strings.Builder
+		_ = writeRawRule(&rule, name, classes, snap.RawCSS[name])
+		if strings.TrimSpace(chunk) == strings.TrimSpace(rule.String()) {
+			builder.WriteString(chunk)
+			continue
+		}
+		builder.WriteString(rule.String())
+	}
+	for _, name := range SortedKeys(snap.Rules) {
+		if !written[name] {
+			_ = writeRawRule(&builder, name, snap.Rules[name], snap.RawCSS[name])
+		}
 	}
-	cssContent := builder.String()
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
 
-	// Add to file content
-	newContent, err := replaceBetweenMarkers(baseContent, []byte(cssContent))
-	if err != nil {
-		return fmt.Errorf("error adding twerge content: %w", err)
+// splitRules splits a twerge section into chunks each starting at the first
+// line of a rule written by writeRule, so raw CSS stays with its rule. Text
+// before the first rule is its own chunk.
+func splitRules(section []byte) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(string(section), "\n") {
+		if ruleHeaderRegex.MatchString(line) && current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
 	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
 
-	// Write to output path
-	err = os.WriteFile(cssPath, newContent, 0644)
+// applyRegex matches the @apply directive of a rule written by writeRule
+var applyRegex = regexp.MustCompile(`@apply ([^;]*);`)
+
+// ReadTailwindSection returns the rules of the twerge section of the CSS file
+// at cssPath, mapping class names to the classes they apply.
+//
+// An error is returned if the file does not contain the twerge markers.
+func ReadTailwindSection(cssPath string) (map[string]string, error) {
+	content, err := os.ReadFile(cssPath)
 	if err != nil {
-		return fmt.Errorf("error writing output file: %w", err)
+		return nil, fmt.Errorf("error reading css file: %w", err)
+	}
+	section, ok := betweenMarkers(content)
+	if !ok {
+		return nil, fmt.Errorf("%s does not contain the markers %s and %s", cssPath, twergeBeginMarker, twergeEndMarker)
 	}
 
-	return nil
-}
-
-func sortMap(m map[string]string) map[string]string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+	rules := make(map[string]string)
+	for _, chunk := range splitRules(section) {
+		name, ok := ruleName(chunk)
+		if !ok {
+			continue
+		}
+		if m := applyRegex.FindStringSubmatch(chunk); m != nil {
+			rules[name] = m[1]
+		}
 	}
-	sort.Strings(keys)
+	return rules, nil
+}
 
-	sorted := make(map[string]string, len(m))
-	for _, k := range keys {
-		sorted[k] = m[k]
+// ruleName returns the class name of a rule chunk written by writeRule
+func ruleName(chunk string) (string, bool) {
+	line, _, _ := strings.Cut(chunk, "\n")
+	m := ruleHeaderRegex.FindStringSubmatch(line + "\n")
+	if m == nil {
+		return "", false
 	}
-	return sorted
+	return m[1], true
 }
 
 // GenerateTempl creates a .templ file that can be used to generate a CSS file
@@ -108,6 +229,18 @@ func sortMap(m map[string]string) map[string]string {
 func GenerateTempl(
 	templPath string,
 ) error {
+	content := templShim(templPackageName(templPath), takeSnapshot())
+
+	err := os.WriteFile(templPath, content, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing .templ file: %w", err)
+	}
+
+	return nil
+}
+
+// templPackageName derives the package of a .templ file from its directory
+func templPackageName(templPath string) string {
 	var pkgName string
 	pkgEnd := strings.LastIndex(templPath, "/")
 	if pkgEnd == -1 {
@@ -120,50 +253,110 @@ func GenerateTempl(
 			pkgName = templPath[pkgStart+1 : pkgEnd]
 		}
 	}
+	return pkgName
+}
 
-	var buf bytes.Buffer
-	buf.WriteString("// Code generated by twerge. DO NOT EDIT.\n\n")
-	buf.WriteString("package ")
-	buf.WriteString(pkgName)
-	buf.WriteString("\n\n")
-	buf.WriteString("templ empty() {\n")
-	buf.WriteString("<div class=\"")
-	buf.WriteString("mb-4")
-	buf.WriteString("\"></div>\n")
-	for k := range sortMap(GenClassMergeStr) {
-		// Create a CSS rule using the generated class name and the merged Tailwind classes
-		buf.WriteString("<div class=\"")
-		buf.WriteString(k)
-		buf.WriteString("\"></div>\n")
-	}
-	for _, v := range sortMap(ClassMapStr) {
-		buf.WriteString("<div class=\"")
-		buf.WriteString(v)
-		buf.WriteString("\"></div>\n")
-	}
-	buf.WriteString("}")
-
-	err := os.WriteFile(templPath, buf.Bytes(), 0644)
+// EnsureMarkers makes sure the CSS file at cssPath contains the twerge markers.
+//
+// A missing file is created with the Tailwind directives and empty markers.
+// An existing file without markers gets them appended. Content between
+// existing markers is left untouched.
+func EnsureMarkers(cssPath string) error {
+	content, err := os.ReadFile(cssPath)
+	if os.IsNotExist(err) {
+		err = os.MkdirAll(filepath.Dir(cssPath), 0755)
+		if err != nil {
+			return fmt.Errorf("error creating css directory: %w", err)
+		}
+		err = os.WriteFile(cssPath, []byte(tailwindInputCSS()), 0644)
+		if err != nil {
+			return fmt.Errorf("error writing css file: %w", err)
+		}
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("error writing .templ file: %w", err)
+		return fmt.Errorf("error reading css file: %w", err)
+	}
+	if bytes.Contains(content, []byte(twergeBeginMarker)) {
+		return nil
 	}
 
+	newContent, err := replaceBetweenMarkers(content, nil)
+	if err != nil {
+		return fmt.Errorf("error adding twerge markers: %w", err)
+	}
+	err = os.WriteFile(cssPath, newContent, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing css file: %w", err)
+	}
 	return nil
 }
 
+// sectionMarkers returns the begin and end markers of the named twerge
+// section. The unnamed section uses twergeBeginMarker and twergeEndMarker.
+func sectionMarkers(name string) (begin, end string) {
+	if name == "" {
+		return twergeBeginMarker, twergeEndMarker
+	}
+	return "/* twerge:begin " + name + " */", "/* twerge:end " + name + " */"
+}
+
+// betweenMarkers returns the content between the twerge markers, without the
+// trailing newline added by replaceBetweenMarkers.
+func betweenMarkers(content []byte) ([]byte, bool) {
+	return betweenSectionMarkers(content, "")
+}
+
+// betweenSectionMarkers is like betweenMarkers for the named section.
+func betweenSectionMarkers(content []byte, name string) ([]byte, bool) {
+	beginMarker, endMarker := sectionMarkers(name)
+	_, section, found := bytes.Cut(content, []byte(beginMarker))
+	if !found {
+		return nil, false
+	}
+	if i := bytes.IndexByte(section, '\n'); i != -1 {
+		section = section[i+1:]
+	} else {
+		section = nil
+	}
+	section, _, found = bytes.Cut(section, []byte(endMarker))
+	if !found {
+		return nil, false
+	}
+	return bytes.TrimSuffix(section, []byte("\n")), true
+}
+
 // replaceBetweenMarkers replaces content between twerge markers
 func replaceBetweenMarkers(content, replacement []byte) ([]byte, error) {
+	return replaceSection(content, "", replacement)
+}
+
+// replaceSection replaces content between the markers of the named section
+func replaceSection(content []byte, name string, replacement []byte) ([]byte, error) {
+	before, after, err := sectionBounds(content, name)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, 0, len(before)+len(replacement)+len(after))
+	result = append(result, before...)
+	result = append(result, replacement...)
+	result = append(result, after...)
+	return result, nil
+}
+
+// sectionBounds returns the content before and after the named section, so
+// that before, the section and after make up the new content. Missing
+// markers are appended to the content.
+func sectionBounds(content []byte, name string) (before, after []byte, err error) {
+	beginMarker, endMarker := sectionMarkers(name)
+
 	// Find begin marker
-	beginMarkerBytes := []byte(twergeBeginMarker)
+	beginMarkerBytes := []byte(beginMarker)
 	beginIdx := bytes.Index(content, beginMarkerBytes)
 	if beginIdx == -1 {
 		// Markers don't exist, append content with markers
-		suffix := append([]byte("\n\n"), beginMarkerBytes...)
-		suffix = append(suffix, '\n')
-		suffix = append(suffix, replacement...)
-		suffix = append(suffix, '\n')
-		suffix = append(suffix, []byte(twergeEndMarker)...)
-		return append(content, suffix...), nil
+		before = append(content[:len(content):len(content)], "\n\n"+beginMarker+"\n"...)
+		return before, []byte("\n" + endMarker), nil
 	}
 
 	// Find the end of the line containing the begin marker
@@ -176,21 +369,68 @@ func replaceBetweenMarkers(content, replacement []byte) ([]byte, error) {
 	}
 
 	// Find end marker
-	endMarkerBytes := []byte(twergeEndMarker)
+	endMarkerBytes := []byte(endMarker)
 	endIdx := bytes.Index(content[beginLineEnd:], endMarkerBytes)
 	if endIdx == -1 {
-		return nil, fmt.Errorf("found begin marker but no end marker")
+		return nil, nil, fmt.Errorf("found begin marker but no end marker")
 	}
 
 	// Adjust end marker index to be relative to the whole content
 	endIdx += beginLineEnd
 
-	// Create new content with replacement
-	result := make([]byte, 0, len(content)-(endIdx-beginLineEnd)+len(replacement)+1)
-	result = append(result, content[:beginLineEnd]...)
-	result = append(result, replacement...)
-	result = append(result, '\n')
-	result = append(result, content[endIdx:]...)
+	after = append([]byte("\n"), content[endIdx:]...)
+	return content[:beginLineEnd], after, nil
+}
 
-	return result, nil
+// layoutPiece is a part of a CSS file, either content kept as is or a twerge
+// section rendered while the file is written
+type layoutPiece struct {
+	content []byte
+	section string
+	// isSection is true for the section named section
+	isSection bool
+}
+
+// sectionLayout splits content around the named sections, so that their
+// rules can be streamed into the file instead of being built in memory.
+// Missing sections are appended in the order of names.
+func sectionLayout(content []byte, names []string) ([]layoutPiece, error) {
+	pieces := []layoutPiece{{content: content}}
+	for _, name := range names {
+		beginMarker, _ := sectionMarkers(name)
+		// sections are appended to the content after the last section
+		at := len(pieces) - 1
+		for i, piece := range pieces {
+			if !piece.isSection && bytes.Contains(piece.content, []byte(beginMarker)) {
+				at = i
+				break
+			}
+		}
+		before, after, err := sectionBounds(pieces[at].content, name)
+		if err != nil {
+			return nil, err
+		}
+		pieces = slices.Replace(pieces, at, at+1,
+			layoutPiece{content: before},
+			layoutPiece{section: name, isSection: true},
+			layoutPiece{content: after},
+		)
+	}
+	return pieces, nil
+}
+
+// writeLayout writes the pieces to w, rendering the sections with render.
+func writeLayout(w io.Writer, pieces []layoutPiece, render func(w io.Writer, section string) error) error {
+	for _, piece := range pieces {
+		var err error
+		if piece.isSection {
+			err = render(w, piece.section)
+		} else {
+			_, err = w.Write(piece.content)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
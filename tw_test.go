@@ -62,10 +62,11 @@ func TestGenerateInputCSSForTailwind(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Create a test class map
-	GenClassMergeStr = map[string]string{
-		"tw-test1": "text-red-500",
-		"tw-test2": "bg-blue-500",
-	}
+	SetMapping(map[string]string{
+		"text-red-500": "tw-test1",
+		"bg-blue-500":  "tw-test2",
+	})
+	t.Cleanup(func() { SetMapping(nil) })
 
 	// Generate input CSS
 	err = GenerateTailwind(inputFile.Name())
@@ -87,3 +88,103 @@ func TestGenerateInputCSSForTailwind(t *testing.T) {
 	err = GenerateTempl(templFile.Name())
 	assert.NoError(t, err)
 }
+
+func TestEnsureMarkers(t *testing.T) {
+	dir := t.TempDir()
+
+	// Missing files are created with directives and markers
+	created := dir + "/static/input.css"
+	assert.NoError(t, EnsureMarkers(created))
+	content, err := os.ReadFile(created)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "@tailwind base;")
+	assert.Contains(t, string(content), twergeBeginMarker)
+	assert.Contains(t, string(content), twergeEndMarker)
+
+	// Existing markers and their content are kept
+	existing := dir + "/existing.css"
+	original := "body {}\n" + twergeBeginMarker + "\n.tw-1 {}\n" + twergeEndMarker + "\n"
+	assert.NoError(t, os.WriteFile(existing, []byte(original), 0644))
+	assert.NoError(t, EnsureMarkers(existing))
+	content, err = os.ReadFile(existing)
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	// Files without markers get them appended
+	plain := dir + "/plain.css"
+	assert.NoError(t, os.WriteFile(plain, []byte("body {}\n"), 0644))
+	assert.NoError(t, EnsureMarkers(plain))
+	content, err = os.ReadFile(plain)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "body {}\n")
+	assert.Contains(t, string(content), twergeBeginMarker)
+}
+
+func TestPatchTailwind(t *testing.T) {
+	SetMapping(map[string]string{
+		"p-4":  "tw-b",
+		"m-2":  "tw-a",
+		"flex": "tw-c",
+	})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	cssPath := t.TempDir() + "/input.css"
+	original := "body {}\n" + twergeBeginMarker + "\n/* keep */\n" +
+		".tw-b { \n\t@apply p-4; \n}\n" +
+		".tw-old { \n\t@apply m-8; \n}\n" +
+		".tw-c { \n\t@apply block; \n}\n" +
+		"\n" + twergeEndMarker + "\nfooter {}\n"
+	assert.NoError(t, os.WriteFile(cssPath, []byte(original), 0644))
+
+	assert.NoError(t, PatchTailwind(cssPath))
+	content, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	want := "body {}\n" + twergeBeginMarker + "\n/* keep */\n" +
+		".tw-b { \n\t@apply p-4; \n}\n" +
+		".tw-c { \n\t@apply flex; \n}\n" +
+		".tw-a { \n\t@apply m-2; \n}\n" +
+		"\n" + twergeEndMarker + "\nfooter {}\n"
+	assert.Equal(t, want, string(content))
+
+	// patching again is a no-op
+	assert.NoError(t, PatchTailwind(cssPath))
+	content, err = os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.Equal(t, want, string(content))
+}
+
+func TestPatchTailwindKeepsUnchangedRules(t *testing.T) {
+	SetMapping(map[string]string{"flex p-2 items-center p-4 gap-2": "tw-row"})
+	t.Cleanup(func() { SetMapping(nil) })
+
+	// merged classes keep their order, so a rule written by an earlier run
+	// is left as is
+	cssPath := t.TempDir() + "/input.css"
+	original := twergeBeginMarker + "\n" +
+		".tw-row { \n\t@apply flex items-center p-4 gap-2; \n}\n" +
+		twergeEndMarker + "\n"
+	assert.NoError(t, os.WriteFile(cssPath, []byte(original), 0644))
+	for range 10 {
+		assert.NoError(t, PatchTailwind(cssPath))
+		content, err := os.ReadFile(cssPath)
+		assert.NoError(t, err)
+		assert.Equal(t, original, string(content))
+	}
+}
+
+func TestReadTailwindSection(t *testing.T) {
+	cssPath := t.TempDir() + "/input.css"
+	content := "body {}\n" + twergeBeginMarker + "\n" +
+		".tw-a { \n\t@apply p-4; \n}\n.tw-a > svg { color: red; }\n" +
+		".tw-b { \n\t@apply flex items-center; \n}\n" +
+		twergeEndMarker + "\n"
+	assert.NoError(t, os.WriteFile(cssPath, []byte(content), 0644))
+
+	rules, err := ReadTailwindSection(cssPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"tw-a": "p-4", "tw-b": "flex items-center"}, rules)
+
+	assert.NoError(t, os.WriteFile(cssPath, []byte("body {}\n"), 0644))
+	_, err = ReadTailwindSection(cssPath)
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package twerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TypeScriptTargets returns targets writing the generated class names into
+// dir as name.json, as the name.js module, and as the name.d.ts declaration
+// of the module, so client-side TypeScript can reference the same class names
+// with type checking:
+//
+//	import classes from "./classes.js";
+//
+//	button.className = classes["tw-header"];
+//	card.className = classes.Card.Root;
+//
+// Class names are keyed by themselves, and the parts of components registered
+// with RegisterComponent are grouped by component, keyed like the fields of
+// the structs of GenerateComponentStylesCode.
+func TypeScriptTargets(dir, name string) []Target {
+	return []Target{
+		{
+			Path: filepath.Join(dir, name+".json"),
+			Render: func(snap Snapshot) ([]byte, error) {
+				content, err := typeScriptClasses(snap)
+				if err != nil {
+					return nil, err
+				}
+				return append(content, '\n'), nil
+			},
+		},
+		{
+			Path: filepath.Join(dir, name+".js"),
+			Render: func(snap Snapshot) ([]byte, error) {
+				content, err := typeScriptClasses(snap)
+				if err != nil {
+					return nil, err
+				}
+				var buf bytes.Buffer
+				buf.WriteString("// Code generated by twerge. DO NOT EDIT.\n\n")
+				buf.WriteString("export default ")
+				buf.Write(content)
+				buf.WriteString(";\n")
+				return buf.Bytes(), nil
+			},
+		},
+		{
+			Path: filepath.Join(dir, name+".d.ts"),
+			Render: func(snap Snapshot) ([]byte, error) {
+				classes, components, err := classKeys(snap)
+				if err != nil {
+					return nil, err
+				}
+				var buf bytes.Buffer
+				buf.WriteString("// Code generated by twerge. DO NOT EDIT.\n\n")
+				buf.WriteString("declare const classes: {\n")
+				for _, key := range SortedKeys(classes) {
+					buf.WriteString("  readonly " + propertyName(key) + ": " + strconv.Quote(classes[key]) + ";\n")
+				}
+				for _, component := range sortedComponentKeys(components) {
+					parts := components[component]
+					buf.WriteString("  readonly " + propertyName(component) + ": {\n")
+					for _, part := range SortedKeys(parts) {
+						buf.WriteString("    readonly " + propertyName(part) + ": " + strconv.Quote(parts[part]) + ";\n")
+					}
+					buf.WriteString("  };\n")
+				}
+				buf.WriteString("};\n\n")
+				buf.WriteString("export default classes;\n")
+				return buf.Bytes(), nil
+			},
+		},
+	}
+}
+
+// typeScriptClasses encodes the class names of snap keyed like in the
+// declaration of TypeScriptTargets as JSON.
+func typeScriptClasses(snap Snapshot) ([]byte, error) {
+	classes, components, err := classKeys(snap)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]any, len(classes)+len(components))
+	for key, className := range classes {
+		keys[key] = className
+	}
+	for component, parts := range components {
+		keys[component] = parts
+	}
+	content, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding class names: %w", err)
+	}
+	return content, nil
+}
+
+// classKeys returns the generated class names of snap that are not parts of
+// components keyed by themselves, and the class names of the parts of every
+// component keyed by the exported identifiers of the component and part.
+func classKeys(snap Snapshot) (classes map[string]string, components map[string]map[string]string, err error) {
+	components = make(map[string]map[string]string, len(snap.Components))
+	inComponent := make(map[string]bool)
+	for component, parts := range snap.Components {
+		ids := make(map[string]string, len(parts))
+		for part, className := range parts {
+			ids[exportedIdentifier(part)] = className
+			inComponent[className] = true
+		}
+		components[exportedIdentifier(component)] = ids
+	}
+
+	classes = make(map[string]string, len(snap.Rules))
+	for className := range snap.Rules {
+		if inComponent[className] {
+			continue
+		}
+		if _, ok := components[className]; ok {
+			return nil, nil, fmt.Errorf("class name %q collides with a component of the same name", className)
+		}
+		classes[className] = className
+	}
+	return classes, components, nil
+}
+
+// propertyName returns key as a TypeScript property name, quoted unless it is
+// an identifier.
+func propertyName(key string) string {
+	if classIdentifier(key) == key {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// classIdentifier converts a class name into a camelCase identifier, e.g.
+// "tw-header" into "twHeader".
+func classIdentifier(className string) string {
+	var builder strings.Builder
+	upper := false
+	for _, r := range className {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' {
+			upper = builder.Len() > 0
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		builder.WriteRune(r)
+	}
+	id := builder.String()
+	if id == "" || unicode.IsDigit(rune(id[0])) {
+		id = "_" + id
+	}
+	return id
+}
@@ -0,0 +1,54 @@
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeScriptTargets(t *testing.T) {
+	resetComponents(t)
+	SetMapping(map[string]string{
+		"flex":  "tw-header",
+		"block": "tw_header",
+		"m-2":   "9lives",
+	})
+	_, err := RegisterComponent("Card", map[string]string{"Root": "rounded-lg p-4"})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	err = GenerateTailwind(filepath.Join(dir, "input.css"), TypeScriptTargets(dir, "classes")...)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "classes.json"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"9lives": "9lives", "tw-header": "tw-header", "tw_header": "tw_header", "Card": {"Root": "tw-card-root"}}`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "classes.js"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "export default {\n")
+	assert.Contains(t, string(content), `"tw-header": "tw-header",`)
+
+	content, err = os.ReadFile(filepath.Join(dir, "classes.d.ts"))
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by twerge. DO NOT EDIT.\n\n"+
+		"declare const classes: {\n"+
+		"  readonly \"9lives\": \"9lives\";\n"+
+		"  readonly \"tw-header\": \"tw-header\";\n"+
+		"  readonly tw_header: \"tw_header\";\n"+
+		"  readonly Card: {\n"+
+		"    readonly Root: \"tw-card-root\";\n"+
+		"  };\n"+
+		"};\n\n"+
+		"export default classes;\n", string(content))
+}
+
+func TestTypeScriptTargetsCollision(t *testing.T) {
+	_, _, err := classKeys(Snapshot{
+		Rules:      map[string]string{"Card": "p-4", "tw-card-root": "p-2"},
+		Components: map[string]map[string]string{"Card": {"Root": "tw-card-root"}},
+	})
+	assert.Error(t, err)
+}
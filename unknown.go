@@ -0,0 +1,111 @@
+package twerge
+
+import (
+	"io"
+	"strings"
+)
+
+// UnknownClasses selects how the @apply rules of a class map treat classes
+// that are not Tailwind utilities, like "card" or "js-toggle", see
+// WithUnknownClasses.
+type UnknownClasses int
+
+const (
+	// UnknownApply keeps unknown classes in @apply, which Tailwind accepts
+	// for classes defined in the input CSS only
+	UnknownApply UnknownClasses = iota
+	// UnknownComment moves unknown classes out of @apply into a comment
+	// listing them, like /* composes: card js-toggle */
+	UnknownComment
+	// UnknownOmit removes unknown classes from the rules, for elements
+	// carrying them next to their class name, see ItWithUnknown
+	UnknownOmit
+)
+
+// WithUnknownClasses sets how the @apply rules treat classes that are not
+// Tailwind utilities, UnknownApply by default.
+//
+// Tailwind fails the build on an @apply of a class it does not know, so class
+// strings mixing utilities with custom classes, like "card p-4", need their
+// custom classes out of the rule:
+//
+//	twerge.WriteGeneratedCSS(w, twerge.WithUnknownClasses(twerge.UnknownOmit))
+//
+//	<div class={ twerge.ItWithUnknown("card p-4") }>...</div> // class="tw-0 card"
+//
+// Plugin groups of Config.PluginGroups are known. Standalone rules never
+// hold unknown classes, as they have no CSS to resolve to.
+func WithUnknownClasses(mode UnknownClasses) MapOption {
+	return func(o *mapOptions) {
+		o.unknown = mode
+	}
+}
+
+// unknownCache caches the results of ItWithUnknown by class string
+var unknownCache = newCache(1000, nil)
+
+// ItWithUnknown returns the class name of classes, like It, followed by the
+// classes of their merge that are not Tailwind utilities, like "tw-0 card
+// js-toggle" for "card p-2 p-4 js-toggle". It is meant for rules written with
+// UnknownOmit, which leave the unknown classes to the element.
+func ItWithUnknown(classes string) string {
+	if cached := unknownCache.Get(classes); cached != "" {
+		return cached
+	}
+	className := It(classes)
+	mapMutex.RLock()
+	merged, ok := GenClassMergeStr[className]
+	mapMutex.RUnlock()
+	if !ok {
+		merged = Merge(classes)
+	}
+
+	result := className
+	if _, unknown := splitUnknown(merged); unknown != "" {
+		result += " " + unknown
+	}
+	unknownCache.Set(classes, result)
+	return result
+}
+
+// splitUnknown splits classes into the Tailwind utilities and plugin classes
+// and the unknown classes, keeping their order.
+func splitUnknown(classes string) (known, unknown string) {
+	conf, trie := currentTrie()
+	getClassGroupID := makeGetClassGroupIDFromTrie(conf, trie)
+	splitModifiers := makeSplitModifiers(conf)
+	var knownClasses, unknownClasses []string
+	for _, class := range strings.Fields(classes) {
+		baseClass, _, _, postFixMod := splitModifiers(class)
+		if _, ok := resolveClassGroup(conf, getClassGroupID, baseClass, postFixMod); ok {
+			knownClasses = append(knownClasses, class)
+		} else {
+			unknownClasses = append(unknownClasses, class)
+		}
+	}
+	return strings.Join(knownClasses, " "), strings.Join(unknownClasses, " ")
+}
+
+// writeUnknownRule writes the @apply rule of the class name like
+// writeRawRule, with the unknown classes handled as selected by mode.
+func writeUnknownRule(w io.Writer, className, classes, raw string, mode UnknownClasses) error {
+	known, unknown := splitUnknown(classes)
+	if unknown == "" {
+		return writeRawRule(w, className, classes, raw)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("." + className + " { \n")
+	if known != "" {
+		builder.WriteString("\t@apply " + known + "; \n")
+	}
+	if mode == UnknownComment {
+		builder.WriteString("\t/* composes: " + unknown + " */ \n")
+	}
+	builder.WriteString("}\n")
+	if raw != "" {
+		builder.WriteString(raw + "\n")
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
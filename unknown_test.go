@@ -0,0 +1,52 @@
+package twerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithUnknownClasses(t *testing.T) {
+	classMap := map[string]string{"card p-2 p-4 hover:js-toggle": "tw-a", "js-only": "tw-b", "flex": "tw-c"}
+
+	var builder strings.Builder
+	err := WriteCSS(&builder, classMap, WithUnknownClasses(UnknownComment))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		".tw-a { \n\t@apply p-4; \n\t/* composes: card hover:js-toggle */ \n}\n"+
+			".tw-b { \n\t/* composes: js-only */ \n}\n"+
+			".tw-c { \n\t@apply flex; \n}\n",
+		builder.String(),
+	)
+
+	builder.Reset()
+	err = WriteCSS(&builder, classMap, WithUnknownClasses(UnknownOmit))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		".tw-a { \n\t@apply p-4; \n}\n"+
+			".tw-b { \n}\n"+
+			".tw-c { \n\t@apply flex; \n}\n",
+		builder.String(),
+	)
+
+	builder.Reset()
+	err = WriteCSS(&builder, classMap, WithUnknownClasses(UnknownComment), WithMinify())
+	assert.NoError(t, err)
+	assert.Equal(t, ".tw-a{@apply p-4}.tw-c{@apply flex}", builder.String())
+
+	// unknown classes are kept by default
+	builder.Reset()
+	err = WriteCSS(&builder, map[string]string{"card p-4": "tw-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, ".tw-a { \n\t@apply card p-4; \n}\n", builder.String())
+}
+
+func TestItWithUnknown(t *testing.T) {
+	RegisterClasses(map[string]string{"card p-2 p-4 js-toggle": "tw-card"})
+	assert.Equal(t, "tw-card card js-toggle", ItWithUnknown("card p-2 p-4 js-toggle"))
+	assert.Equal(t, "tw-card card js-toggle", ItWithUnknown("card p-2 p-4 js-toggle"))
+
+	RegisterClasses(map[string]string{"m-2 m-4": "tw-margin"})
+	assert.Equal(t, "tw-margin", ItWithUnknown("m-2 m-4"))
+}
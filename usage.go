@@ -0,0 +1,92 @@
+package twerge
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Usage is how often a class string was passed to RuntimeGenerate, see
+// UsageReport.
+type Usage struct {
+	// Classes is the class string, in its Canonical form if
+	// Config.CanonicalKeys is set
+	Classes string `json:"classes"`
+	// ClassName is the class name of Classes, empty if it has none
+	ClassName string `json:"class_name"`
+	// Count is the number of calls with Classes
+	Count uint64 `json:"count"`
+	// SavedBytes is the number of bytes saved by rendering ClassName
+	// instead of Classes Count times
+	SavedBytes int64 `json:"saved_bytes"`
+}
+
+// usageCounts maps the class strings of RuntimeGenerate to the number of
+// calls with them
+var usageCounts sync.Map // map[string]*atomic.Uint64
+
+// countUsage counts a call of RuntimeGenerate with classes, except in
+// production mode, where RuntimeGenerate is a lookup only.
+func countUsage(classes string) {
+	if ProductionMode {
+		return
+	}
+	counter, ok := usageCounts.Load(classes)
+	if !ok {
+		counter, _ = usageCounts.LoadOrStore(classes, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+// UsageReport returns how often each class string was passed to
+// RuntimeGenerate and RuntimeGenerateContext since the program started or
+// ResetUsage was called, the most used first. It is empty in production mode,
+// see ProductionMode.
+//
+// Class strings used often with many classes save the most bytes, and are
+// worth extracting into a component:
+//
+//	for _, u := range twerge.UsageReport()[:10] {
+//		log.Printf("%6d %6dB %s %q", u.Count, u.SavedBytes, u.ClassName, u.Classes)
+//	}
+func UsageReport() []Usage {
+	var report []Usage
+	usageCounts.Range(func(key, value any) bool {
+		report = append(report, Usage{
+			Classes: key.(string),
+			Count:   value.(*atomic.Uint64).Load(),
+		})
+		return true
+	})
+
+	mapMutex.RLock()
+	for i := range report {
+		u := &report[i]
+		u.ClassName = ClassMapStr[u.Classes]
+		if u.ClassName != "" {
+			u.SavedBytes = int64(u.Count) * int64(len(u.Classes)-len(u.ClassName))
+		}
+	}
+	mapMutex.RUnlock()
+
+	SortUsage(report)
+	return report
+}
+
+// SortUsage sorts usage by Count, then by SavedBytes, the largest first, and
+// then by Classes.
+func SortUsage(usage []Usage) {
+	slices.SortFunc(usage, func(a, b Usage) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(b.SavedBytes, a.SavedBytes),
+			cmp.Compare(a.Classes, b.Classes),
+		)
+	})
+}
+
+// ResetUsage clears the counts of UsageReport.
+func ResetUsage() {
+	usageCounts.Clear()
+}
@@ -0,0 +1,30 @@
+//go:build !twerge_prod
+
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageReport(t *testing.T) {
+	SetMapping(map[string]string{"flex items-center justify-between": "tw-1"})
+	t.Cleanup(func() { SetMapping(nil) })
+	ResetUsage()
+	t.Cleanup(ResetUsage)
+
+	for range 3 {
+		RuntimeGenerate("flex items-center justify-between")
+	}
+	className := RuntimeGenerate("p-4")
+
+	report := UsageReport()
+	assert.Equal(t, []Usage{
+		{Classes: "flex items-center justify-between", ClassName: "tw-1", Count: 3, SavedBytes: 87},
+		{Classes: "p-4", ClassName: className, Count: 1, SavedBytes: int64(3 - len(className))},
+	}, report)
+
+	ResetUsage()
+	assert.Empty(t, UsageReport())
+}
@@ -0,0 +1,65 @@
+package twerge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Warning reports a class of a class string that is likely a typo.
+type Warning struct {
+	// Class is the class as written, with its variants
+	Class string
+	// Message describes the problem, like `unknown color "red-5000"`
+	Message string
+}
+
+// String returns the class followed by the message.
+func (w Warning) String() string {
+	return w.Class + ": " + w.Message
+}
+
+// Validate returns a Warning for every class of classes that is not a known
+// Tailwind utility, which Merge passes through unchanged, and for every color
+// class whose color is not in the default palette, like text-red-5000.
+//
+// Custom classes and the custom colors of a Tailwind theme are reported as
+// well, so Validate is meant for build time checks, see GenOptions.OnWarning.
+func Validate(classes string) []Warning {
+	conf, trie := currentTrie()
+	getClassGroupID := makeGetClassGroupIDFromTrie(conf, trie)
+	splitModifiers := makeSplitModifiers(conf)
+	var warnings []Warning
+	for _, class := range strings.Fields(classes) {
+		baseClass, _, _, postFixMod := splitModifiers(class)
+		if _, ok := resolveClassGroup(conf, getClassGroupID, baseClass, postFixMod); !ok {
+			warnings = append(warnings, Warning{Class: class, Message: fmt.Sprintf("unknown utility %q", baseClass)})
+			continue
+		}
+		if postFixMod != -1 {
+			baseClass = baseClass[:postFixMod]
+		}
+		baseClass, _ = stripPrefix(conf, baseClass)
+		baseClass = strings.TrimPrefix(baseClass, string(conf.ClassSeparator))
+		groupID, v, value, ok := trie.match(0, baseClass, 0)
+		// colors are matched by any value, and so are their typos
+		if ok && v != nil && groupID != "font-family" && validatorName(v.Fn) == "isAny" && !isPaletteColor(value) {
+			warnings = append(warnings, Warning{Class: class, Message: fmt.Sprintf("unknown color %q", value)})
+		}
+	}
+	return warnings
+}
+
+// isPaletteColor reports whether value is a color of the default palette,
+// like red-500, a color without shades or an arbitrary value.
+func isPaletteColor(value string) bool {
+	if _, ok := specialColors[value]; ok || isArbitraryValue(value) {
+		return true
+	}
+	i := strings.LastIndexByte(value, '-')
+	if i < 0 {
+		return false
+	}
+	_, ok := tailwindColors[value[:i]]
+	return ok && slices.Contains(colorShades, value[i+1:])
+}
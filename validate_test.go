@@ -0,0 +1,19 @@
+package twerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Equal(t, []Warning{
+		{Class: "text-red-5000", Message: `unknown color "red-5000"`},
+		{Class: "hover:bg-rde-500/50", Message: `unknown color "rde-500"`},
+		{Class: "flexx", Message: `unknown utility "flexx"`},
+	}, Validate("text-red-5000 p-4 hover:bg-rde-500/50 flexx"))
+
+	assert.Empty(t, Validate("text-red-500 bg-blue-500/50 text-[#fff] border-white fill-current font-sans "+
+		"-mt-4 md:hover:underline [mask-type:luminance] bg-(--brand)"))
+	assert.Equal(t, `flexx: unknown utility "flexx"`, Validate("flexx")[0].String())
+}
@@ -0,0 +1,157 @@
+package twerge
+
+import "strings"
+
+// DarkMode selects how WithStandaloneCSS writes the dark: variant.
+type DarkMode int
+
+const (
+	// DarkModeMedia applies dark: classes when the system prefers a dark
+	// color scheme, with the prefers-color-scheme media query.
+	DarkModeMedia DarkMode = iota
+	// DarkModeClass applies dark: classes to elements within, or being, an
+	// element matching Config.DarkSelector, like Tailwind's class strategy.
+	DarkModeClass
+)
+
+// DefaultDarkSelector is the selector of DarkModeClass if
+// Config.DarkSelector is empty
+const DefaultDarkSelector = ".dark"
+
+// ariaStates are the aria attributes with true/false values that have a
+// variant, like aria-checked:
+var ariaStates = map[string]bool{
+	"busy": true, "checked": true, "disabled": true, "expanded": true,
+	"hidden": true, "pressed": true, "readonly": true, "required": true,
+	"selected": true,
+}
+
+// applyVariant returns selector with the selector template of a custom
+// variant applied, "&" standing for selector.
+func applyVariant(template, selector string) string {
+	return strings.ReplaceAll(template, "&", selector)
+}
+
+// groupVariant returns the kind, group or peer, the state and the name of a
+// group-* or peer-* variant:
+//
+//	group-hover        -> group, hover
+//	group-hover/edit   -> group, hover, edit
+//	peer-[.open]/field -> peer, [.open], field
+//
+// The name is split off at the last slash outside of brackets, so named and
+// unnamed variants of the same state mark different elements.
+func groupVariant(modifier string) (kind, state, name string, ok bool) {
+	for _, kind := range []string{"group", "peer"} {
+		state, ok := strings.CutPrefix(modifier, kind+"-")
+		if !ok || state == "" {
+			continue
+		}
+		if i := strings.LastIndexByte(state, '/'); i > strings.LastIndexByte(state, ']') {
+			if i == 0 || i == len(state)-1 {
+				return "", "", "", false
+			}
+			return kind, state[:i], state[i+1:], true
+		}
+		return kind, state, "", true
+	}
+	return "", "", "", false
+}
+
+// groupMarker returns the selector of the element marked with the group or
+// peer class of a variant, like .group\/edit for group-hover/edit.
+func groupMarker(kind, name string) string {
+	if name == "" {
+		return "." + kind
+	}
+	return "." + kind + `\/` + name
+}
+
+// groupSelector returns the selector of the marker of a group-* or peer-*
+// variant in state, the arbitrary states like [.open] appended to it, or
+// with "&" standing for the marker.
+func groupSelector(marker, state string) (string, bool) {
+	if arbitrary, ok := strings.CutPrefix(state, "["); ok {
+		arbitrary, ok = strings.CutSuffix(arbitrary, "]")
+		if !ok || arbitrary == "" {
+			return "", false
+		}
+		arbitrary = strings.ReplaceAll(arbitrary, "_", " ")
+		if strings.Contains(arbitrary, "&") {
+			return applyVariant(arbitrary, marker), true
+		}
+		return marker + arbitrary, true
+	}
+	selector, ok := stateSelector(state)
+	if !ok {
+		return "", false
+	}
+	return marker + selector, true
+}
+
+// variantKey returns the modifier in the form modifiers are compared in when
+// merging. The aria-* and data-* variants, also of groups and peers, are
+// written with their attribute in brackets and without quotes, as they
+// select the same elements:
+//
+//	data-open                -> data-[open]
+//	aria-checked             -> aria-[checked=true]
+//	data-[state="open"]      -> data-[state=open]
+//	group-aria-expanded/menu -> group-aria-[expanded=true]/menu
+//
+// Other modifiers are returned as they are.
+func variantKey(modifier string) string {
+	if kind, state, name, ok := groupVariant(modifier); ok {
+		key := kind + "-" + attributeKey(state)
+		if name != "" {
+			key += "/" + name
+		}
+		return key
+	}
+	return attributeKey(modifier)
+}
+
+// attributeKey returns the aria-* or data-* variant modifier in the form of
+// variantKey, other modifiers as they are.
+func attributeKey(modifier string) string {
+	selector, ok := attributeVariant(modifier)
+	if !ok {
+		return modifier
+	}
+	attribute, value, _ := strings.Cut(selector[1:len(selector)-1], "-")
+	if name, v, ok := strings.Cut(value, "="); ok {
+		value = name + "=" + strings.Trim(v, `"'`)
+	}
+	return attribute + "-[" + value + "]"
+}
+
+// attributeVariant returns the attribute selector of an aria-* or data-*
+// variant:
+//
+//	aria-checked         -> [aria-checked="true"]
+//	aria-[sort=ascending] -> [aria-sort=ascending]
+//	data-active          -> [data-active]
+//	data-[state=open]    -> [data-state=open]
+func attributeVariant(modifier string) (string, bool) {
+	for _, attribute := range []string{"aria", "data"} {
+		value, ok := strings.CutPrefix(modifier, attribute+"-")
+		if !ok || value == "" {
+			continue
+		}
+		if arbitrary, ok := strings.CutPrefix(value, "["); ok {
+			arbitrary, ok = strings.CutSuffix(arbitrary, "]")
+			if !ok || arbitrary == "" {
+				return "", false
+			}
+			return "[" + attribute + "-" + strings.ReplaceAll(arbitrary, "_", " ") + "]", true
+		}
+		if attribute == "aria" {
+			if !ariaStates[value] {
+				return "", false
+			}
+			return `[aria-` + value + `="true"]`, true
+		}
+		return "[data-" + value + "]", true
+	}
+	return "", false
+}
@@ -0,0 +1,80 @@
+package twerge
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TailwindVersion is the major version of Tailwind CSS a project uses.
+type TailwindVersion int
+
+const (
+	// TailwindUnknown is returned when no version could be detected
+	TailwindUnknown TailwindVersion = 0
+	// TailwindV3 is Tailwind CSS 3, configured with tailwind.config.js and
+	// @tailwind directives. It is the default.
+	TailwindV3 TailwindVersion = 3
+	// TailwindV4 is Tailwind CSS 4, configured in CSS and imported with
+	// @import "tailwindcss".
+	TailwindV4 TailwindVersion = 4
+)
+
+// String returns the version as "v3" or "v4".
+func (v TailwindVersion) String() string {
+	if v == TailwindUnknown {
+		return "unknown"
+	}
+	return "v" + strconv.Itoa(int(v))
+}
+
+// versionRegex matches the major version of a version or version range
+var versionRegex = regexp.MustCompile(`(?:^|[^\d.])v?(\d+)(?:\.[\dx*]+)*`)
+
+// SetTailwindVersion selects the Tailwind version Merge and the generated
+// input CSS target, overriding any detected version.
+//
+// Like SetConflictConfig, it resets the merge cache and is not safe to call
+// concurrently with Merge, so it should be called once at program start.
+func SetTailwindVersion(v TailwindVersion) {
+	if v == TailwindUnknown {
+		v = TailwindV3
+	}
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	settings.TailwindVersion = v
+	rebuildMerge()
+}
+
+// CurrentTailwindVersion returns the Tailwind version selected for Merge.
+func CurrentTailwindVersion() TailwindVersion {
+	mergeSettingsMutex.Lock()
+	defer mergeSettingsMutex.Unlock()
+	return settings.TailwindVersion
+}
+
+// parseTailwindVersion returns the Tailwind version of a version string or
+// npm version range, e.g. "4.1.3" or "^3.4.0".
+func parseTailwindVersion(s string) TailwindVersion {
+	m := versionRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return TailwindUnknown
+	}
+	switch m[1] {
+	case "3":
+		return TailwindV3
+	case "4":
+		return TailwindV4
+	default:
+		return TailwindUnknown
+	}
+}
+
+// tailwindInputCSS returns the content of a new Tailwind input CSS file for
+// the selected version.
+func tailwindInputCSS() string {
+	if CurrentTailwindVersion() == TailwindV4 {
+		return defaultTailwindV4CSS
+	}
+	return defaultTailwindCSS
+}
@@ -0,0 +1,38 @@
+package twerge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTailwindVersion(t *testing.T) {
+	for in, want := range map[string]TailwindVersion{
+		"4.1.3":                 TailwindV4,
+		"^3.4.0":                TailwindV3,
+		"~4":                    TailwindV4,
+		">=3.0.0 <4.0.0":        TailwindV3,
+		"≈ tailwindcss v4.0.14": TailwindV4,
+		"latest":                TailwindUnknown,
+		"2.2.19":                TailwindUnknown,
+		"":                      TailwindUnknown,
+	} {
+		assert.Equal(t, want, parseTailwindVersion(in), in)
+	}
+}
+
+func TestSetTailwindVersion(t *testing.T) {
+	defer SetTailwindVersion(TailwindV3)
+
+	SetTailwindVersion(TailwindV4)
+	assert.Equal(t, TailwindV4, CurrentTailwindVersion())
+	assert.Equal(t, "p-4", Merge("p-2 p-4"))
+
+	cssPath := filepath.Join(t.TempDir(), "input.css")
+	assert.NoError(t, EnsureMarkers(cssPath))
+	content, err := os.ReadFile(cssPath)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTailwindV4CSS, string(content))
+}